@@ -4,16 +4,45 @@
 package admin
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
 	"sort"
+	"time"
 
+	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// defaultDialTimeout is how long ConnectPeer waits for a peer to accept a
+// connection
+const defaultDialTimeout = 10 * time.Second
+
+var (
+	errNoPeerCert  = errors.New("peer didn't present a staking certificate")
+	errWrongNodeID = errors.New("peer has a different node ID")
 )
 
 // Peerable can return a group of peers
 type Peerable interface{ Peers() []utils.IPDesc }
 
+// PeerDialer can initiate a connection to a peer
+type PeerDialer interface {
+	Dial(ip utils.IPDesc, nodeID ids.ShortID) error
+}
+
 // Networking provides helper methods for tracking the current network state
-type Networking struct{ peers Peerable }
+type Networking struct {
+	peers       Peerable
+	dialer      PeerDialer
+	dialTimeout time.Duration
+	// stakingCert is presented to peers to learn their node IDs from their
+	// staking certificates. If nil, staking is disabled, and node IDs are
+	// derived from peers' IPs.
+	stakingCert *tls.Certificate
+}
 
 // Peers returns the current peers
 func (n *Networking) Peers() ([]string, error) {
@@ -25,3 +54,54 @@ func (n *Networking) Peers() ([]string, error) {
 	sort.Strings(ips)
 	return ips, nil
 }
+
+// ConnectPeer connects to the peer at [ip] with ID [nodeID]. Since the
+// dialer may connect asynchronously, the peer is first connected to directly,
+// so that an unreachable peer, or one whose node ID isn't [nodeID], is
+// reported to the caller.
+func (n *Networking) ConnectPeer(ip utils.IPDesc, nodeID ids.ShortID) error {
+	peerID, err := n.peerID(ip)
+	if err != nil {
+		return err
+	}
+	if !peerID.Equals(nodeID) {
+		return fmt.Errorf("%w: %s at %s", errWrongNodeID, peerID, ip)
+	}
+	return n.dialer.Dial(ip, nodeID)
+}
+
+// peerID connects to the peer at [ip] and returns its node ID
+func (n *Networking) peerID(ip utils.IPDesc) (ids.ShortID, error) {
+	dialer := &net.Dialer{Timeout: n.dialTimeout}
+	if dialer.Timeout == 0 {
+		dialer.Timeout = defaultDialTimeout
+	}
+
+	if n.stakingCert == nil {
+		conn, err := dialer.Dial("tcp", ip.String())
+		if err != nil {
+			return ids.ShortID{}, err
+		}
+		if err := conn.Close(); err != nil {
+			return ids.ShortID{}, err
+		}
+		return ids.NewShortID(hashing.ComputeHash160Array([]byte(ip.String()))), nil
+	}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", ip.String(), &tls.Config{
+		Certificates: []tls.Certificate{*n.stakingCert},
+		// Staking certificates are self-signed. The peer is identified by the
+		// hash of its certificate instead.
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return ids.ShortID{}, errNoPeerCert
+	}
+	return ids.ToShortID(hashing.PubkeyBytesToAddress(certs[0].Raw))
+}
@@ -0,0 +1,204 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+type testDialer struct {
+	ips     []utils.IPDesc
+	nodeIDs []ids.ShortID
+}
+
+func (d *testDialer) Dial(ip utils.IPDesc, nodeID ids.ShortID) error {
+	d.ips = append(d.ips, ip)
+	d.nodeIDs = append(d.nodeIDs, nodeID)
+	return nil
+}
+
+func listen(t *testing.T) (net.Listener, utils.IPDesc) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip, err := utils.ToIPDesc(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return listener, ip
+}
+
+func TestConnectPeer(t *testing.T) {
+	listener, ip := listen(t)
+	defer listener.Close()
+
+	dialer := &testDialer{}
+	service := &Admin{
+		log: logging.NoLog{},
+		networking: Networking{
+			dialer:      dialer,
+			dialTimeout: time.Second,
+		},
+	}
+
+	// Without staking, node IDs are derived from IPs
+	nodeID := ids.NewShortID(hashing.ComputeHash160Array([]byte(ip.String())))
+	reply := ConnectPeerReply{}
+	if err := service.ConnectPeer(nil, &ConnectPeerArgs{
+		IP:     ip.IP.String(),
+		Port:   cjson.Uint16(ip.Port),
+		NodeID: nodeID.String(),
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Success {
+		t.Fatalf("Should have succeeded")
+	}
+	if len(dialer.ips) != 1 || !dialer.ips[0].Equal(ip) || !dialer.nodeIDs[0].Equals(nodeID) {
+		t.Fatalf("Should have dialed %s", ip)
+	}
+}
+
+func TestConnectPeerDeadAddress(t *testing.T) {
+	// Close the listener so that nothing is listening on its port
+	listener, ip := listen(t)
+	listener.Close()
+
+	dialer := &testDialer{}
+	service := &Admin{
+		log: logging.NoLog{},
+		networking: Networking{
+			dialer:      dialer,
+			dialTimeout: time.Second,
+		},
+	}
+
+	reply := ConnectPeerReply{}
+	if err := service.ConnectPeer(nil, &ConnectPeerArgs{
+		IP:     ip.IP.String(),
+		Port:   cjson.Uint16(ip.Port),
+		NodeID: ids.NewShortID([20]byte{1}).String(),
+	}, &reply); err == nil {
+		t.Fatalf("Should have failed to connect to a dead address")
+	}
+	if reply.Success {
+		t.Fatalf("Shouldn't have succeeded")
+	}
+	if len(dialer.ips) != 0 {
+		t.Fatalf("Shouldn't have dialed an unreachable peer")
+	}
+}
+
+func TestConnectPeerInvalidArgs(t *testing.T) {
+	service := &Admin{
+		log:        logging.NoLog{},
+		networking: Networking{dialer: &testDialer{}},
+	}
+	nodeID := ids.NewShortID([20]byte{1}).String()
+
+	tests := []ConnectPeerArgs{
+		{IP: "not an ip", Port: 9651, NodeID: nodeID},
+		{IP: "127.0.0.1", Port: 0, NodeID: nodeID},
+		{IP: "127.0.0.1", Port: 9651, NodeID: "not an id"},
+	}
+	for _, args := range tests {
+		if err := service.ConnectPeer(nil, &args, &ConnectPeerReply{}); err == nil {
+			t.Fatalf("Should have rejected %+v", args)
+		}
+	}
+}
+
+func TestConnectPeerWrongNodeID(t *testing.T) {
+	listener, ip := listen(t)
+	defer listener.Close()
+
+	dialer := &testDialer{}
+	networking := Networking{
+		dialer:      dialer,
+		dialTimeout: time.Second,
+	}
+	if err := networking.ConnectPeer(ip, ids.NewShortID([20]byte{1})); !errors.Is(err, errWrongNodeID) {
+		t.Fatalf("Should have failed with %s but returned %v", errWrongNodeID, err)
+	}
+	if len(dialer.ips) != 0 {
+		t.Fatalf("Shouldn't have dialed a peer with the wrong node ID")
+	}
+}
+
+// newTestCert returns a self-signed TLS certificate
+func newTestCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{certBytes}, PrivateKey: key}
+}
+
+func TestConnectPeerStakingCert(t *testing.T) {
+	peerCert := newTestCert(t)
+	listener, ip := listen(t)
+	defer listener.Close()
+	listener = tls.NewListener(listener, &tls.Config{
+		Certificates: []tls.Certificate{peerCert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	stakingCert := newTestCert(t)
+	dialer := &testDialer{}
+	networking := Networking{
+		dialer:      dialer,
+		dialTimeout: time.Second,
+		stakingCert: &stakingCert,
+	}
+
+	if err := networking.ConnectPeer(ip, ids.NewShortID([20]byte{1})); !errors.Is(err, errWrongNodeID) {
+		t.Fatalf("Should have failed with %s but returned %v", errWrongNodeID, err)
+	}
+	peerID, err := ids.ToShortID(hashing.PubkeyBytesToAddress(peerCert.Certificate[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := networking.ConnectPeer(ip, peerID); err != nil {
+		t.Fatal(err)
+	}
+	if len(dialer.ips) != 1 || !dialer.nodeIDs[0].Equals(peerID) {
+		t.Fatalf("Should have dialed %s once", ip)
+	}
+}
@@ -4,6 +4,9 @@
 package admin
 
 import (
+	"crypto/tls"
+	"errors"
+	"net"
 	"net/http"
 
 	"github.com/gorilla/rpc/v2"
@@ -14,9 +17,16 @@ import (
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/logging"
 
+	"github.com/ava-labs/gecko/utils"
+
 	cjson "github.com/ava-labs/gecko/utils/json"
 )
 
+var (
+	errBadPeerIP   = errors.New("peer ip is invalid")
+	errBadPeerPort = errors.New("peer port must be non-zero")
+)
+
 // Admin is the API service for node admin management
 type Admin struct {
 	nodeID       ids.ShortID
@@ -29,7 +39,9 @@ type Admin struct {
 }
 
 // NewService returns a new admin API service
-func NewService(nodeID ids.ShortID, networkID uint32, log logging.Logger, chainManager chains.Manager, peers Peerable, httpServer *api.Server) *common.HTTPHandler {
+// [stakingCert] is the node's staking certificate, or nil if staking is
+// disabled
+func NewService(nodeID ids.ShortID, networkID uint32, log logging.Logger, chainManager chains.Manager, peers Peerable, dialer PeerDialer, stakingCert *tls.Certificate, httpServer *api.Server) *common.HTTPHandler {
 	newServer := rpc.NewServer()
 	codec := cjson.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
@@ -40,7 +52,9 @@ func NewService(nodeID ids.ShortID, networkID uint32, log logging.Logger, chainM
 		log:          log,
 		chainManager: chainManager,
 		networking: Networking{
-			peers: peers,
+			peers:       peers,
+			dialer:      dialer,
+			stakingCert: stakingCert,
 		},
 		httpServer: httpServer,
 	}, "admin")
@@ -98,6 +112,23 @@ func (service *Admin) GetBlockchainID(r *http.Request, args *GetBlockchainIDArgs
 	return err
 }
 
+// GetBootstrapStatusArgs are the arguments for calling GetBootstrapStatus
+type GetBootstrapStatusArgs struct{}
+
+// GetBootstrapStatusReply are the results from calling GetBootstrapStatus
+type GetBootstrapStatusReply struct {
+	chains.BootstrapStatus
+}
+
+// GetBootstrapStatus returns the progress of bootstrapping the Platform chain,
+// including whether it timed out
+func (service *Admin) GetBootstrapStatus(r *http.Request, args *GetBootstrapStatusArgs, reply *GetBootstrapStatusReply) error {
+	service.log.Debug("Admin: GetBootstrapStatus called")
+
+	reply.BootstrapStatus = service.chainManager.BootstrapStatus()
+	return nil
+}
+
 // PeersArgs are the arguments for calling Peers
 type PeersArgs struct{}
 
@@ -115,6 +146,42 @@ func (service *Admin) Peers(r *http.Request, args *PeersArgs, reply *PeersReply)
 	return err
 }
 
+// ConnectPeerArgs are the arguments for calling ConnectPeer
+type ConnectPeerArgs struct {
+	IP     string       `json:"ip"`
+	Port   cjson.Uint16 `json:"port"`
+	NodeID string       `json:"nodeID"`
+}
+
+// ConnectPeerReply are the results from calling ConnectPeer
+type ConnectPeerReply struct {
+	Success bool `json:"success"`
+}
+
+// ConnectPeer dials the peer at the specified address. Fails if the peer's
+// node ID, from its staking certificate, isn't [args.NodeID].
+func (service *Admin) ConnectPeer(r *http.Request, args *ConnectPeerArgs, reply *ConnectPeerReply) error {
+	service.log.Debug("Admin: ConnectPeer called with IP: %s, Port: %d, NodeID: %s", args.IP, args.Port, args.NodeID)
+
+	ip := net.ParseIP(args.IP)
+	if ip == nil {
+		return errBadPeerIP
+	}
+	if args.Port == 0 {
+		return errBadPeerPort
+	}
+	nodeID, err := ids.ShortFromString(args.NodeID)
+	if err != nil {
+		return err
+	}
+
+	if err := service.networking.ConnectPeer(utils.IPDesc{IP: ip, Port: uint16(args.Port)}, nodeID); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
 // StartCPUProfilerArgs are the arguments for calling StartCPUProfiler
 type StartCPUProfilerArgs struct {
 	Filename string `json:"filename"`
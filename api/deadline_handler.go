@@ -0,0 +1,41 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestTimeoutHeader is the header clients may set to the duration, such as
+// "5s", after which they will give up on a request
+const RequestTimeoutHeader = "Request-Timeout"
+
+// deadlineHandler serves each request with a context that is cancelled when the
+// client disconnects, when the client's requested timeout passes, or after
+// [maxTimeout], whichever happens first. If [maxTimeout] is 0, the server
+// doesn't impose a timeout.
+type deadlineHandler struct {
+	maxTimeout time.Duration
+	handler    http.Handler
+}
+
+func (dh deadlineHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	timeout := dh.maxTimeout
+	if header := request.Header.Get(RequestTimeoutHeader); header != "" {
+		if requested, err := time.ParseDuration(header); err == nil && requested > 0 &&
+			(timeout == 0 || requested < timeout) {
+			timeout = requested
+		}
+	}
+	if timeout == 0 {
+		dh.handler.ServeHTTP(writer, request)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	defer cancel()
+	dh.handler.ServeHTTP(writer, request.WithContext(ctx))
+}
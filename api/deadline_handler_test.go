@@ -0,0 +1,74 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type deadlineRecorder struct {
+	deadline    time.Time
+	hasDeadline bool
+	err         error
+}
+
+func (r *deadlineRecorder) ServeHTTP(_ http.ResponseWriter, request *http.Request) {
+	r.deadline, r.hasDeadline = request.Context().Deadline()
+	r.err = request.Context().Err()
+}
+
+func TestDeadlineHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxTimeout time.Duration
+		header     string
+		expected   time.Duration // 0 means no deadline
+	}{
+		{name: "no timeout", expected: 0},
+		{name: "server timeout", maxTimeout: time.Minute, expected: time.Minute},
+		{name: "client timeout", header: "5s", expected: 5 * time.Second},
+		{name: "client timeout below server timeout", maxTimeout: time.Minute, header: "5s", expected: 5 * time.Second},
+		{name: "client timeout above server timeout", maxTimeout: time.Second, header: "5s", expected: time.Second},
+		{name: "malformed client timeout", maxTimeout: time.Minute, header: "soon", expected: time.Minute},
+		{name: "negative client timeout", header: "-5s", expected: 0},
+	}
+	for _, test := range tests {
+		recorder := &deadlineRecorder{}
+		h := deadlineHandler{maxTimeout: test.maxTimeout, handler: recorder}
+
+		request := httptest.NewRequest("POST", "/", nil)
+		if test.header != "" {
+			request.Header.Set(RequestTimeoutHeader, test.header)
+		}
+		start := time.Now()
+		h.ServeHTTP(httptest.NewRecorder(), request)
+
+		switch {
+		case test.expected == 0 && recorder.hasDeadline:
+			t.Fatalf("%s: shouldn't have had a deadline", test.name)
+		case test.expected != 0 && !recorder.hasDeadline:
+			t.Fatalf("%s: should have had a deadline", test.name)
+		case test.expected != 0 && (recorder.deadline.Before(start.Add(test.expected)) || recorder.deadline.After(time.Now().Add(test.expected))):
+			t.Fatalf("%s: deadline should have been %s after the request", test.name, test.expected)
+		}
+	}
+}
+
+func TestDeadlineHandlerClientCancelled(t *testing.T) {
+	recorder := &deadlineRecorder{}
+	h := deadlineHandler{maxTimeout: time.Minute, handler: recorder}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request := httptest.NewRequest("POST", "/", nil).WithContext(ctx)
+	h.ServeHTTP(httptest.NewRecorder(), request)
+
+	if recorder.err != context.Canceled {
+		t.Fatalf("Handler should have seen the client's cancellation but saw %v", recorder.err)
+	}
+}
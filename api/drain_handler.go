@@ -0,0 +1,58 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var errDrainTimeout = errors.New("timed out waiting for in-flight requests")
+
+// drainHandler tracks the requests that [handler] is serving. Once draining,
+// new requests are rejected with 503 Service Unavailable.
+type drainHandler struct {
+	lock     sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+	handler  http.Handler
+}
+
+func (dh *drainHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	dh.lock.Lock()
+	if dh.draining {
+		dh.lock.Unlock()
+		http.Error(writer, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	dh.inFlight.Add(1)
+	dh.lock.Unlock()
+
+	defer dh.inFlight.Done()
+	dh.handler.ServeHTTP(writer, request)
+}
+
+// Drain stops accepting new requests and waits up to [timeout] for in-flight
+// requests to finish. If [timeout] is 0, it doesn't wait.
+func (dh *drainHandler) Drain(timeout time.Duration) error {
+	dh.lock.Lock()
+	dh.draining = true
+	dh.lock.Unlock()
+
+	// No requests can be added to [inFlight] once draining
+	done := make(chan struct{})
+	go func() {
+		dh.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return errDrainTimeout
+	}
+}
@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// blockingHandler signals [started] when it starts serving a request and
+// finishes once [finish] is closed
+type blockingHandler struct {
+	started, finish chan struct{}
+}
+
+func (h *blockingHandler) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	h.started <- struct{}{}
+	<-h.finish
+	writer.WriteHeader(http.StatusOK)
+}
+
+func TestDrain(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080)
+
+	handler := &blockingHandler{
+		started: make(chan struct{}, 1),
+		finish:  make(chan struct{}),
+	}
+	if err := s.AddRoute(&common.HTTPHandler{LockOptions: common.NoLock, Handler: handler}, new(sync.RWMutex), "vm/lol", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Start a request that is in flight when draining starts
+	inFlight := httptest.NewRecorder()
+	inFlightDone := make(chan struct{})
+	go func() {
+		s.drain.ServeHTTP(inFlight, httptest.NewRequest("POST", "/ext/vm/lol", nil))
+		close(inFlightDone)
+	}()
+	<-handler.started
+
+	drainErr := make(chan error, 1)
+	go func() { drainErr <- s.Drain(time.Minute) }()
+	for draining := false; !draining; {
+		s.drain.lock.Lock()
+		draining = s.drain.draining
+		s.drain.lock.Unlock()
+	}
+
+	// New requests are rejected while the in-flight request is served
+	rejected := httptest.NewRecorder()
+	s.drain.ServeHTTP(rejected, httptest.NewRequest("POST", "/ext/vm/lol", nil))
+	if rejected.Code != http.StatusServiceUnavailable {
+		t.Fatalf("New request returned %d during drain, expected %d", rejected.Code, http.StatusServiceUnavailable)
+	}
+	select {
+	case err := <-drainErr:
+		t.Fatalf("Drain returned %v while a request was in flight", err)
+	default:
+	}
+
+	close(handler.finish)
+	<-inFlightDone
+	if inFlight.Code != http.StatusOK {
+		t.Fatalf("In-flight request returned %d, expected %d", inFlight.Code, http.StatusOK)
+	}
+	if err := <-drainErr; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080)
+
+	handler := &blockingHandler{
+		started: make(chan struct{}, 1),
+		finish:  make(chan struct{}),
+	}
+	if err := s.AddRoute(&common.HTTPHandler{LockOptions: common.NoLock, Handler: handler}, new(sync.RWMutex), "vm/lol", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+	defer close(handler.finish)
+
+	go s.drain.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/ext/vm/lol", nil))
+	<-handler.started
+
+	if err := s.Drain(time.Millisecond); err != errDrainTimeout {
+		t.Fatalf("Drain should have failed with %s but returned %v", errDrainTimeout, err)
+	}
+}
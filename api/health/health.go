@@ -0,0 +1,149 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// Names of the checks in a Report
+const (
+	BootstrappedCheck = "bootstrapped"
+	PeersCheck        = "peers"
+	LastAcceptedCheck = "lastAccepted"
+)
+
+// Thresholds are the criteria, besides having bootstrapped, that a healthy
+// node meets
+type Thresholds struct {
+	// MinPeers is the minimum number of connected peers.
+	// If 0, the number of peers isn't checked.
+	MinPeers int
+	// MaxTimeSinceAccept is the maximum time since a block or vertex was last
+	// accepted on any chain, or since the node started if none has been.
+	// If 0, the time since the last acceptance isn't checked.
+	MaxTimeSinceAccept time.Duration
+}
+
+// CheckResult is the outcome of one health check
+type CheckResult struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message"`
+}
+
+// Report is the outcome of every health check. The node is healthy iff every
+// check passed.
+type Report struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+// Peerable can return a group of peers
+type Peerable interface{ Peers() []utils.IPDesc }
+
+// Checker evaluates the health of the node. It's notified of accepted
+// containers as a triggers.Acceptor.
+type Checker struct {
+	lock sync.Mutex
+
+	thresholds      Thresholds
+	peers           Peerable
+	bootstrapStatus func() chains.BootstrapStatus
+	clock           timer.Clock
+
+	// When a container was last accepted, or when the checker was created if
+	// none has been
+	lastAccepted time.Time
+	// Chain the last container was accepted on, if any
+	lastAcceptedChain ids.ID
+}
+
+// NewChecker returns a checker that evaluates the node against [thresholds],
+// using [peers] to count its peers and [bootstrapStatus] to check whether the
+// Platform chain has bootstrapped
+func NewChecker(thresholds Thresholds, peers Peerable, bootstrapStatus func() chains.BootstrapStatus) *Checker {
+	c := &Checker{
+		thresholds:      thresholds,
+		peers:           peers,
+		bootstrapStatus: bootstrapStatus,
+	}
+	c.lastAccepted = c.clock.Time()
+	return c
+}
+
+// Accept records that [containerID] was accepted on [chainID]
+func (c *Checker) Accept(chainID, containerID ids.ID, _ []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lastAccepted = c.clock.Time()
+	c.lastAcceptedChain = chainID
+	return nil
+}
+
+// Report evaluates every health check
+func (c *Checker) Report() Report {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	report := Report{
+		Checks: []CheckResult{
+			c.checkBootstrapped(),
+			c.checkPeers(),
+			c.checkLastAccepted(),
+		},
+	}
+	report.Healthy = true
+	for _, check := range report.Checks {
+		report.Healthy = report.Healthy && check.Healthy
+	}
+	return report
+}
+
+func (c *Checker) checkBootstrapped() CheckResult {
+	state := c.bootstrapStatus().State
+	return CheckResult{
+		Name:    BootstrappedCheck,
+		Healthy: state == chains.Bootstrapped,
+		Message: fmt.Sprintf("Platform chain is %s", state),
+	}
+}
+
+func (c *Checker) checkPeers() CheckResult {
+	numPeers := len(c.peers.Peers())
+	result := CheckResult{
+		Name:    PeersCheck,
+		Healthy: numPeers >= c.thresholds.MinPeers,
+		Message: fmt.Sprintf("connected to %d peers", numPeers),
+	}
+	if !result.Healthy {
+		result.Message += fmt.Sprintf(", fewer than the minimum of %d", c.thresholds.MinPeers)
+	}
+	return result
+}
+
+func (c *Checker) checkLastAccepted() CheckResult {
+	timeSinceAccept := c.clock.Time().Sub(c.lastAccepted)
+	result := CheckResult{
+		Name:    LastAcceptedCheck,
+		Healthy: c.thresholds.MaxTimeSinceAccept == 0 || timeSinceAccept <= c.thresholds.MaxTimeSinceAccept,
+	}
+	if c.lastAcceptedChain.IsZero() {
+		result.Message = fmt.Sprintf("nothing accepted in %s", timeSinceAccept)
+	} else {
+		result.Message = fmt.Sprintf("last accepted on chain %s %s ago", c.lastAcceptedChain, timeSinceAccept)
+	}
+	if !result.Healthy {
+		result.Message += fmt.Sprintf(", more than the maximum of %s", c.thresholds.MaxTimeSinceAccept)
+	}
+	return result
+}
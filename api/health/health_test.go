@@ -0,0 +1,98 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+)
+
+type testPeers []utils.IPDesc
+
+func (p testPeers) Peers() []utils.IPDesc { return p }
+
+// newTestChecker returns a checker for a bootstrapped node with [numPeers]
+// peers, whose clock is fixed at the time it was created
+func newTestChecker(thresholds Thresholds, numPeers int) *Checker {
+	status := chains.BootstrapStatus{State: chains.Bootstrapped}
+	c := NewChecker(thresholds, make(testPeers, numPeers), func() chains.BootstrapStatus { return status })
+	c.clock.Set(c.lastAccepted)
+	return c
+}
+
+// assertReport fails the test unless [report] is healthy iff [healthy] and
+// exactly the checks named in [failed] failed
+func assertReport(t *testing.T, report Report, healthy bool, failed ...string) {
+	t.Helper()
+
+	if report.Healthy != healthy {
+		t.Fatalf("report should have had healthy=%v: %+v", healthy, report)
+	}
+	failing := map[string]bool{}
+	for _, name := range failed {
+		failing[name] = true
+	}
+	for _, check := range report.Checks {
+		if check.Healthy == failing[check.Name] {
+			t.Fatalf("check %s should have had healthy=%v: %+v", check.Name, !failing[check.Name], check)
+		}
+	}
+}
+
+func TestHealthy(t *testing.T) {
+	c := newTestChecker(Thresholds{MinPeers: 2, MaxTimeSinceAccept: time.Minute}, 2)
+	c.clock.Set(c.clock.Time().Add(time.Minute))
+
+	report := c.Report()
+	assertReport(t, report, true)
+	if len(report.Checks) != 3 {
+		t.Fatalf("report should have had 3 checks but had %d", len(report.Checks))
+	}
+}
+
+func TestUnhealthyNotBootstrapped(t *testing.T) {
+	c := newTestChecker(Thresholds{}, 0)
+	c.bootstrapStatus = func() chains.BootstrapStatus { return chains.BootstrapStatus{State: chains.Degraded} }
+
+	assertReport(t, c.Report(), false, BootstrappedCheck)
+}
+
+func TestUnhealthyTooFewPeers(t *testing.T) {
+	c := newTestChecker(Thresholds{MinPeers: 3}, 2)
+
+	assertReport(t, c.Report(), false, PeersCheck)
+}
+
+func TestUnhealthyNothingAccepted(t *testing.T) {
+	c := newTestChecker(Thresholds{MaxTimeSinceAccept: time.Minute}, 0)
+	c.clock.Set(c.clock.Time().Add(time.Minute + time.Second))
+
+	assertReport(t, c.Report(), false, LastAcceptedCheck)
+}
+
+func TestUnhealthyLastAcceptedTooOld(t *testing.T) {
+	c := newTestChecker(Thresholds{MaxTimeSinceAccept: time.Minute}, 0)
+	start := c.clock.Time()
+
+	c.clock.Set(start.Add(50 * time.Second))
+	if err := c.Accept(ids.NewID([32]byte{1}), ids.NewID([32]byte{2}), nil); err != nil {
+		t.Fatal(err)
+	}
+	c.clock.Set(start.Add(100 * time.Second))
+	assertReport(t, c.Report(), true)
+
+	c.clock.Set(start.Add(111 * time.Second))
+	assertReport(t, c.Report(), false, LastAcceptedCheck)
+}
+
+func TestThresholdsDisabled(t *testing.T) {
+	c := newTestChecker(Thresholds{}, 0)
+	c.clock.Set(c.clock.Time().Add(24 * time.Hour))
+
+	assertReport(t, c.Report(), true)
+}
@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"net/http"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Health is the API service for checking the health of the node
+type Health struct {
+	log     logging.Logger
+	checker *Checker
+}
+
+// NewService returns a new health API service that reports the health
+// evaluated by [checker]
+func NewService(log logging.Logger, checker *Checker) *common.HTTPHandler {
+	newServer := rpc.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Health{log: log, checker: checker}, "health")
+	return &common.HTTPHandler{Handler: newServer}
+}
+
+// GetHealthArgs are the arguments for calling GetHealth
+type GetHealthArgs struct{}
+
+// GetHealthReply are the results from calling GetHealth
+type GetHealthReply struct {
+	Report
+}
+
+// GetHealth returns whether the node is healthy, and the outcome of each
+// health check
+func (service *Health) GetHealth(r *http.Request, args *GetHealthArgs, reply *GetHealthReply) error {
+	service.log.Debug("Health: GetHealth called")
+
+	reply.Report = service.checker.Report()
+	return nil
+}
@@ -0,0 +1,27 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// methodHandler only serves requests whose HTTP method is in [methods]. Other
+// requests are answered with 405 Method Not Allowed.
+type methodHandler struct {
+	methods []string
+	handler http.Handler
+}
+
+func (mh methodHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	for _, method := range mh.methods {
+		if request.Method == method {
+			mh.handler.ServeHTTP(writer, request)
+			return
+		}
+	}
+	writer.Header().Set("Allow", strings.Join(mh.methods, ", "))
+	http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// CorrelationIDHeader is the header clients may set to an ID that is included
+// in the request's log line, to match it with the client's own logs
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// requestLog is the outcome of a request, as logged by requestLogger
+type requestLog struct {
+	Method        string        `json:"method"`
+	Path          string        `json:"path"`
+	Status        int           `json:"status"`
+	Duration      time.Duration `json:"duration"`
+	CorrelationID string        `json:"correlationID,omitempty"`
+}
+
+func (rl requestLog) String() string {
+	s := fmt.Sprintf("%s %s %d %s", rl.Method, rl.Path, rl.Status, rl.Duration)
+	if rl.CorrelationID != "" {
+		s += " correlationID=" + rl.CorrelationID
+	}
+	return s
+}
+
+// statusRecorder records the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// requestLogger logs the method, path, status code, duration and correlation ID
+// of every request at [level]. If [json] is true, the fields are logged as a
+// JSON object. If [slowThreshold] > 0, requests that take at least that long
+// are logged at the Warn level instead, even if [level] is Off.
+type requestLogger struct {
+	log           logging.Logger
+	level         logging.Level
+	json          bool
+	slowThreshold time.Duration
+	handler       http.Handler
+}
+
+func (rl requestLogger) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	recorder := &statusRecorder{ResponseWriter: writer}
+	start := time.Now()
+	rl.handler.ServeHTTP(recorder, request)
+
+	entry := requestLog{
+		Method:        request.Method,
+		Path:          request.URL.Path,
+		Status:        recorder.status,
+		Duration:      time.Since(start),
+		CorrelationID: request.Header.Get(CorrelationIDHeader),
+	}
+	if entry.Status == 0 {
+		entry.Status = http.StatusOK
+	}
+
+	level := rl.level
+	slow := rl.slowThreshold > 0 && entry.Duration >= rl.slowThreshold
+	if slow {
+		level = logging.Warn
+	}
+	if level == logging.Off {
+		return
+	}
+
+	line := entry.String()
+	if rl.json {
+		entryJSON, err := json.Marshal(entry)
+		if err != nil {
+			rl.log.Error("couldn't marshal request log: %s", err)
+			return
+		}
+		line = string(entryJSON)
+	}
+	if slow {
+		line = "slow request: " + line
+	}
+
+	switch level {
+	case logging.Fatal:
+		rl.log.Fatal("%s", line)
+	case logging.Error:
+		rl.log.Error("%s", line)
+	case logging.Warn:
+		rl.log.Warn("%s", line)
+	case logging.Info:
+		rl.log.Info("%s", line)
+	case logging.Debug:
+		rl.log.Debug("%s", line)
+	case logging.Verbo:
+		rl.log.Verbo("%s", line)
+	}
+}
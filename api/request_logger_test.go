@@ -0,0 +1,126 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// lineLogger records the lines logged at the Debug and Warn levels
+type lineLogger struct {
+	logging.NoLog
+	lines     []string
+	warnLines []string
+}
+
+func (l *lineLogger) Debug(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *lineLogger) Warn(format string, args ...interface{}) {
+	l.warnLines = append(l.warnLines, fmt.Sprintf(format, args...))
+}
+
+func TestRequestLogger(t *testing.T) {
+	log := &lineLogger{}
+	h := requestLogger{
+		log:   log,
+		level: logging.Debug,
+		handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	}
+
+	request := httptest.NewRequest("POST", "/ext/bc/X", nil)
+	request.Header.Set(CorrelationIDHeader, "abc123")
+	h.ServeHTTP(httptest.NewRecorder(), request)
+
+	if len(log.lines) != 1 {
+		t.Fatalf("should have logged 1 line but logged %d", len(log.lines))
+	}
+	for _, field := range []string{"POST", "/ext/bc/X", "418", "correlationID=abc123"} {
+		if !strings.Contains(log.lines[0], field) {
+			t.Fatalf("log line %q should have contained %q", log.lines[0], field)
+		}
+	}
+}
+
+func TestRequestLoggerJSON(t *testing.T) {
+	log := &lineLogger{}
+	h := requestLogger{
+		log:   log,
+		level: logging.Debug,
+		json:  true,
+		handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}),
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ext/admin", nil))
+
+	if len(log.lines) != 1 {
+		t.Fatalf("should have logged 1 line but logged %d", len(log.lines))
+	}
+	entry := requestLog{}
+	if err := json.Unmarshal([]byte(log.lines[0]), &entry); err != nil {
+		t.Fatalf("log line %q should have been JSON: %s", log.lines[0], err)
+	}
+	if entry.Method != "GET" || entry.Path != "/ext/admin" || entry.Status != http.StatusOK || entry.CorrelationID != "" {
+		t.Fatalf("wrong log entry: %+v", entry)
+	}
+}
+
+func TestRequestLoggerOtherLevel(t *testing.T) {
+	log := &lineLogger{}
+	h := requestLogger{
+		log:     log,
+		level:   logging.Info,
+		handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if len(log.lines) != 0 {
+		t.Fatal("shouldn't have logged at the Debug level")
+	}
+}
+
+func TestRequestLoggerSlowQuery(t *testing.T) {
+	log := &lineLogger{}
+	slow := requestLogger{
+		log:           log,
+		level:         logging.Off,
+		slowThreshold: 10 * time.Millisecond,
+		handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			time.Sleep(20 * time.Millisecond)
+		}),
+	}
+	fast := slow
+	fast.handler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	fast.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/ext/fast", nil))
+	if len(log.warnLines) != 0 || len(log.lines) != 0 {
+		t.Fatal("shouldn't have logged a fast request")
+	}
+
+	slow.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/ext/slow", nil))
+	if len(log.warnLines) != 1 {
+		t.Fatalf("should have logged 1 slow request but logged %d", len(log.warnLines))
+	}
+	for _, field := range []string{"slow request", "POST", "/ext/slow"} {
+		if !strings.Contains(log.warnLines[0], field) {
+			t.Fatalf("log line %q should have contained %q", log.warnLines[0], field)
+		}
+	}
+	if len(log.lines) != 0 {
+		t.Fatal("a slow request shouldn't have also been logged at the request log level")
+	}
+}
@@ -25,6 +25,7 @@ type router struct {
 	reservedRoutes map[string]bool                    // Reserves routes so that there can't be alias that conflict
 	aliases        map[string][]string                // Maps a route to a set of reserved routes
 	routes         map[string]map[string]http.Handler // Maps routes to a handler
+	owners         map[string]string                  // Maps a full url to the route that registered it
 }
 
 func newRouter() *router {
@@ -33,6 +34,7 @@ func newRouter() *router {
 		reservedRoutes: make(map[string]bool),
 		aliases:        make(map[string][]string),
 		routes:         make(map[string]map[string]http.Handler),
+		owners:         make(map[string]string),
 	}
 }
 
@@ -72,27 +74,31 @@ func (r *router) addRouter(base, endpoint string, handler http.Handler) error {
 		return fmt.Errorf("couldn't route to %s as that route is either aliased or already maps to a handler", base)
 	}
 
-	return r.forceAddRouter(base, endpoint, handler)
+	return r.forceAddRouter(base, base, endpoint, handler)
 }
 
-func (r *router) forceAddRouter(base, endpoint string, handler http.Handler) error {
+// forceAddRouter routes [base]+[endpoint], and every alias of [base], to
+// [handler]. [owner] is the route that [base] is an alias of, or [base] itself.
+// Fails if another route already routes to the same url.
+func (r *router) forceAddRouter(owner, base, endpoint string, handler http.Handler) error {
+	url := base + endpoint
+	if existingOwner, exists := r.owners[url]; exists {
+		return fmt.Errorf("couldn't route %s for %s as that url is already routed for %s", url, owner, existingOwner)
+	}
+
 	endpoints := r.routes[base]
 	if endpoints == nil {
 		endpoints = make(map[string]http.Handler)
 	}
-	url := base + endpoint
-	if _, exists := endpoints[endpoint]; exists {
-		return fmt.Errorf("failed to create endpoint as %s already exists", url)
-	}
-
 	endpoints[endpoint] = handler
 	r.routes[base] = endpoints
+	r.owners[url] = owner
 	r.router.Handle(url, handler)
 
 	var err error
 	if aliases, exists := r.aliases[base]; exists {
 		for _, alias := range aliases {
-			if innerErr := r.forceAddRouter(alias, endpoint, handler); err == nil {
+			if innerErr := r.forceAddRouter(owner, alias, endpoint, handler); err == nil {
 				err = innerErr
 			}
 		}
@@ -121,8 +127,9 @@ func (r *router) AddAlias(base string, aliases ...string) error {
 	var err error
 	if endpoints, exists := r.routes[base]; exists {
 		for endpoint, handler := range endpoints {
+			owner := r.owners[base+endpoint]
 			for _, alias := range aliases {
-				if innerErr := r.forceAddRouter(alias, endpoint, handler); err == nil {
+				if innerErr := r.forceAddRouter(owner, alias, endpoint, handler); err == nil {
 					err = innerErr
 				}
 			}
@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -67,3 +68,34 @@ func TestBlock(t *testing.T) {
 		t.Fatalf("Permanently locked %s", "1")
 	}
 }
+
+func TestConflictingRoutes(t *testing.T) {
+	r := newRouter()
+
+	handler1 := &testHandler{}
+	if err := r.AddRouter("vm/1", "/rpc", handler1); err != nil {
+		t.Fatal(err)
+	}
+	handler2 := &testHandler{}
+	if err := r.AddRouter("vm/2", "", handler2); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddAlias("vm/1", "vm/shared"); err != nil {
+		t.Fatal(err)
+	}
+
+	// vm/shared/rpc is already routed to vm/1's handler
+	err := r.AddAlias("vm/2", "vm/shared/rpc")
+	if err == nil {
+		t.Fatal("Should have failed to route the same url twice")
+	}
+	for _, expected := range []string{"vm/shared/rpc", "vm/1", "vm/2"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("Error %q should have contained %q", err, expected)
+		}
+	}
+
+	if err := r.AddRouter("vm/1", "/rpc", handler2); err == nil {
+		t.Fatal("Should have failed to route the same endpoint twice")
+	}
+}
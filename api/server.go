@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"time"
 
 	"github.com/gorilla/handlers"
 
@@ -31,7 +32,19 @@ type Server struct {
 	log     logging.Logger
 	factory logging.Factory
 	router  *router
+	drain   *drainHandler
 	portURL string
+
+	// requestTimeout is the maximum duration of a request. 0 means no limit.
+	requestTimeout time.Duration
+
+	// requestLogLevel is the level requests are logged at. Off means requests
+	// aren't logged.
+	requestLogLevel logging.Level
+	requestLogJSON  bool
+	// slowRequestThreshold is the duration after which requests are logged
+	// as slow. 0 means requests aren't logged as slow.
+	slowRequestThreshold time.Duration
 }
 
 // Initialize creates the API server at the provided port
@@ -40,20 +53,46 @@ func (s *Server) Initialize(log logging.Logger, factory logging.Factory, port ui
 	s.factory = factory
 	s.portURL = fmt.Sprintf(":%d", port)
 	s.router = newRouter()
+	s.drain = &drainHandler{handler: s.router}
+}
+
+// SetRequestTimeout sets the maximum duration of requests to routes added
+// after this call. Handlers see the deadline through the request's context.
+// If [timeout] is 0, requests only time out if the client asks them to.
+func (s *Server) SetRequestTimeout(timeout time.Duration) { s.requestTimeout = timeout }
+
+// SetRequestLogging logs the outcome of each request to routes added after
+// this call at [level]. If [json] is true, requests are logged as JSON objects.
+// If [level] is Off, requests aren't logged.
+func (s *Server) SetRequestLogging(level logging.Level, json bool) {
+	s.requestLogLevel = level
+	s.requestLogJSON = json
+}
+
+// SetSlowRequestThreshold logs requests to routes added after this call that
+// take at least [threshold] at the Warn level, regardless of the request log
+// level. If [threshold] is 0, requests aren't logged as slow.
+func (s *Server) SetSlowRequestThreshold(threshold time.Duration) {
+	s.slowRequestThreshold = threshold
 }
 
 // Dispatch starts the API server
 func (s *Server) Dispatch() error {
-	handler := cors.Default().Handler(s.router)
+	handler := cors.Default().Handler(s.drain)
 	return http.ListenAndServe(s.portURL, handler)
 }
 
 // DispatchTLS starts the API server with the provided TLS certificate
 func (s *Server) DispatchTLS(certFile, keyFile string) error {
-	handler := cors.Default().Handler(s.router)
+	handler := cors.Default().Handler(s.drain)
 	return http.ListenAndServeTLS(s.portURL, certFile, keyFile, handler)
 }
 
+// Drain makes the server respond to new requests with 503 Service Unavailable
+// and waits up to [timeout] for in-flight requests to finish. Returns an error
+// if requests are still in flight after [timeout].
+func (s *Server) Drain(timeout time.Duration) error { return s.drain.Drain(timeout) }
+
 // RegisterChain registers the API endpoints associated with this chain That
 // is, add <route, handler> pairs to server so that http calls can be made to
 // the vm
@@ -95,22 +134,43 @@ func (s *Server) AddRoute(handler *common.HTTPHandler, lock *sync.RWMutex, base,
 	h := handlers.CombinedLoggingHandler(log, handler.Handler)
 	switch handler.LockOptions {
 	case common.WriteLock:
-		return s.router.AddRouter(url, endpoint, middlewareHandler{
+		h = middlewareHandler{
 			before:  lock.Lock,
 			after:   lock.Unlock,
 			handler: h,
-		})
+		}
 	case common.ReadLock:
-		return s.router.AddRouter(url, endpoint, middlewareHandler{
+		h = middlewareHandler{
 			before:  lock.RLock,
 			after:   lock.RUnlock,
 			handler: h,
-		})
+		}
 	case common.NoLock:
-		return s.router.AddRouter(url, endpoint, h)
 	default:
 		return errUnknownLockOption
 	}
+	// Requests with other methods don't need to wait for the lock
+	if len(handler.Methods) > 0 {
+		h = methodHandler{
+			methods: handler.Methods,
+			handler: h,
+		}
+	}
+	// Time spent waiting for the lock counts against the deadline
+	h = deadlineHandler{
+		maxTimeout: s.requestTimeout,
+		handler:    h,
+	}
+	if s.requestLogLevel != logging.Off || s.slowRequestThreshold > 0 {
+		h = requestLogger{
+			log:           s.log,
+			level:         s.requestLogLevel,
+			json:          s.requestLogJSON,
+			slowThreshold: s.slowRequestThreshold,
+			handler:       h,
+		}
+	}
+	return s.router.AddRouter(url, endpoint, h)
 }
 
 // AddAliases registers aliases to the server
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/rpc/v2"
 	"github.com/gorilla/rpc/v2/json2"
 
@@ -58,3 +59,32 @@ func TestCall(t *testing.T) {
 		t.Fatalf("Should have been called")
 	}
 }
+
+func TestRawRoute(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080)
+
+	handler := &common.HTTPHandler{
+		Methods: []string{"GET"},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(mux.Vars(r)["id"]))
+		}),
+	}
+	if err := s.AddRoute(handler, new(sync.RWMutex), "vm/lol", "/block/{id}", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, httptest.NewRequest("GET", "/ext/vm/lol/block/abc", nil))
+	if writer.Code != http.StatusOK || writer.Body.String() != "abc" {
+		t.Fatalf("GET returned (%d, %q), expected (%d, %q)", writer.Code, writer.Body.String(), http.StatusOK, "abc")
+	}
+
+	writer = httptest.NewRecorder()
+	s.router.ServeHTTP(writer, httptest.NewRequest("POST", "/ext/vm/lol/block/abc", nil))
+	if writer.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST returned %d, expected %d", writer.Code, http.StatusMethodNotAllowed)
+	} else if allow := writer.Header().Get("Allow"); allow != "GET" {
+		t.Fatalf("Allow header was %q, expected %q", allow, "GET")
+	}
+}
@@ -0,0 +1,194 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// BootstrapTimeoutPolicy is what the node does if bootstrapping takes longer
+// than the bootstrap timeout
+type BootstrapTimeoutPolicy int
+
+// Bootstrap timeout policies
+const (
+	// BootstrapRetry restarts bootstrapping from scratch and waits another
+	// timeout
+	BootstrapRetry BootstrapTimeoutPolicy = iota
+	// BootstrapDegrade keeps the node running in the degraded state
+	BootstrapDegrade
+	// BootstrapExit shuts down the node
+	BootstrapExit
+)
+
+// ToBootstrapTimeoutPolicy parses the string representation of a policy
+func ToBootstrapTimeoutPolicy(policy string) (BootstrapTimeoutPolicy, error) {
+	switch strings.ToLower(policy) {
+	case "retry":
+		return BootstrapRetry, nil
+	case "degrade":
+		return BootstrapDegrade, nil
+	case "exit":
+		return BootstrapExit, nil
+	default:
+		return BootstrapDegrade, fmt.Errorf("unknown bootstrap timeout policy: %s", policy)
+	}
+}
+
+func (p BootstrapTimeoutPolicy) String() string {
+	switch p {
+	case BootstrapRetry:
+		return "retry"
+	case BootstrapDegrade:
+		return "degrade"
+	case BootstrapExit:
+		return "exit"
+	default:
+		return "unknown"
+	}
+}
+
+// Bootstrap states
+const (
+	Bootstrapping = "bootstrapping"
+	Bootstrapped  = "bootstrapped"
+	// Degraded means bootstrapping timed out but the node kept running
+	Degraded = "degraded"
+	// BootstrapFailed means bootstrapping timed out and the node is exiting
+	BootstrapFailed = "failed"
+)
+
+// BootstrapStatus is the progress of bootstrapping the Platform chain
+type BootstrapStatus struct {
+	State string `json:"state"`
+	// Attempts is the number of times bootstrapping has been started
+	Attempts int `json:"attempts"`
+	// Started is when the current attempt started
+	Started time.Time `json:"started"`
+}
+
+// bootstrapMonitor tracks whether the Platform chain has bootstrapped. If it
+// hasn't within [timeout], [policy] is applied: [restart] is called and the
+// timeout restarts, the node is marked as degraded, or [exit] is called.
+type bootstrapMonitor struct {
+	lock    sync.Mutex
+	status  BootstrapStatus
+	timer   *time.Timer
+	stopped bool
+
+	log     logging.Logger
+	timeout time.Duration
+	policy  BootstrapTimeoutPolicy
+	exit    func()
+	// restarts bootstrapping the Platform chain. May be nil until the chain is
+	// created.
+	restart func()
+}
+
+func (bm *bootstrapMonitor) initialize() {
+	bm.status = BootstrapStatus{
+		State:    Bootstrapping,
+		Attempts: 1,
+		Started:  time.Now(),
+	}
+}
+
+// start the bootstrap timeout. If [timeout] is 0, bootstrapping never times
+// out.
+func (bm *bootstrapMonitor) start(
+	log logging.Logger,
+	timeout time.Duration,
+	policy BootstrapTimeoutPolicy,
+	exit func(),
+) {
+	bm.lock.Lock()
+	defer bm.lock.Unlock()
+
+	bm.log = log
+	bm.timeout = timeout
+	bm.policy = policy
+	bm.exit = exit
+	if timeout == 0 || bm.status.State == Bootstrapped {
+		return
+	}
+	bm.timer = time.AfterFunc(timeout, bm.timedOut)
+}
+
+// bootstrapped marks bootstrapping as finished. A degraded node becomes healthy
+// again if bootstrapping finishes late, but a node that is exiting keeps
+// exiting.
+func (bm *bootstrapMonitor) bootstrapped() {
+	bm.lock.Lock()
+	defer bm.lock.Unlock()
+
+	if bm.status.State == Bootstrapped || bm.status.State == BootstrapFailed {
+		return
+	}
+	bm.status.State = Bootstrapped
+	if bm.timer != nil {
+		bm.timer.Stop()
+	}
+}
+
+func (bm *bootstrapMonitor) timedOut() {
+	bm.lock.Lock()
+	if bm.stopped || bm.status.State != Bootstrapping {
+		bm.lock.Unlock()
+		return
+	}
+
+	var action func()
+	switch bm.policy {
+	case BootstrapRetry:
+		bm.status.Attempts++
+		bm.status.Started = time.Now()
+		bm.log.Warn("bootstrapping didn't finish within %s, starting attempt %d", bm.timeout, bm.status.Attempts)
+		bm.timer.Reset(bm.timeout)
+		action = bm.restart
+	case BootstrapExit:
+		bm.status.State = BootstrapFailed
+		bm.log.Fatal("bootstrapping didn't finish within %s, shutting down", bm.timeout)
+		action = bm.exit
+	default:
+		bm.status.State = Degraded
+		bm.log.Error("bootstrapping didn't finish within %s, the node is degraded until it finishes", bm.timeout)
+	}
+	bm.lock.Unlock()
+
+	if action != nil {
+		action()
+	}
+}
+
+// setRestart sets the function that restarts bootstrapping
+func (bm *bootstrapMonitor) setRestart(restart func()) {
+	bm.lock.Lock()
+	defer bm.lock.Unlock()
+
+	bm.restart = restart
+}
+
+// stop the bootstrap timeout
+func (bm *bootstrapMonitor) stop() {
+	bm.lock.Lock()
+	defer bm.lock.Unlock()
+
+	bm.stopped = true
+	if bm.timer != nil {
+		bm.timer.Stop()
+	}
+}
+
+// getStatus returns the progress of bootstrapping
+func (bm *bootstrapMonitor) getStatus() BootstrapStatus {
+	bm.lock.Lock()
+	defer bm.lock.Unlock()
+
+	return bm.status
+}
@@ -0,0 +1,116 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// newNeverBootstrapped returns a monitor for a chain that never finishes
+// bootstrapping. Calls to exit are sent on the returned channel.
+func newNeverBootstrapped(policy BootstrapTimeoutPolicy) (*bootstrapMonitor, chan struct{}) {
+	exits := make(chan struct{}, 10)
+	bm := &bootstrapMonitor{}
+	bm.initialize()
+	bm.start(
+		logging.NoLog{},
+		10*time.Millisecond,
+		policy,
+		func() { exits <- struct{}{} },
+	)
+	return bm, exits
+}
+
+func TestBootstrapMonitorRetry(t *testing.T) {
+	bm, exits := newNeverBootstrapped(BootstrapRetry)
+	defer bm.stop()
+	restarts := make(chan struct{}, 10)
+	bm.setRestart(func() { restarts <- struct{}{} })
+	started := bm.getStatus().Started
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-restarts:
+		case <-time.After(time.Second):
+			t.Fatal("bootstrapping should have been restarted")
+		}
+	}
+	if status := bm.getStatus(); status.State != Bootstrapping || status.Attempts < 3 || !status.Started.After(started) {
+		t.Fatalf("wrong status after restarting: %+v", status)
+	}
+	if len(exits) != 0 {
+		t.Fatal("shouldn't have exited")
+	}
+
+	// Bootstrapping finishing stops the restarts
+	bm.bootstrapped()
+	time.Sleep(20 * time.Millisecond) // Let a restart that already started finish
+	for len(restarts) > 0 {
+		<-restarts
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(restarts) != 0 || bm.getStatus().State != Bootstrapped {
+		t.Fatal("shouldn't have restarted after bootstrapping finished")
+	}
+}
+
+func TestBootstrapMonitorDegrade(t *testing.T) {
+	bm, exits := newNeverBootstrapped(BootstrapDegrade)
+	defer bm.stop()
+
+	deadline := time.Now().Add(time.Second)
+	for bm.getStatus().State != Degraded {
+		if time.Now().After(deadline) {
+			t.Fatal("the node should have been degraded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(exits) != 0 {
+		t.Fatal("a degraded node shouldn't exit")
+	}
+
+	// A degraded node recovers if bootstrapping finishes late
+	bm.bootstrapped()
+	if state := bm.getStatus().State; state != Bootstrapped {
+		t.Fatalf("state should have been %s but was %s", Bootstrapped, state)
+	}
+}
+
+func TestBootstrapMonitorExit(t *testing.T) {
+	bm, exits := newNeverBootstrapped(BootstrapExit)
+	defer bm.stop()
+
+	select {
+	case <-exits:
+	case <-time.After(time.Second):
+		t.Fatal("the node should have exited")
+	}
+	if state := bm.getStatus().State; state != BootstrapFailed {
+		t.Fatalf("state should have been %s but was %s", BootstrapFailed, state)
+	}
+}
+
+func TestBootstrapMonitorNoTimeout(t *testing.T) {
+	bm := &bootstrapMonitor{}
+	bm.initialize()
+	bm.start(logging.NoLog{}, 0, BootstrapExit, func() { t.Fatal("shouldn't have exited") })
+	time.Sleep(20 * time.Millisecond)
+	if state := bm.getStatus().State; state != Bootstrapping {
+		t.Fatalf("state should have been %s but was %s", Bootstrapping, state)
+	}
+}
+
+func TestToBootstrapTimeoutPolicy(t *testing.T) {
+	for _, policy := range []BootstrapTimeoutPolicy{BootstrapRetry, BootstrapDegrade, BootstrapExit} {
+		if parsed, err := ToBootstrapTimeoutPolicy(policy.String()); err != nil || parsed != policy {
+			t.Fatalf("parsing %s returned (%s, %v)", policy, parsed, err)
+		}
+	}
+	if _, err := ToBootstrapTimeoutPolicy("panic"); err == nil {
+		t.Fatal("should have failed to parse an unknown policy")
+	}
+}
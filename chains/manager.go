@@ -75,6 +75,15 @@ type Manager interface {
 	// Add an alias to a chain
 	Alias(ids.ID, string) error
 
+	// Apply [policy] if the Platform chain hasn't bootstrapped within
+	// [timeout]. If [policy] is to retry, bootstrapping the Platform chain is
+	// restarted. [exit] is called when the node should shut down. If [timeout]
+	// is 0, bootstrapping never times out.
+	MonitorBootstrap(timeout time.Duration, policy BootstrapTimeoutPolicy, exit func())
+
+	// Return the progress of bootstrapping the Platform chain
+	BootstrapStatus() BootstrapStatus
+
 	Shutdown()
 }
 
@@ -113,15 +122,20 @@ type manager struct {
 	server          *api.Server           // Handles HTTP API calls
 	keystore        *keystore.Keystore
 	sharedMemory    *atomic.SharedMemory
+	features        map[string]bool // Feature flags enabled on new chains
+	peers           snow.PeerSet    // Peers this node is connected to
 
 	unblocked     bool
 	blockedChains []ChainParameters
+
+	bootstrap bootstrapMonitor
 }
 
 // New returns a new Manager where:
 //     <db> is this node's database
 //     <sender> sends messages to other validators
 //     <validators> validate this chain
+//     <peers> are the peers this node is connected to
 // TODO: Make this function take less arguments
 func New(
 	stakingEnabled bool,
@@ -141,6 +155,8 @@ func New(
 	server *api.Server,
 	keystore *keystore.Keystore,
 	sharedMemory *atomic.SharedMemory,
+	features map[string]bool,
+	peers snow.PeerSet,
 ) Manager {
 	timeoutManager := timeout.Manager{}
 	timeoutManager.Initialize(requestTimeout)
@@ -167,8 +183,11 @@ func New(
 		server:          server,
 		keystore:        keystore,
 		sharedMemory:    sharedMemory,
+		features:        features,
+		peers:           peers,
 	}
 	m.Initialize()
+	m.bootstrap.initialize()
 	return m
 }
 
@@ -256,6 +275,8 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 		Keystore:            m.keystore.NewBlockchainKeyStore(chain.ID),
 		SharedMemory:        m.sharedMemory.NewBlockchainSharedMemory(chain.ID),
 		BCLookup:            m,
+		Features:            m.features,
+		Peers:               m.peers,
 	}
 	consensusParams := m.consensusParams
 	if alias, err := m.PrimaryAlias(ctx.ChainID); err == nil {
@@ -328,6 +349,7 @@ func (m *manager) AddRegistrant(r Registrant) { m.registrants = append(m.registr
 
 func (m *manager) unblockChains() {
 	m.unblocked = true
+	m.bootstrap.bootstrapped()
 	blocked := m.blockedChains
 	m.blockedChains = nil
 	for _, chain := range blocked {
@@ -496,9 +518,14 @@ func (m *manager) createSnowmanChain(
 				Alpha:      bootstrapWeight/2 + 1, // must be > 50%
 				Sender:     &sender,
 			},
-			Blocked:      blocked,
-			VM:           vm,
-			Bootstrapped: m.unblockChains,
+			Blocked: blocked,
+			VM:      vm,
+			Bootstrapped: func() {
+				if listener, ok := vm.(common.BootstrapListener); ok {
+					listener.Bootstrapped()
+				}
+				m.unblockChains()
+			},
 		},
 		Params:    consensusParams,
 		Consensus: &smcon.Topological{},
@@ -523,11 +550,34 @@ func (m *manager) createSnowmanChain(
 		},
 	}
 	m.awaiter.AwaitConnections(awaiting)
+
+	// The other chains are blocked until the Platform chain bootstraps, so
+	// this is the Platform chain
+	if !m.unblocked {
+		m.bootstrap.setRestart(func() {
+			ctx.Lock.Lock()
+			defer ctx.Lock.Unlock()
+
+			engine.Restart()
+		})
+	}
 	return nil
 }
 
+// MonitorBootstrap applies [policy] if the Platform chain hasn't bootstrapped
+// within [timeout]
+func (m *manager) MonitorBootstrap(timeout time.Duration, policy BootstrapTimeoutPolicy, exit func()) {
+	m.bootstrap.start(m.log, timeout, policy, exit)
+}
+
+// BootstrapStatus returns the progress of bootstrapping the Platform chain
+func (m *manager) BootstrapStatus() BootstrapStatus { return m.bootstrap.getStatus() }
+
 // Shutdown stops all the chains
-func (m *manager) Shutdown() { m.chainRouter.Shutdown() }
+func (m *manager) Shutdown() {
+	m.bootstrap.stop()
+	m.chainRouter.Shutdown()
+}
 
 // LookupVM returns the ID of the VM associated with an alias
 func (m *manager) LookupVM(alias string) (ids.ID, error) { return m.vmManager.Lookup(alias) }
@@ -1,6 +1,8 @@
 package chains
 
 import (
+	"time"
+
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/networking/router"
 )
@@ -33,5 +35,11 @@ func (mm MockManager) Aliases(ids.ID) []string { return nil }
 // Alias ...
 func (mm MockManager) Alias(ids.ID, string) error { return nil }
 
+// MonitorBootstrap ...
+func (mm MockManager) MonitorBootstrap(time.Duration, BootstrapTimeoutPolicy, func()) {}
+
+// BootstrapStatus ...
+func (mm MockManager) BootstrapStatus() BootstrapStatus { return BootstrapStatus{} }
+
 // Shutdown ...
 func (mm MockManager) Shutdown() {}
@@ -4,6 +4,7 @@
 package encdb
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ava-labs/gecko/database"
@@ -22,3 +23,40 @@ func TestInterface(t *testing.T) {
 		test(t, db)
 	}
 }
+
+func TestValuesEncrypted(t *testing.T) {
+	unencryptedDB := memdb.New()
+	db, err := New([]byte("password"), unencryptedDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := []byte("key")
+	value := []byte("a very secret value")
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := unencryptedDB.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, value) {
+		t.Fatal("value was stored in plaintext")
+	}
+
+	if got, err := db.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(got, value) {
+		t.Fatalf("Get returned %q, expected %q", got, value)
+	}
+
+	// The values can't be decrypted with a different password
+	wrongDB, err := New([]byte("wrong password"), unencryptedDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrongDB.Get(key); err == nil {
+		t.Fatal("Get should have failed with the wrong password")
+	}
+}
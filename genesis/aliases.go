@@ -18,6 +18,16 @@ import (
 
 // Aliases returns the default aliases based on the network ID
 func Aliases(networkID uint32) (map[string][]string, map[[32]byte][]string, map[[32]byte][]string, error) {
+	genesisBytes, err := Genesis(networkID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return AliasesFromBytes(genesisBytes)
+}
+
+// AliasesFromBytes returns the default aliases of the chains created by the
+// Platform chain genesis [genesisBytes]
+func AliasesFromBytes(genesisBytes []byte) (map[string][]string, map[[32]byte][]string, map[[32]byte][]string, error) {
 	generalAliases := map[string][]string{
 		"vm/" + platformvm.ID.String():  []string{"vm/platform"},
 		"vm/" + avm.ID.String():         []string{"vm/avm"},
@@ -42,11 +52,6 @@ func Aliases(networkID uint32) (map[string][]string, map[[32]byte][]string, map[
 		propertyfx.ID.Key():  []string{"propertyfx"},
 	}
 
-	genesisBytes, err := Genesis(networkID)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
 	genesis := &platformvm.Genesis{} // TODO let's not re-create genesis to do aliasing
 	if err := platformvm.Codec.Unmarshal(genesisBytes, genesis); err != nil {
 		return nil, nil, nil, err
@@ -259,8 +259,16 @@ func VMGenesis(networkID uint32, vmID ids.ID) (*platformvm.CreateChainTx, error)
 	if err != nil {
 		return nil, err
 	}
+	return VMGenesisFromBytes(genesisBytes, vmID)
+}
+
+// VMGenesisFromBytes returns the transaction in the Platform chain genesis
+// [genesisBytes] that creates the chain running the VM with ID [vmID]
+func VMGenesisFromBytes(genesisBytes []byte, vmID ids.ID) (*platformvm.CreateChainTx, error) {
 	genesis := platformvm.Genesis{}
-	platformvm.Codec.Unmarshal(genesisBytes, &genesis)
+	if err := platformvm.Codec.Unmarshal(genesisBytes, &genesis); err != nil {
+		return nil, err
+	}
 	if err := genesis.Initialize(); err != nil {
 		return nil, err
 	}
@@ -274,7 +282,17 @@ func VMGenesis(networkID uint32, vmID ids.ID) (*platformvm.CreateChainTx, error)
 
 // AVAAssetID ...
 func AVAAssetID(networkID uint32) (ids.ID, error) {
-	createAVM, err := VMGenesis(networkID, avm.ID)
+	genesisBytes, err := Genesis(networkID)
+	if err != nil {
+		return ids.ID{}, err
+	}
+	return AVAAssetIDFromBytes(genesisBytes)
+}
+
+// AVAAssetIDFromBytes returns the ID of the AVA asset created by the AVM chain
+// in the Platform chain genesis [genesisBytes]
+func AVAAssetIDFromBytes(genesisBytes []byte) (ids.ID, error) {
+	createAVM, err := VMGenesisFromBytes(genesisBytes, avm.ID)
 	if err != nil {
 		return ids.ID{}, err
 	}
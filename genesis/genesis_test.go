@@ -4,6 +4,7 @@
 package genesis
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/ava-labs/gecko/ids"
@@ -188,3 +189,55 @@ func TestAVAAssetID(t *testing.T) {
 		}
 	}
 }
+
+func TestFromBytes(t *testing.T) {
+	genesisBytes, err := Genesis(CascadeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTx, err := VMGenesis(CascadeID, avm.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err := VMGenesisFromBytes(genesisBytes, avm.ID); err != nil {
+		t.Fatal(err)
+	} else if !tx.ID().Equals(expectedTx.ID()) {
+		t.Fatalf("AVM genesis should have been %s but was %s", expectedTx.ID(), tx.ID())
+	}
+
+	expectedAssetID, err := AVAAssetID(CascadeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assetID, err := AVAAssetIDFromBytes(genesisBytes); err != nil {
+		t.Fatal(err)
+	} else if !assetID.Equals(expectedAssetID) {
+		t.Fatalf("AVA assetID should have been %s but was %s", expectedAssetID, assetID)
+	}
+
+	_, expectedChainAliases, _, err := Aliases(CascadeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, chainAliases, _, err := AliasesFromBytes(genesisBytes); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(chainAliases, expectedChainAliases) {
+		t.Fatalf("chain aliases should have been %v but were %v", expectedChainAliases, chainAliases)
+	}
+
+	// A different genesis gives the chains different IDs
+	localGenesisBytes, err := Genesis(LocalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, chainAliases, _, err := AliasesFromBytes(localGenesisBytes); err != nil {
+		t.Fatal(err)
+	} else if reflect.DeepEqual(chainAliases, expectedChainAliases) {
+		t.Fatal("chain aliases should have been from the given genesis")
+	}
+
+	if _, err := VMGenesisFromBytes([]byte{1, 2, 3}, avm.ID); err == nil {
+		t.Fatal("should have failed to parse a malformed genesis")
+	}
+}
@@ -0,0 +1,88 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// edgeLen is the number of bytes of a packed edge
+const edgeLen = 2 * hashing.AddrLen
+
+var (
+	errZeroEdge     = errors.New("edge has an uninitialized id")
+	errTooManyEdges = errors.New("number of edges exceeds the remaining bytes")
+)
+
+// Edge is a directed edge between two ids
+type Edge struct {
+	From, To ShortID
+}
+
+// Less returns true if this edge is sorted before [other], by From then To
+func (e Edge) Less(other Edge) bool {
+	if cmp := bytes.Compare(e.From.Bytes(), other.From.Bytes()); cmp != 0 {
+		return cmp < 0
+	}
+	return bytes.Compare(e.To.Bytes(), other.To.Bytes()) < 0
+}
+
+type sortEdgeData []Edge
+
+func (edges sortEdgeData) Less(i, j int) bool { return edges[i].Less(edges[j]) }
+func (edges sortEdgeData) Len() int           { return len(edges) }
+func (edges sortEdgeData) Swap(i, j int)      { edges[j], edges[i] = edges[i], edges[j] }
+
+// SortEdges sorts the edges by From then To
+func SortEdges(edges []Edge) { sort.Sort(sortEdgeData(edges)) }
+
+// PackEdges appends the number of edges and then each edge's From and To ids
+// to the packer. If [canonical] is true, the edges are packed in sorted order,
+// so that the same set of edges always packs to the same bytes. [edges] isn't
+// modified.
+func PackEdges(p *wrappers.Packer, edges []Edge, canonical bool) {
+	for _, edge := range edges {
+		if edge.From.IsZero() || edge.To.IsZero() {
+			p.Add(errZeroEdge)
+			return
+		}
+	}
+	if canonical {
+		edges = append([]Edge(nil), edges...)
+		SortEdges(edges)
+	}
+
+	p.PackInt(uint32(len(edges)))
+	for _, edge := range edges {
+		p.PackFixedBytes(edge.From.Bytes())
+		p.PackFixedBytes(edge.To.Bytes())
+	}
+}
+
+// UnpackEdges unpacks the edges packed by PackEdges, in the order they were
+// packed
+func UnpackEdges(p *wrappers.Packer) []Edge {
+	numEdges := p.UnpackInt()
+	p.CheckSpace(0)
+	// Divide rather than multiply, as the size could overflow an int
+	if !p.Errored() && uint64(numEdges) > uint64(p.Remaining()/edgeLen) {
+		p.Add(errTooManyEdges)
+	}
+	if p.Errored() {
+		return nil
+	}
+
+	edges := make([]Edge, numEdges)
+	for i := range edges {
+		from, _ := ToShortID(p.UnpackFixedBytes(hashing.AddrLen))
+		to, _ := ToShortID(p.UnpackFixedBytes(hashing.AddrLen))
+		edges[i] = Edge{From: from, To: to}
+	}
+	return edges
+}
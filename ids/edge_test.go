@@ -0,0 +1,103 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+func TestPackEdges(t *testing.T) {
+	a := NewShortID([20]byte{1})
+	b := NewShortID([20]byte{2})
+	c := NewShortID([20]byte{3})
+
+	edges := []Edge{
+		{From: b, To: a},
+		{From: a, To: c},
+		{From: a, To: b},
+	}
+	reordered := []Edge{edges[2], edges[0], edges[1]}
+
+	p1 := wrappers.Packer{MaxSize: 1024}
+	PackEdges(&p1, edges, true)
+	p2 := wrappers.Packer{MaxSize: 1024}
+	PackEdges(&p2, reordered, true)
+	if p1.Errored() || p2.Errored() {
+		t.Fatalf("PackEdges failed: %v, %v", p1.Err, p2.Err)
+	}
+	if !bytes.Equal(p1.Bytes, p2.Bytes) {
+		t.Fatal("the same edges should have packed to the same bytes regardless of their order")
+	}
+	if !edges[0].From.Equals(b) {
+		t.Fatal("PackEdges shouldn't have modified the edges")
+	}
+
+	unpacker := wrappers.Packer{Bytes: p1.Bytes}
+	unpacked := UnpackEdges(&unpacker)
+	if unpacker.Errored() {
+		t.Fatal(unpacker.Err)
+	}
+	expected := []Edge{
+		{From: a, To: b},
+		{From: a, To: c},
+		{From: b, To: a},
+	}
+	if len(unpacked) != len(expected) {
+		t.Fatalf("UnpackEdges returned %d edges, expected %d", len(unpacked), len(expected))
+	}
+	for i, edge := range unpacked {
+		if !edge.From.Equals(expected[i].From) || !edge.To.Equals(expected[i].To) {
+			t.Fatalf("UnpackEdges returned %v at index %d, expected %v", edge, i, expected[i])
+		}
+	}
+}
+
+func TestPackEdgesNotCanonical(t *testing.T) {
+	a := NewShortID([20]byte{1})
+	b := NewShortID([20]byte{2})
+	edges := []Edge{{From: b, To: a}, {From: a, To: b}}
+
+	p := wrappers.Packer{MaxSize: 1024}
+	PackEdges(&p, edges, false)
+	unpacker := wrappers.Packer{Bytes: p.Bytes}
+	unpacked := UnpackEdges(&unpacker)
+	if unpacker.Errored() {
+		t.Fatal(unpacker.Err)
+	}
+	if len(unpacked) != 2 || !unpacked[0].From.Equals(b) || !unpacked[1].From.Equals(a) {
+		t.Fatalf("UnpackEdges should have kept the packed order but returned %v", unpacked)
+	}
+}
+
+func TestPackEdgesZeroID(t *testing.T) {
+	p := wrappers.Packer{MaxSize: 1024}
+	PackEdges(&p, []Edge{{From: NewShortID([20]byte{1})}}, true)
+	if p.Err != errZeroEdge {
+		t.Fatalf("PackEdges should have failed with %s but got %v", errZeroEdge, p.Err)
+	}
+}
+
+func TestUnpackEdgesTruncated(t *testing.T) {
+	p := wrappers.Packer{MaxSize: 1024}
+	PackEdges(&p, []Edge{{From: NewShortID([20]byte{1}), To: NewShortID([20]byte{2})}}, true)
+
+	unpacker := wrappers.Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if edges := UnpackEdges(&unpacker); !unpacker.Errored() || edges != nil {
+		t.Fatal("UnpackEdges should have failed on truncated edges")
+	}
+}
+
+func TestUnpackEdgesTooMany(t *testing.T) {
+	p := wrappers.Packer{MaxSize: 1024}
+	p.PackInt(math.MaxUint32)
+	p.PackFixedBytes(make([]byte, edgeLen))
+
+	if edges := UnpackEdges(&wrappers.Packer{Bytes: p.Bytes}); edges != nil {
+		t.Fatalf("UnpackEdges should have failed but returned %d edges", len(edges))
+	}
+}
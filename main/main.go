@@ -4,24 +4,80 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path"
 
+	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/node"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/logging"
-	"github.com/ava-labs/go-ethereum/p2p/nat"
 )
 
 // main is the primary entry point to Ava. This can either create a CLI to an
 //     existing node or create a new node.
 func main() {
+	// A self-test exits with a non-zero code unless it passes. The exit code is
+	// checked after every other deferred call has run.
+	exitCode := 0
+	if Config.SelfTest {
+		exitCode = 1
+	}
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// Err is set based on the CLI arguments
 	if Err != nil {
 		fmt.Printf("parsing parameters returned with error %s\n", Err)
 		return
 	}
 
+	if PrintConfig {
+		defer Config.DB.Close()
+
+		configJSON, err := Config.RedactedJSON()
+		if err != nil {
+			fmt.Printf("serializing config failed with: %s\n", err)
+			exitCode = 1
+			return
+		}
+		fmt.Println(string(configJSON))
+		return
+	}
+
+	if RotateStakingKey {
+		defer Config.DB.Close()
+
+		nodeID, err := node.StageStakingKey(Config.StakingKeyFile, Config.StakingCertFile)
+		if err != nil {
+			fmt.Printf("rotating staking key failed with: %s\n", err)
+			exitCode = 1
+			return
+		}
+		fmt.Printf("new staking key written to %s%s and certificate to %s%s\n", Config.StakingKeyFile, node.StagedKeySuffix, Config.StakingCertFile, node.StagedKeySuffix)
+		fmt.Printf("with the new key, this node's ID will be %s\n", nodeID)
+		fmt.Println("to use the new key, stop the node and run it once with --staking-tls-adopt")
+		return
+	}
+
+	if AdoptStakingKey {
+		defer Config.DB.Close()
+
+		nodeID, err := node.AdoptStakingKey(Config.StakingKeyFile, Config.StakingCertFile)
+		if err != nil {
+			fmt.Printf("adopting staking key failed with: %s\n", err)
+			exitCode = 1
+			return
+		}
+		fmt.Printf("staking key replaced. The old key and certificate were moved to %s%s and %s%s\n", Config.StakingKeyFile, node.BackupKeySuffix, Config.StakingCertFile, node.BackupKeySuffix)
+		fmt.Printf("this node's ID is now %s\n", nodeID)
+		return
+	}
+
 	config := Config.LoggingConfig
 	config.Directory = path.Join(config.Directory, "node")
 	factory := logging.NewFactory(config)
@@ -40,6 +96,13 @@ func main() {
 	defer log.StopOnPanic()
 	defer Config.DB.Close()
 
+	// SIGINT and SIGTERM cancel [shutdownCtx], which makes the node stop
+	// dispatching. The deferred calls then shut the node down before the
+	// database is closed.
+	shutdownCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer notifySignals(shutdownCtx, log, cancel)()
+
 	// Track if sybil control is enforced
 	if !Config.EnableStaking {
 		log.Warn("Staking and p2p encryption are disabled. Packet spoofing is possible.")
@@ -56,31 +119,50 @@ func main() {
 		return
 	}
 
+	if err := Config.Valid(); err != nil {
+		log.Fatal("node configuration is invalid: %s", err)
+		return
+	}
+
 	// Track if assertions should be executed
 	if Config.LoggingConfig.Assertions {
 		log.Warn("assertions are enabled. This may slow down execution")
 	}
 
+	if Config.SelfTest {
+		log.Info("running self-test")
+		if err := node.MainNode.SelfTest(&Config, log, factory); err != nil {
+			log.Fatal("self-test failed: %s", err)
+			return
+		}
+		log.Info("self-test passed")
+		exitCode = 0
+		return
+	}
+
 	natChan := make(chan struct{})
 	defer close(natChan)
 
-	go nat.Map(
-		/*nat=*/ Config.Nat,
-		/*closeChannel=*/ natChan,
-		/*protocol=*/ "TCP",
-		/*internetPort=*/ int(Config.StakingIP.Port),
-		/*localPort=*/ int(Config.StakingIP.Port),
-		/*name=*/ "Gecko Staking Server",
-	)
-
-	go nat.Map(
-		/*nat=*/ Config.Nat,
-		/*closeChannel=*/ natChan,
-		/*protocol=*/ "TCP",
-		/*internetPort=*/ int(Config.HTTPPort),
-		/*localPort=*/ int(Config.HTTPPort),
-		/*name=*/ "Gecko HTTP Server",
-	)
+	// Mapping ports can take a while, so it doesn't hold up startup. If the
+	// node is only reachable through the NAT, it shuts down if no port could
+	// be mapped.
+	mappings := []node.PortMapping{
+		{Port: Config.StakingIP.Port, Name: "Gecko Staking Server"},
+		{Port: Config.HTTPPort, Name: "Gecko HTTP Server"},
+	}
+	natFailed := make(chan struct{})
+	go func() {
+		err := node.MapPorts(Config.Nat, mappings, natChan, log)
+		switch {
+		case err == nil:
+		case Config.NatTraversal && Config.EnableStaking:
+			log.Fatal("mapping ports failed: %s", err)
+			close(natFailed)
+			cancel()
+		default:
+			log.Warn("mapping ports failed: %s. This node may not be reachable", err)
+		}
+	}()
 
 	log.Debug("initializing node state")
 	// MainNode is a global variable in the node.go file
@@ -95,8 +177,17 @@ func main() {
 		return
 	}
 
-	defer node.MainNode.Shutdown()
+	defer node.MainNode.Drain()
 
 	log.Debug("Dispatching node handlers")
-	node.MainNode.Dispatch()
+	node.MainNode.DispatchUntil(shutdownCtx)
+
+	if node.MainNode.BootstrapStatus().State == chains.BootstrapFailed {
+		exitCode = 1
+	}
+	select {
+	case <-natFailed:
+		exitCode = 1
+	default:
+	}
 }
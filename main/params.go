@@ -11,9 +11,11 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/ava-labs/go-ethereum/p2p/nat"
 
+	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/database/leveldb"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/genesis"
@@ -29,8 +31,11 @@ import (
 
 // Results of parsing the CLI
 var (
-	Config = node.Config{}
-	Err    error
+	Config           = node.Config{}
+	PrintConfig      bool
+	RotateStakingKey bool
+	AdoptStakingKey  bool
+	Err              error
 )
 
 // GetIPs returns the default IPs for each network
@@ -73,22 +78,41 @@ func init() {
 
 	// IP:
 	consensusIP := fs.String("public-ip", "", "Public IP of this node")
+	natTraversal := fs.Bool("nat", false, "Require mapping the staking and HTTP ports through the NAT gateway. Always required if --public-ip isn't set")
 
 	// HTTP Server:
 	httpPort := fs.Uint("http-port", 9650, "Port of the HTTP server")
 	fs.BoolVar(&Config.EnableHTTPS, "http-tls-enabled", false, "Upgrade the HTTP server to HTTPs")
 	fs.StringVar(&Config.HTTPSKeyFile, "http-tls-key-file", "", "TLS private key file for the HTTPs server")
 	fs.StringVar(&Config.HTTPSCertFile, "http-tls-cert-file", "", "TLS certificate file for the HTTPs server")
+	fs.DurationVar(&Config.APIRequestTimeout, "api-request-timeout", 30*time.Second, "Maximum duration of an API request. If 0, requests only time out if the client sets the Request-Timeout header")
+	fs.DurationVar(&Config.DrainTimeout, "http-drain-timeout", 10*time.Second, "Maximum time to wait for in-flight API requests when shutting down")
+	apiRequestLogLevel := fs.String("api-request-log-level", "off", "The log level API requests are logged at. If off, API requests aren't logged")
+	fs.BoolVar(&Config.APIRequestLogJSON, "api-request-log-json", false, "Log API requests as JSON objects")
+	fs.DurationVar(&Config.SlowQueryThreshold, "api-slow-query-threshold", 0, "API requests that take at least this long are logged at the warn level, with their method and duration. If 0, slow requests aren't logged")
+
+	// Genesis:
+	fs.StringVar(&Config.GenesisURL, "genesis-url", "", "https URL to fetch the Platform chain's genesis data from. If empty, the network's genesis data is used")
+	genesisHash := fs.String("genesis-hash", "", "SHA-256 hash, in cb58, the genesis data fetched from --genesis-url must have")
 
 	// Bootstrapping:
 	bootstrapIPs := fs.String("bootstrap-ips", "default", "Comma separated list of bootstrap peer ips to connect to. Example: 127.0.0.1:9630,127.0.0.1:9631")
 	bootstrapIDs := fs.String("bootstrap-ids", "default", "Comma separated list of bootstrap peer ids to connect to. Example: JR4dVmy6ffUGAKCBDkyCbeZbyHQBeDsET,8CrVPQZ4VSqgL8zTdvL14G8HqAfrBr4z")
+	fs.DurationVar(&Config.BeaconReconnectBackoff, "bootstrap-reconnect-backoff", time.Second, "Initial delay between attempts to reconnect to the bootstrap peers when not connected to any of them. If 0, they aren't reconnected to")
+	fs.DurationVar(&Config.BeaconReconnectMaxBackoff, "bootstrap-reconnect-max-backoff", time.Minute, "Maximum delay between attempts to reconnect to the bootstrap peers")
+	fs.DurationVar(&Config.BootstrapTimeout, "bootstrap-timeout", 0, "Maximum duration of bootstrapping the Platform chain before the bootstrap timeout policy is applied. If 0, bootstrapping never times out")
+	bootstrapTimeoutPolicy := fs.String("bootstrap-timeout-policy", "degrade", "What to do if bootstrapping times out. One of: retry (restart bootstrapping from scratch and wait another timeout), degrade (keep running in the degraded state), exit (shut down the node)")
 
 	// Staking:
 	consensusPort := fs.Uint("staking-port", 9651, "Port of the consensus server")
 	fs.BoolVar(&Config.EnableStaking, "staking-tls-enabled", true, "Require TLS to authenticate staking connections")
 	fs.StringVar(&Config.StakingKeyFile, "staking-tls-key-file", "keys/staker.key", "TLS private key file for staking connections")
 	fs.StringVar(&Config.StakingCertFile, "staking-tls-cert-file", "keys/staker.crt", "TLS certificate file for staking connections")
+	fs.BoolVar(&RotateStakingKey, "staking-tls-rotate", false, "If true, generates a new staking key and certificate next to the current ones, with a .new suffix, and exits")
+	fs.BoolVar(&AdoptStakingKey, "staking-tls-adopt", false, "If true, replaces the staking key and certificate with the ones generated by --staking-tls-rotate, keeping the old ones with a .old suffix, and exits")
+	fs.DurationVar(&Config.HandshakeTimeout, "handshake-timeout", 10*time.Second, "Maximum duration of the handshake with a new peer before the connection is dropped. If 0, the handshake never times out")
+	fs.StringVar(&Config.MinPeerVersion, "min-peer-version", "", "Minimum version peers must run, such as avalanche/0.0.1. If empty, peers running any version are accepted")
+	fs.Uint64Var(&Config.PeerBandwidth, "peer-bandwidth", 0, "Bytes per second of consensus messages allotted to each peer in each direction. Messages over the allotment are delayed, and dropped if too many are already delayed. If 0, peers aren't throttled")
 
 	// Logging:
 	logsDir := fs.String("log-dir", "", "Logging directory for Ava")
@@ -108,11 +132,49 @@ func init() {
 	fs.BoolVar(&Config.KeystoreAPIEnabled, "api-keystore-enabled", true, "If true, this node exposes the Keystore API")
 	fs.BoolVar(&Config.MetricsAPIEnabled, "api-metrics-enabled", true, "If true, this node exposes the Metrics API")
 	fs.BoolVar(&Config.IPCEnabled, "api-ipcs-enabled", false, "If true, IPCs can be opened")
+	fs.BoolVar(&Config.HealthAPIEnabled, "api-health-enabled", true, "If true, this node exposes the Health API")
+
+	// Health:
+	fs.IntVar(&Config.HealthThresholds.MinPeers, "health-min-peers", 0, "Minimum number of connected peers of a healthy node. If 0, the number of peers isn't checked")
+	fs.DurationVar(&Config.HealthThresholds.MaxTimeSinceAccept, "health-max-time-since-accept", 0, "Maximum time since a block or vertex was last accepted on any chain for the node to be healthy. If 0, it isn't checked")
 
 	// Throughput Server
 	throughputPort := fs.Uint("xput-server-port", 9652, "Port of the deprecated throughput test server")
 	fs.BoolVar(&Config.ThroughputServerEnabled, "xput-server-enabled", false, "If true, throughput test server is created")
 
+	// Config dump:
+	fs.BoolVar(&PrintConfig, "print-config", false, "If true, prints the effective config as JSON, with secrets redacted, and exits")
+
+	// Feature flags:
+	features := fs.String("features", "", "Comma separated list of feature flags to enable. Example: strict-timestamps")
+
+	// Writes:
+	fs.IntVar(&Config.MinPeersForWrites, "min-peers-for-writes", 0, "Number of peers the node must be connected to for the timestamp VM to accept proposals")
+	fs.StringVar(&Config.TimestampDBEncryptionKey, "timestamp-db-encryption-key", "", "Secret used to encrypt the timestamp VM's database values at rest. If empty, they aren't encrypted")
+	fs.IntVar(&Config.TimestampMaxMempoolBytes, "timestamp-max-mempool-bytes", 0, "Maximum total size of the data in the timestamp VM's mempool. The oldest data is evicted beyond it. If 0, the mempool isn't bounded")
+	fs.Float64Var(&Config.TimestampProposeRate, "timestamp-propose-rate", 0, "Average number of blocks per second that may be proposed through the timestamp VM's API. If 0, proposals aren't rate limited")
+	fs.Float64Var(&Config.TimestampProposeBurst, "timestamp-propose-burst", 1, "Maximum number of blocks that may be proposed through the timestamp VM's API at once when proposals are rate limited")
+	fs.Uint64Var(&Config.TimestampMaxReorgDepth, "timestamp-max-reorg-depth", 0, "Maximum number of accepted blocks that a timestamp VM block may replace. If 0, the depth isn't limited")
+	fs.StringVar(&Config.TimestampCodecName, "timestamp-codec", "", "Name of the codec the timestamp VM serializes blocks with. If empty, the default codec")
+	fs.IntVar(&Config.TimestampMaxMempoolSize, "timestamp-max-mempool-size", 0, "Maximum number of pieces of data in the timestamp VM's mempool. Proposals are rejected while it's full. If 0, 1024")
+	fs.BoolVar(&Config.TimestampRequeueRejected, "timestamp-requeue-rejected", false, "If true, the data of rejected timestamp VM blocks is re-added to the mempool instead of being dropped")
+	fs.DurationVar(&Config.TimestampProposeTimeout, "timestamp-propose-timeout", 0, "How long a synchronous proposal to the timestamp VM waits for its block to be accepted. If 0, 30s")
+	fs.DurationVar(&Config.TimestampMaxFutureDrift, "timestamp-max-future-drift", 0, "How far ahead of local time a timestamp VM block's timestamp may be. If 0, 10s")
+
+	// Storage:
+	fs.IntVar(&Config.TimestampCompactionThreshold, "timestamp-compaction-threshold", 0, "Number of blocks the timestamp VM prunes before compacting its database. If 0, it's never compacted")
+
+	// Reads:
+	fs.BoolVar(&Config.TimestampEnableSearch, "timestamp-enable-search", false, "If true, the timestamp VM indexes the data of accepted blocks so that it can be searched")
+	fs.IntVar(&Config.TimestampMaxPageSize, "timestamp-max-page-size", 0, "Maximum number of items returned by a range query through the timestamp VM's API. If 0, 1024")
+
+	// Snapshots:
+	fs.DurationVar(&Config.TimestampSnapshotInterval, "timestamp-snapshot-interval", 0, "How often the timestamp VM exports a snapshot of its chain. If 0, snapshots aren't exported")
+	fs.StringVar(&Config.TimestampSnapshotDir, "timestamp-snapshot-dir", "", "Directory the timestamp VM exports snapshots of its chain to. The most recent snapshots are kept")
+
+	// Self-test:
+	fs.BoolVar(&Config.SelfTest, "selftest", false, "If true, initializes the node, shuts it down and exits. Exits with a non-zero code on failure")
+
 	ferr := fs.Parse(os.Args[1:])
 
 	if ferr == flag.ErrHelp {
@@ -142,6 +204,7 @@ func init() {
 	}
 
 	Config.Nat = nat.Any()
+	Config.NatTraversal = *natTraversal || *consensusIP == ""
 
 	var ip net.IP
 	// If public IP is not specified, get it using shell command dig
@@ -211,8 +274,19 @@ func init() {
 		}
 	}
 
+	Config.BootstrapTimeoutPolicy, err = chains.ToBootstrapTimeoutPolicy(*bootstrapTimeoutPolicy)
+	errs.Add(err)
+
+	// Genesis:
+	if *genesisHash != "" {
+		Config.GenesisHash, err = ids.FromString(*genesisHash)
+		errs.Add(err)
+	}
+
 	// HTTP:
 	Config.HTTPPort = uint16(*httpPort)
+	Config.APIRequestLogLevel, err = logging.ToLevel(*apiRequestLogLevel)
+	errs.Add(err)
 
 	// Logging:
 	if *logsDir != "" {
@@ -234,6 +308,14 @@ func init() {
 	// Throughput:
 	Config.ThroughputPort = uint16(*throughputPort)
 
+	// Feature flags:
+	Config.Features = make(map[string]bool)
+	for _, feature := range strings.Split(*features, ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			Config.Features[feature] = true
+		}
+	}
+
 	// Router used for consensus
 	Config.ConsensusRouter = &router.ChainRouter{}
 }
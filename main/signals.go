@@ -0,0 +1,43 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// notifySignals calls [shutdown] when the process receives SIGINT or SIGTERM,
+// until [ctx] is done. Returns a function that stops relaying the signals.
+func notifySignals(ctx context.Context, log logging.Logger, shutdown func()) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go handleSignals(ctx, signals, log, shutdown)
+	return func() { signal.Stop(signals) }
+}
+
+// handleSignals calls [shutdown] when the first signal is received on
+// [signals]. Later signals are logged and ignored, so that a node that's
+// already shutting down isn't interrupted. Returns once [ctx] is done.
+func handleSignals(ctx context.Context, signals <-chan os.Signal, log logging.Logger, shutdown func()) {
+	shuttingDown := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			if shuttingDown {
+				log.Info("received %s while already shutting down", sig)
+				continue
+			}
+			log.Info("received %s, shutting down", sig)
+			shuttingDown = true
+			shutdown()
+		}
+	}
+}
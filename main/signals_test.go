@@ -0,0 +1,103 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/genesis"
+	"github.com/ava-labs/gecko/node"
+	"github.com/ava-labs/gecko/snow/networking/router"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestHandleSignalsShutsDownOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal)
+	shutdowns := make(chan struct{}, 2)
+	returned := make(chan struct{})
+	go func() {
+		handleSignals(ctx, signals, logging.NoLog{}, func() { shutdowns <- struct{}{} })
+		close(returned)
+	}()
+
+	signals <- syscall.SIGTERM
+	signals <- syscall.SIGINT
+	cancel()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("handleSignals should have returned once the context was done")
+	}
+	if len(shutdowns) != 1 {
+		t.Fatalf("shutdown should have been called once but was called %d times", len(shutdowns))
+	}
+}
+
+func TestHandleSignalsNoSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	shutdowns := 0
+	handleSignals(ctx, make(chan os.Signal), logging.NoLog{}, func() { shutdowns++ })
+	if shutdowns != 0 {
+		t.Fatalf("shutdown shouldn't have been called without a signal but was called %d times", shutdowns)
+	}
+}
+
+// The node's networking mustn't take over the signals relayed to
+// handleSignals
+func TestSignalsAfterNodeInitialize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shutdowns := make(chan struct{}, 1)
+	defer notifySignals(ctx, logging.NoLog{}, func() { shutdowns <- struct{}{} })()
+
+	db := memdb.New()
+	defer db.Close()
+	config := &node.Config{
+		NetworkID:    genesis.LocalID,
+		EnableCrypto: true,
+		DB:           db,
+		StakingIP: utils.IPDesc{
+			IP:   net.IPv6loopback,
+			Port: 9651,
+		},
+		HTTPPort:        9650,
+		ConsensusRouter: &router.ChainRouter{},
+		SelfTest:        true,
+	}
+	config.ConsensusParams.K = 1
+	config.ConsensusParams.Alpha = 1
+	config.ConsensusParams.BetaVirtuous = 1
+	config.ConsensusParams.BetaRogue = 2
+	config.ConsensusParams.Parents = 2
+	config.ConsensusParams.BatchSize = 1
+	config.ConsensusParams.ConcurrentRepolls = 1
+
+	n := node.Node{}
+	if err := n.Initialize(config, logging.NoLog{}, logging.NoFactory{}); err != nil {
+		t.Fatal(err)
+	}
+	defer n.Shutdown()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-shutdowns:
+	case <-time.After(time.Second):
+		t.Fatal("SIGTERM should have reached handleSignals")
+	}
+}
@@ -0,0 +1,133 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	// bandwidthPruneInterval is how often peers whose allocation is full are
+	// forgotten
+	bandwidthPruneInterval = time.Minute
+
+	// maxThrottledMessages is the number of a peer's messages that can wait for
+	// its allocation at once
+	maxThrottledMessages = 256
+)
+
+// bandwidthThrottler allots each peer a number of bytes per second with a token
+// bucket. Bytes over a peer's allocation are delayed until the peer's
+// allocation allows them, so that a greedy peer can't monopolize the node's
+// bandwidth. Messages are dropped once [maxThrottledMessages] of the peer's
+// messages are waiting, so that a greedy peer can't exhaust the node's memory.
+type bandwidthThrottler struct {
+	lock sync.Mutex
+	// Bytes per second allotted to each peer. If 0, peers aren't throttled.
+	rate float64
+	// Maximum number of bytes a peer can transfer at once without waiting
+	burst float64
+
+	peers     map[[20]byte]*peerBandwidth
+	lastPrune time.Time
+	clock     timer.Clock
+}
+
+type peerBandwidth struct {
+	tokens     float64
+	lastRefill time.Time
+	// Number of the peer's messages waiting for its allocation
+	waiting int
+}
+
+// Initialize the throttler to allot [bytesPerSecond] to each peer
+func (t *bandwidthThrottler) Initialize(bytesPerSecond uint64) {
+	t.rate = float64(bytesPerSecond)
+	t.burst = t.rate
+	t.peers = make(map[[20]byte]*peerBandwidth)
+	t.lastPrune = t.clock.Time()
+}
+
+// reserve [size] bytes of [peer]'s allocation and return how long to wait
+// before transferring them. If the bytes must wait, [release] must be called
+// once they're transferred. Returns false, without reserving anything, if too
+// many of [peer]'s messages are already waiting.
+func (t *bandwidthThrottler) reserve(peer ids.ShortID, size int) (time.Duration, bool) {
+	if t.rate == 0 {
+		return 0, true
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Time()
+	t.prune(now)
+
+	key := peer.Key()
+	bandwidth, exists := t.peers[key]
+	if !exists {
+		bandwidth = &peerBandwidth{
+			tokens:     t.burst,
+			lastRefill: now,
+		}
+		t.peers[key] = bandwidth
+	}
+	t.refill(bandwidth, now)
+
+	// The tokens may go negative, so that bytes reserved while waiting are
+	// delayed until after the bytes already waiting
+	tokens := bandwidth.tokens - float64(size)
+	if tokens >= 0 {
+		bandwidth.tokens = tokens
+		return 0, true
+	}
+	if bandwidth.waiting >= maxThrottledMessages {
+		return 0, false
+	}
+	bandwidth.tokens = tokens
+	bandwidth.waiting++
+	return time.Duration(-tokens / t.rate * float64(time.Second)), true
+}
+
+// release a message from [peer] that waited for its allocation
+func (t *bandwidthThrottler) release(peer ids.ShortID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if bandwidth, exists := t.peers[peer.Key()]; exists && bandwidth.waiting > 0 {
+		bandwidth.waiting--
+	}
+}
+
+// refill [bandwidth] with the tokens it has earned since it was last refilled
+func (t *bandwidthThrottler) refill(bandwidth *peerBandwidth, now time.Time) {
+	if elapsed := now.Sub(bandwidth.lastRefill); elapsed > 0 {
+		bandwidth.tokens += elapsed.Seconds() * t.rate
+		if bandwidth.tokens > t.burst {
+			bandwidth.tokens = t.burst
+		}
+		bandwidth.lastRefill = now
+	}
+}
+
+// prune forgets the peers whose allocation is full and that have no messages
+// waiting, as they're throttled the same as peers that haven't transferred
+// anything. Assumes [t.lock] is held.
+func (t *bandwidthThrottler) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < bandwidthPruneInterval {
+		return
+	}
+	t.lastPrune = now
+
+	for key, bandwidth := range t.peers {
+		t.refill(bandwidth, now)
+		if bandwidth.tokens >= t.burst && bandwidth.waiting == 0 {
+			delete(t.peers, key)
+		}
+	}
+}
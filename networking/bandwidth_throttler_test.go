@@ -0,0 +1,115 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestBandwidthThrottlerThrottlesGreedyPeer(t *testing.T) {
+	throttler := bandwidthThrottler{}
+	throttler.clock.Set(time.Unix(1000, 0))
+	throttler.Initialize(1000)
+
+	greedy := ids.NewShortID([20]byte{1})
+	other := ids.NewShortID([20]byte{2})
+
+	// The greedy peer's first second of bytes isn't throttled
+	if wait, _ := throttler.reserve(greedy, 1000); wait != 0 {
+		t.Fatalf("bytes within the allocation shouldn't have been throttled but waited %s", wait)
+	}
+	// Bytes over the allocation are delayed, and later bytes wait behind them
+	if wait, _ := throttler.reserve(greedy, 500); wait != 500*time.Millisecond {
+		t.Fatalf("bytes over the allocation should have waited 500ms but waited %s", wait)
+	}
+	if wait, _ := throttler.reserve(greedy, 1000); wait != 1500*time.Millisecond {
+		t.Fatalf("bytes over the allocation should have waited 1.5s but waited %s", wait)
+	}
+
+	// Other peers proceed unaffected
+	if wait, _ := throttler.reserve(other, 1000); wait != 0 {
+		t.Fatalf("another peer shouldn't have been throttled but waited %s", wait)
+	}
+
+	// The greedy peer's allocation refills over time
+	throttler.clock.Set(throttler.clock.Time().Add(2 * time.Second))
+	if wait, _ := throttler.reserve(greedy, 500); wait != 0 {
+		t.Fatalf("bytes within the refilled allocation shouldn't have been throttled but waited %s", wait)
+	}
+}
+
+func TestBandwidthThrottlerUnlimited(t *testing.T) {
+	throttler := bandwidthThrottler{}
+	throttler.Initialize(0)
+
+	peer := ids.NewShortID([20]byte{1})
+	for i := 0; i < 10; i++ {
+		if wait, _ := throttler.reserve(peer, 1<<20); wait != 0 {
+			t.Fatalf("peers shouldn't be throttled without a rate but waited %s", wait)
+		}
+	}
+}
+
+func TestBandwidthThrottlerPrune(t *testing.T) {
+	throttler := bandwidthThrottler{}
+	throttler.clock.Set(time.Unix(1000, 0))
+	throttler.Initialize(1000)
+
+	idle := ids.NewShortID([20]byte{1})
+	busy := ids.NewShortID([20]byte{2})
+	throttler.reserve(idle, 1000)
+	throttler.reserve(busy, 1000)
+
+	throttler.clock.Set(throttler.clock.Time().Add(bandwidthPruneInterval))
+	throttler.reserve(busy, 1000)
+	if _, exists := throttler.peers[idle.Key()]; exists {
+		t.Fatal("a peer with a full allocation should have been forgotten")
+	}
+	if _, exists := throttler.peers[busy.Key()]; !exists {
+		t.Fatal("a peer that's using its allocation shouldn't have been forgotten")
+	}
+}
+
+func TestBandwidthThrottlerDropsPastMaxWaiting(t *testing.T) {
+	throttler := bandwidthThrottler{}
+	throttler.clock.Set(time.Unix(1000, 0))
+	throttler.Initialize(1000)
+
+	greedy := ids.NewShortID([20]byte{1})
+	other := ids.NewShortID([20]byte{2})
+	throttler.reserve(greedy, 1000)
+	for i := 0; i < maxThrottledMessages; i++ {
+		if wait, ok := throttler.reserve(greedy, 10); !ok || wait == 0 {
+			t.Fatalf("message %d should have waited but returned (%s, %t)", i, wait, ok)
+		}
+	}
+
+	// Messages past the limit are dropped without using the allocation
+	expectedTokens := throttler.peers[greedy.Key()].tokens
+	if _, ok := throttler.reserve(greedy, 10); ok {
+		t.Fatal("a message past the limit should have been dropped")
+	}
+	if tokens := throttler.peers[greedy.Key()].tokens; tokens != expectedTokens {
+		t.Fatalf("a dropped message shouldn't have used the allocation, but the tokens went from %f to %f", expectedTokens, tokens)
+	}
+	if _, ok := throttler.reserve(other, 10); !ok {
+		t.Fatal("another peer's messages shouldn't have been dropped")
+	}
+
+	// Once a waiting message is released, another one can wait
+	throttler.release(greedy)
+	if wait, ok := throttler.reserve(greedy, 10); !ok || wait == 0 {
+		t.Fatalf("the message should have waited but returned (%s, %t)", wait, ok)
+	}
+
+	// A peer isn't forgotten while its messages are waiting
+	throttler.clock.Set(throttler.clock.Time().Add(time.Hour))
+	throttler.reserve(other, 10)
+	if _, exists := throttler.peers[greedy.Key()]; !exists {
+		t.Fatal("a peer with waiting messages shouldn't have been forgotten")
+	}
+}
@@ -13,6 +13,7 @@ package networking
 // void version(msg_t *, msgnetwork_conn_t *, void *);
 // void getPeerList(msg_t *, msgnetwork_conn_t *, void *);
 // void peerList(msg_t *, msgnetwork_conn_t *, void *);
+// void unknownMessage(msg_t *, msgnetwork_conn_t *, void *);
 import "C"
 
 import (
@@ -109,6 +110,13 @@ type Handshake struct {
 	pending     AddrCert // Connections that I haven't gotten version messages from
 	connections AddrCert // Connections that I think are connected
 
+	// handshakeTimeout is how long a peer has to complete the handshake before
+	// the connection is dropped. 0 means the handshake never times out.
+	handshakeTimeout time.Duration
+	// minVersion is the oldest version peers may run. If nil, peers running
+	// any version of this application are accepted.
+	minVersion *peerVersion
+
 	versionTimeout   timer.TimeoutManager
 	reconnectTimeout timer.TimeoutManager
 	peerListGossiper *timer.Repeater
@@ -128,8 +136,18 @@ func (nm *Handshake) Initialize(
 	registerer prometheus.Registerer,
 	enableStaking bool,
 	networkID uint32,
-) {
+	handshakeTimeout time.Duration,
+	minVersion string,
+) error {
 	log.AssertTrue(nm.net == nil, "Should only register network handlers once")
+	if minVersion != "" {
+		parsedMinVersion, err := parseVersion(minVersion)
+		if err != nil {
+			return err
+		}
+		nm.minVersion = &parsedMinVersion
+	}
+	nm.handshakeTimeout = handshakeTimeout
 	nm.log = log
 	nm.vdrs = vdrs
 	nm.myAddr = myAddr
@@ -149,6 +167,9 @@ func (nm *Handshake) Initialize(
 	net.RegHandler(Version, salticidae.MsgNetworkMsgCallback(C.version), nil)
 	net.RegHandler(GetPeerList, salticidae.MsgNetworkMsgCallback(C.getPeerList), nil)
 	net.RegHandler(PeerList, salticidae.MsgNetworkMsgCallback(C.peerList), nil)
+	for _, op := range unknownOps() {
+		net.RegHandler(op, salticidae.MsgNetworkMsgCallback(C.unknownMessage), nil)
+	}
 
 	nm.handshakeMetrics.Initialize(nm.log, registerer)
 
@@ -160,6 +181,7 @@ func (nm *Handshake) Initialize(
 
 	nm.peerListGossiper = timer.NewRepeater(nm.gossipPeerList, PeerListGossipSpacing)
 	go nm.log.RecoverAndPanic(nm.peerListGossiper.Dispatch)
+	return nil
 }
 
 // AwaitConnections ...
@@ -325,16 +347,32 @@ func (nm *Handshake) connectedToPeer(conn *C.struct_peernetwork_conn_t, addr sal
 
 	nm.pending.Add(addr, cert)
 
+	started := nm.clock.Time()
 	handler := new(func())
 	*handler = func() {
-		if nm.pending.ContainsIP(addr) {
-			nm.SendGetVersion(addr)
-			nm.versionTimeout.Put(longCert, *handler)
+		if !nm.pending.ContainsIP(addr) {
+			return
+		}
+		// Drop peers that stall the handshake so that they can't hold
+		// connections open indefinitely
+		if nm.handshakeExpired(started) {
+			nm.log.Debug("Handshake with %s didn't complete within %s", ip, nm.handshakeTimeout)
+			nm.pending.Remove(addr, cert)
+			nm.net.DelPeer(addr)
+			return
 		}
+		nm.SendGetVersion(addr)
+		nm.versionTimeout.Put(longCert, *handler)
 	}
 	(*handler)()
 }
 
+// handshakeExpired returns true if a handshake that started at [started] has
+// taken longer than the handshake timeout
+func (nm *Handshake) handshakeExpired(started time.Time) bool {
+	return nm.handshakeTimeout > 0 && nm.clock.Time().Sub(started) >= nm.handshakeTimeout
+}
+
 func (nm *Handshake) disconnectedFromPeer(addr salticidae.NetAddr) {
 	cert := ids.ShortID{}
 	if pendingCert, exists := nm.pending.GetID(addr); exists {
@@ -425,6 +463,31 @@ func ping(_ *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer
 	HandshakeNet.send(pong, addr)
 }
 
+// unknownOps returns the ops that don't correspond to a known message
+func unknownOps() []salticidae.Opcode {
+	ops := []salticidae.Opcode(nil)
+	for op := 0; op <= math.MaxUint8; op++ {
+		if _, known := Messages[salticidae.Opcode(op)]; !known {
+			ops = append(ops, salticidae.Opcode(op))
+		}
+	}
+	return ops
+}
+
+// unknownMessage handles the recept of a message with an unknown op
+//export unknownMessage
+func unknownMessage(_msg *C.struct_msg_t, _ *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	msg := salticidae.MsgFromC(salticidae.CMsg(_msg))
+	HandshakeNet.unknownMessage(msg.GetOpcode())
+}
+
+// unknownMessage drops a message with op [op]. The connection is kept alive, as
+// the message may have been sent by a peer running a newer version.
+func (nm *Handshake) unknownMessage(op salticidae.Opcode) {
+	nm.numUnknownReceived.Inc()
+	nm.log.Debug("Dropping message with unknown op %d", op)
+}
+
 // pong handles the recept of a pong message
 //export pong
 func pong(*C.struct_msg_t, *C.struct_msgnetwork_conn_t, unsafe.Pointer) {}
@@ -493,8 +556,9 @@ func version(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.P
 		return
 	}
 
-	if peerVersion := pMsg.Get(VersionStr).(string); !checkCompatibility(CurrentVersion, peerVersion) {
-		HandshakeNet.log.Warn("Bad version")
+	versionStr := pMsg.Get(VersionStr).(string)
+	if err := HandshakeNet.checkCompatibility(versionStr); err != nil {
+		HandshakeNet.log.Warn("Peer's version %s is incompatible: %s", versionStr, err)
 
 		HandshakeNet.net.DelPeer(addr)
 		return
@@ -606,10 +670,27 @@ func getCert(cert salticidae.X509) ids.ShortID {
 	return certID
 }
 
-// checkCompatibility Check to make sure that the peer and I speak the same language.
-func checkCompatibility(myVersion string, peerVersion string) bool {
-	// At the moment, we are all compatible.
-	return true
+// checkCompatibility returns an error if a peer running [versionStr] shouldn't
+// be connected to. Peers are only checked if a minimum version is configured.
+func (nm *Handshake) checkCompatibility(versionStr string) error {
+	if nm.minVersion == nil {
+		return nil
+	}
+	peer, err := parseVersion(versionStr)
+	if err != nil {
+		return err
+	}
+	mine, err := parseVersion(CurrentVersion)
+	if err != nil {
+		return err
+	}
+	if peer.app != mine.app {
+		return errIncompatibleApp
+	}
+	if peer.before(*nm.minVersion) {
+		return fmt.Errorf("%w: %s < %s", errVersionTooOld, peer, nm.minVersion)
+	}
+	return nil
 }
 
 func toAddr(ip utils.IPDesc, autoFree bool) salticidae.NetAddr {
@@ -0,0 +1,106 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestUnknownOps(t *testing.T) {
+	ops := unknownOps()
+	if expected := math.MaxUint8 + 1 - len(Messages); len(ops) != expected {
+		t.Fatalf("Expected %d unknown ops but got %d", expected, len(ops))
+	}
+	for _, op := range ops {
+		if _, known := Messages[op]; known {
+			t.Fatalf("Op %d is known", op)
+		}
+	}
+}
+
+func TestUnknownMessage(t *testing.T) {
+	nm := Handshake{log: logging.NoLog{}}
+	nm.handshakeMetrics.Initialize(logging.NoLog{}, prometheus.NewRegistry())
+
+	// The handshake has no network, so this would panic if the message caused
+	// the connection to be dropped
+	op := unknownOps()[0]
+	nm.unknownMessage(op)
+	nm.unknownMessage(op)
+
+	metric := dto.Metric{}
+	if err := nm.numUnknownReceived.Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	if count := metric.GetCounter().GetValue(); count != 2 {
+		t.Fatalf("Expected 2 unknown messages to be counted but got %f", count)
+	}
+}
+
+func TestHandshakeExpired(t *testing.T) {
+	nm := Handshake{handshakeTimeout: 10 * time.Second}
+	started := time.Now()
+	nm.clock.Set(started)
+
+	nm.clock.Set(started.Add(9 * time.Second))
+	if nm.handshakeExpired(started) {
+		t.Fatal("The handshake shouldn't have expired yet")
+	}
+	// A peer that stalls the handshake is dropped
+	nm.clock.Set(started.Add(10 * time.Second))
+	if !nm.handshakeExpired(started) {
+		t.Fatal("The stalled handshake should have expired")
+	}
+
+	nm.handshakeTimeout = 0
+	nm.clock.Set(started.Add(time.Hour))
+	if nm.handshakeExpired(started) {
+		t.Fatal("The handshake shouldn't expire without a timeout")
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	nm := Handshake{}
+	if err := nm.checkCompatibility(CurrentVersion); err != nil {
+		t.Fatal(err)
+	}
+	if err := nm.checkCompatibility("avalanche/0.0.0"); err != nil {
+		t.Fatalf("Peers running any version should be accepted without a minimum version but got %s", err)
+	}
+	// Without a minimum version, every peer is accepted
+	if err := nm.checkCompatibility("other/0.0.1"); err != nil {
+		t.Fatalf("Peers running another app should be accepted without a minimum version but got %s", err)
+	}
+	if err := nm.checkCompatibility("garbage"); err != nil {
+		t.Fatalf("Unparseable versions should be accepted without a minimum version but got %s", err)
+	}
+
+	minVersion, err := parseVersion("avalanche/0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nm.minVersion = &minVersion
+	if err := nm.checkCompatibility("other/0.0.1"); err != errIncompatibleApp {
+		t.Fatalf("Expected %s but got %v", errIncompatibleApp, err)
+	}
+	if err := nm.checkCompatibility("garbage"); !errors.Is(err, errBadVersion) {
+		t.Fatalf("Expected %s but got %v", errBadVersion, err)
+	}
+	// An old-version peer is rejected
+	if err := nm.checkCompatibility("avalanche/0.0.0"); !errors.Is(err, errVersionTooOld) {
+		t.Fatalf("Expected %s but got %v", errVersionTooOld, err)
+	}
+	if err := nm.checkCompatibility("avalanche/0.1.0"); err != nil {
+		t.Fatal(err)
+	}
+}
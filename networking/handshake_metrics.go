@@ -15,7 +15,8 @@ type handshakeMetrics struct {
 	numGetVersionSent, numGetVersionReceived,
 	numVersionSent, numVersionReceived,
 	numGetPeerlistSent, numGetPeerlistReceived,
-	numPeerlistSent, numPeerlistReceived prometheus.Counter
+	numPeerlistSent, numPeerlistReceived,
+	numUnknownReceived prometheus.Counter
 }
 
 func (hm *handshakeMetrics) Initialize(log logging.Logger, registerer prometheus.Registerer) {
@@ -73,6 +74,12 @@ func (hm *handshakeMetrics) Initialize(log logging.Logger, registerer prometheus
 			Name:      "peerlist_received",
 			Help:      "Number of peerlist messages received",
 		})
+	hm.numUnknownReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "unknown_received",
+			Help:      "Number of messages with an unknown type received",
+		})
 
 	if err := registerer.Register(hm.numPeers); err != nil {
 		log.Error("Failed to register peers statistics due to %s", err)
@@ -101,4 +108,7 @@ func (hm *handshakeMetrics) Initialize(log logging.Logger, registerer prometheus
 	if err := registerer.Register(hm.numPeerlistReceived); err != nil {
 		log.Error("Failed to register peerlist_received statistics due to %s", err)
 	}
+	if err := registerer.Register(hm.numUnknownReceived); err != nil {
+		log.Error("Failed to register unknown_received statistics due to %s", err)
+	}
 }
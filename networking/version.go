@@ -0,0 +1,68 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errBadVersion      = errors.New("version must be of the form <app>/<major>.<minor>.<patch>")
+	errIncompatibleApp = errors.New("peer is running a different application")
+	errVersionTooOld   = errors.New("peer's version is older than the minimum version")
+)
+
+// peerVersion is a version string of the form "<app>/<major>.<minor>.<patch>",
+// such as CurrentVersion
+type peerVersion struct {
+	app                 string
+	major, minor, patch int
+}
+
+// parseVersion parses a version string of the form
+// "<app>/<major>.<minor>.<patch>"
+func parseVersion(version string) (peerVersion, error) {
+	slash := strings.IndexByte(version, '/')
+	if slash <= 0 {
+		return peerVersion{}, fmt.Errorf("%w: %q", errBadVersion, version)
+	}
+	parts := strings.Split(version[slash+1:], ".")
+	if len(parts) != 3 {
+		return peerVersion{}, fmt.Errorf("%w: %q", errBadVersion, version)
+	}
+	nums := [3]int{}
+	for i, part := range parts {
+		num, err := strconv.Atoi(part)
+		if err != nil || num < 0 {
+			return peerVersion{}, fmt.Errorf("%w: %q", errBadVersion, version)
+		}
+		nums[i] = num
+	}
+	return peerVersion{
+		app:   version[:slash],
+		major: nums[0],
+		minor: nums[1],
+		patch: nums[2],
+	}, nil
+}
+
+// before returns true if [v] is an older version than [other]. The application
+// names aren't compared.
+func (v peerVersion) before(other peerVersion) bool {
+	switch {
+	case v.major != other.major:
+		return v.major < other.major
+	case v.minor != other.minor:
+		return v.minor < other.minor
+	default:
+		return v.patch < other.patch
+	}
+}
+
+func (v peerVersion) String() string {
+	return fmt.Sprintf("%s/%d.%d.%d", v.app, v.major, v.minor, v.patch)
+}
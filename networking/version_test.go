@@ -0,0 +1,44 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := parseVersion("avalanche/1.20.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.app != "avalanche" || v.major != 1 || v.minor != 20 || v.patch != 3 {
+		t.Fatalf("Parsed %+v", v)
+	}
+	if str := v.String(); str != "avalanche/1.20.3" {
+		t.Fatalf("Expected avalanche/1.20.3 but got %s", str)
+	}
+
+	for _, bad := range []string{"", "avalanche", "/1.2.3", "avalanche/1.2", "avalanche/1.2.3.4", "avalanche/1.x.3", "avalanche/1.-2.3"} {
+		if _, err := parseVersion(bad); !errors.Is(err, errBadVersion) {
+			t.Fatalf("Parsing %q should have failed with %s but returned %v", bad, errBadVersion, err)
+		}
+	}
+}
+
+func TestVersionBefore(t *testing.T) {
+	versions := []string{"a/0.0.1", "a/0.1.0", "a/0.1.9", "a/0.10.0", "a/1.0.0"}
+	for i, earlierStr := range versions {
+		earlier, _ := parseVersion(earlierStr)
+		if earlier.before(earlier) {
+			t.Fatalf("%s shouldn't be before itself", earlier)
+		}
+		for _, laterStr := range versions[i+1:] {
+			later, _ := parseVersion(laterStr)
+			if !earlier.before(later) || later.before(earlier) {
+				t.Fatalf("%s should be before %s", earlier, later)
+			}
+		}
+	}
+}
@@ -18,6 +18,7 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -52,10 +53,15 @@ type Voting struct {
 
 	router   router.Router
 	executor timer.Executor
+
+	// Delay messages to and from peers that exceed their bandwidth
+	inbound, outbound bandwidthThrottler
 }
 
 // Initialize to the c networking library. Should only be called once ever.
-func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet salticidae.PeerNetwork, conns Connections, router router.Router, registerer prometheus.Registerer) {
+// Each peer is allotted [peerBandwidth] bytes per second in each direction. If
+// [peerBandwidth] is 0, peers aren't throttled.
+func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet salticidae.PeerNetwork, conns Connections, router router.Router, registerer prometheus.Registerer, peerBandwidth uint64) {
 	log.AssertTrue(s.net == nil, "Should only register network handlers once")
 	log.AssertTrue(s.conns == nil, "Should only set connections once")
 	log.AssertTrue(s.router == nil, "Should only set the router once")
@@ -65,6 +71,8 @@ func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet sal
 	s.net = peerNet
 	s.conns = conns
 	s.router = router
+	s.inbound.Initialize(peerBandwidth)
+	s.outbound.Initialize(peerBandwidth)
 
 	s.votingMetrics.Initialize(log, registerer)
 
@@ -422,12 +430,62 @@ func (s *Voting) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32
 	s.numChitsSent.Inc()
 }
 
+// send [msg] to [addrs]. Peers that exceed their bandwidth are sent [msg] once
+// their bandwidth allows it, unless too many messages to them are already
+// waiting, in which case [msg] isn't sent to them.
 func (s *Voting) send(msg Msg, addrs ...salticidae.NetAddr) {
 	ds := msg.DataStream()
 	defer ds.Free()
+
+	size := ds.Size()
+	unthrottled := make([]salticidae.NetAddr, 0, len(addrs))
+	msgBytes := []byte(nil)
+	for _, addr := range addrs {
+		validatorID, exists := s.conns.GetID(addr)
+		if !exists {
+			unthrottled = append(unthrottled, addr)
+			continue
+		}
+		wait, ok := s.outbound.reserve(validatorID, size)
+		if !ok {
+			s.log.Debug("Dropping a message to %s due to too many throttled messages", validatorID)
+			continue
+		}
+		if wait == 0 {
+			unthrottled = append(unthrottled, addr)
+			continue
+		}
+
+		if msgBytes == nil {
+			msgBytes = dataStreamBytes(ds)
+		}
+		s.log.Verbo("Throttling a message to %s for %s", validatorID, wait)
+		op := msg.Op()
+		time.AfterFunc(wait, func() { s.sendThrottled(op, msgBytes, validatorID) })
+	}
+	s.transmit(msg.Op(), ds, unthrottled...)
+}
+
+// sendThrottled sends a message that was delayed by throttling to
+// [validatorID], if it's still connected
+func (s *Voting) sendThrottled(op salticidae.Opcode, msgBytes []byte, validatorID ids.ShortID) {
+	defer s.outbound.release(validatorID)
+
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Dropping a throttled message to a disconnected validator: %s", validatorID)
+		return
+	}
+	ds := salticidae.NewDataStreamFromBytes(msgBytes, false)
+	defer ds.Free()
+	s.transmit(op, ds, addr)
+}
+
+// transmit the message in [ds] to [addrs]. [ds] is moved into the message.
+func (s *Voting) transmit(op salticidae.Opcode, ds salticidae.DataStream, addrs ...salticidae.NetAddr) {
 	ba := salticidae.NewByteArrayMovedFromDataStream(ds, false)
 	defer ba.Free()
-	cMsg := salticidae.NewMsgMovedFromByteArray(msg.Op(), ba, false)
+	cMsg := salticidae.NewMsgMovedFromByteArray(op, ba, false)
 	defer cMsg.Free()
 
 	switch len(addrs) {
@@ -439,19 +497,53 @@ func (s *Voting) send(msg Msg, addrs ...salticidae.NetAddr) {
 	}
 }
 
+// deliver [msg], received from [validatorID], by calling [handle]. If the
+// validator exceeds its bandwidth, [msg] is delivered once its bandwidth allows
+// it, unless too many messages from the validator are already waiting, in
+// which case [msg] is dropped.
+func (s *Voting) deliver(validatorID ids.ShortID, msg Msg, handle func()) {
+	wait, ok := s.inbound.reserve(validatorID, msg.DataStream().Size())
+	if !ok {
+		s.log.Debug("Dropping a message from %s due to too many throttled messages", validatorID)
+		return
+	}
+	if wait == 0 {
+		handle()
+		return
+	}
+	s.log.Verbo("Throttling a message from %s for %s", validatorID, wait)
+	time.AfterFunc(wait, func() {
+		s.executor.Add(func() {
+			s.inbound.release(validatorID)
+			handle()
+		})
+	})
+}
+
+// dataStreamBytes returns a copy of the bytes in [ds]
+func dataStreamBytes(ds salticidae.DataStream) []byte {
+	size := ds.Size()
+	byteHandle := ds.GetDataInPlace(size)
+	defer byteHandle.Release()
+
+	msgBytes := make([]byte, size)
+	copy(msgBytes, byteHandle.Get())
+	return msgBytes
+}
+
 // getAcceptedFrontier handles the recept of a getAcceptedFrontier container
 // message for a chain
 //export getAcceptedFrontier
 func getAcceptedFrontier(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
 	VotingNet.numGetAcceptedFrontierReceived.Inc()
 
-	validatorID, chainID, requestID, _, err := VotingNet.sanitize(_msg, _conn, GetAcceptedFrontier)
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, GetAcceptedFrontier)
 	if err != nil {
 		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
 		return
 	}
 
-	VotingNet.router.GetAcceptedFrontier(validatorID, chainID, requestID)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.GetAcceptedFrontier(validatorID, chainID, requestID) })
 }
 
 // acceptedFrontier handles the recept of an acceptedFrontier message
@@ -475,7 +567,7 @@ func acceptedFrontier(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _
 		containerIDs.Add(containerID)
 	}
 
-	VotingNet.router.AcceptedFrontier(validatorID, chainID, requestID, containerIDs)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.AcceptedFrontier(validatorID, chainID, requestID, containerIDs) })
 }
 
 // getAccepted handles the recept of a getAccepted message
@@ -499,7 +591,7 @@ func getAccepted(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsa
 		containerIDs.Add(containerID)
 	}
 
-	VotingNet.router.GetAccepted(validatorID, chainID, requestID, containerIDs)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.GetAccepted(validatorID, chainID, requestID, containerIDs) })
 }
 
 // accepted handles the recept of an accepted message
@@ -523,7 +615,7 @@ func accepted(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.
 		containerIDs.Add(containerID)
 	}
 
-	VotingNet.router.Accepted(validatorID, chainID, requestID, containerIDs)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.Accepted(validatorID, chainID, requestID, containerIDs) })
 }
 
 // get handles the recept of a get container message for a chain
@@ -539,7 +631,7 @@ func get(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Point
 
 	containerID, _ := ids.ToID(msg.Get(ContainerID).([]byte))
 
-	VotingNet.router.Get(validatorID, chainID, requestID, containerID)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.Get(validatorID, chainID, requestID, containerID) })
 }
 
 // put handles the receipt of a container message
@@ -557,7 +649,7 @@ func put(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Point
 
 	containerBytes := msg.Get(ContainerBytes).([]byte)
 
-	VotingNet.router.Put(validatorID, chainID, requestID, containerID, containerBytes)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.Put(validatorID, chainID, requestID, containerID, containerBytes) })
 }
 
 // pushQuery handles the recept of a pull query message
@@ -575,7 +667,7 @@ func pushQuery(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe
 
 	containerBytes := msg.Get(ContainerBytes).([]byte)
 
-	VotingNet.router.PushQuery(validatorID, chainID, requestID, containerID, containerBytes)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.PushQuery(validatorID, chainID, requestID, containerID, containerBytes) })
 }
 
 // pullQuery handles the recept of a query message
@@ -591,7 +683,7 @@ func pullQuery(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe
 
 	containerID, _ := ids.ToID(msg.Get(ContainerID).([]byte))
 
-	VotingNet.router.PullQuery(validatorID, chainID, requestID, containerID)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.PullQuery(validatorID, chainID, requestID, containerID) })
 }
 
 // chits handles the recept of a chits message
@@ -615,7 +707,7 @@ func chits(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Poi
 		votes.Add(vote)
 	}
 
-	VotingNet.router.Chits(validatorID, chainID, requestID, votes)
+	VotingNet.deliver(validatorID, msg, func() { VotingNet.router.Chits(validatorID, chainID, requestID, votes) })
 }
 
 func (s *Voting) sanitize(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, op salticidae.Opcode) (ids.ShortID, ids.ID, uint32, Msg, error) {
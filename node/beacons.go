@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// beaconConnections reports which peers this node is connected to
+type beaconConnections interface{ ContainsID(ids.ShortID) bool }
+
+// beaconDialer initiates a connection to a peer
+type beaconDialer interface {
+	Dial(ip utils.IPDesc, nodeID ids.ShortID) error
+}
+
+// beaconReconnector dials the beacons whenever this node isn't connected to
+// any of them, so that the node can always rejoin the network. While
+// disconnected, the time between attempts doubles from [minBackoff] up to
+// [maxBackoff].
+type beaconReconnector struct {
+	log         logging.Logger
+	beacons     []*Peer
+	connections beaconConnections
+	dialer      beaconDialer
+
+	minBackoff, maxBackoff time.Duration
+
+	closer chan struct{}
+	done   chan struct{}
+}
+
+func newBeaconReconnector(
+	log logging.Logger,
+	beacons []*Peer,
+	connections beaconConnections,
+	dialer beaconDialer,
+	minBackoff, maxBackoff time.Duration,
+) *beaconReconnector {
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	return &beaconReconnector{
+		log:         log,
+		beacons:     beacons,
+		connections: connections,
+		dialer:      dialer,
+		minBackoff:  minBackoff,
+		maxBackoff:  maxBackoff,
+		closer:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start checking the beacon connections in the background
+func (r *beaconReconnector) Start() { go r.log.RecoverAndPanic(r.run) }
+
+// Stop checking the beacon connections. Returns once the background loop has
+// exited.
+func (r *beaconReconnector) Stop() {
+	close(r.closer)
+	<-r.done
+}
+
+func (r *beaconReconnector) run() {
+	defer close(r.done)
+
+	backoff := r.minBackoff
+	for {
+		delay := r.minBackoff
+		if r.reconnect() {
+			delay = backoff
+			backoff *= 2
+			if backoff > r.maxBackoff {
+				backoff = r.maxBackoff
+			}
+		} else {
+			backoff = r.minBackoff
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.closer:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// reconnect dials every beacon if this node isn't connected to any of them.
+// Returns true if the beacons were dialed.
+func (r *beaconReconnector) reconnect() bool {
+	for _, beacon := range r.beacons {
+		if r.connections.ContainsID(beacon.ID) {
+			return false
+		}
+	}
+
+	r.log.Debug("not connected to any beacons, dialing %d beacons", len(r.beacons))
+	for _, beacon := range r.beacons {
+		if err := r.dialer.Dial(beacon.IP, beacon.ID); err != nil {
+			r.log.Debug("failed to dial beacon %s at %s: %s", beacon.ID, beacon.IP, err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type testBeaconConnections struct {
+	lock      sync.Mutex
+	connected ids.ShortSet
+}
+
+func (c *testBeaconConnections) ContainsID(id ids.ShortID) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.connected.Contains(id)
+}
+
+func (c *testBeaconConnections) set(nodeIDs ...ids.ShortID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.connected.Clear()
+	c.connected.Add(nodeIDs...)
+}
+
+type testBeaconDialer struct{ dials chan ids.ShortID }
+
+func (d *testBeaconDialer) Dial(_ utils.IPDesc, nodeID ids.ShortID) error {
+	d.dials <- nodeID
+	return nil
+}
+
+func TestBeaconReconnector(t *testing.T) {
+	beacons := []*Peer{
+		{IP: utils.IPDesc{IP: net.IPv4(127, 0, 0, 1), Port: 9651}, ID: ids.NewShortID([20]byte{1})},
+		{IP: utils.IPDesc{IP: net.IPv4(127, 0, 0, 1), Port: 9652}, ID: ids.NewShortID([20]byte{2})},
+	}
+	connections := &testBeaconConnections{}
+	dialer := &testBeaconDialer{dials: make(chan ids.ShortID, 100)}
+	r := newBeaconReconnector(logging.NoLog{}, beacons, connections, dialer, time.Millisecond, 4*time.Millisecond)
+	r.Start()
+	defer r.Stop()
+
+	// While disconnected, every beacon is dialed repeatedly
+	dialed := map[[20]byte]int{}
+	for i := 0; i < 3*len(beacons); i++ {
+		select {
+		case id := <-dialer.dials:
+			dialed[id.Key()]++
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for the beacons to be dialed")
+		}
+	}
+	for _, beacon := range beacons {
+		if dialed[beacon.ID.Key()] < 2 {
+			t.Fatalf("beacon %s should have been dialed repeatedly but was dialed %d times", beacon.ID, dialed[beacon.ID.Key()])
+		}
+	}
+
+	// Once connected to a beacon, no more attempts are made
+	connections.set(beacons[1].ID)
+	time.Sleep(10 * time.Millisecond)
+	for len(dialer.dials) > 0 {
+		<-dialer.dials
+	}
+	time.Sleep(20 * time.Millisecond)
+	if numDials := len(dialer.dials); numDials != 0 {
+		t.Fatalf("shouldn't have dialed while connected to a beacon but dialed %d times", numDials)
+	}
+
+	// Losing the connection causes the beacons to be dialed again
+	connections.set()
+	select {
+	case <-dialer.dials:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for the beacons to be redialed")
+	}
+}
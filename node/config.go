@@ -4,9 +4,16 @@
 package node
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
 	"github.com/ava-labs/go-ethereum/p2p/nat"
 
+	"github.com/ava-labs/gecko/api/health"
+	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/consensus/avalanche"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/utils"
@@ -16,7 +23,11 @@ import (
 // Config contains all of the configurations of an Ava node.
 type Config struct {
 	// protocol to use for opening the network interface
-	Nat nat.Interface
+	Nat nat.Interface `json:"-"`
+
+	// NatTraversal is true if the node relies on mapping its ports through
+	// [Nat] to be reachable
+	NatTraversal bool
 
 	// ID of the network this node should connect to
 	NetworkID uint32
@@ -31,7 +42,7 @@ type Config struct {
 	EnableCrypto bool
 
 	// Database to use for the node
-	DB database.Database
+	DB database.Database `json:"-"`
 
 	// Staking configuration
 	StakingIP       utils.IPDesc
@@ -39,14 +50,57 @@ type Config struct {
 	StakingKeyFile  string
 	StakingCertFile string
 
+	// Peer handshake configuration
+	// Peers that don't complete the handshake within HandshakeTimeout are
+	// disconnected. If 0, the handshake never times out.
+	HandshakeTimeout time.Duration
+	// Peers running a version older than MinPeerVersion are disconnected during
+	// the handshake. If empty, peers running any version are accepted.
+	MinPeerVersion string
+	// Each peer is allotted PeerBandwidth bytes per second of consensus
+	// messages in each direction. Messages over a peer's allotment are
+	// delayed, and dropped if too many are already delayed. If 0, peers aren't
+	// throttled.
+	PeerBandwidth uint64
+
+	// Genesis configuration
+	// If GenesisURL isn't empty, the Platform chain's genesis data is fetched
+	// from it over https and must have the SHA-256 hash GenesisHash
+	GenesisURL  string
+	GenesisHash ids.ID
+
 	// Bootstrapping configuration
 	BootstrapPeers []*Peer
 
+	// Beacon reconnection configuration
+	// While not connected to any bootstrap peer, they're dialed with a backoff
+	// that doubles from BeaconReconnectBackoff up to BeaconReconnectMaxBackoff.
+	// If BeaconReconnectBackoff is 0, they aren't redialed.
+	BeaconReconnectBackoff    time.Duration
+	BeaconReconnectMaxBackoff time.Duration
+	// If the Platform chain hasn't bootstrapped within BootstrapTimeout,
+	// BootstrapTimeoutPolicy is applied. If 0, bootstrapping never times out.
+	BootstrapTimeout       time.Duration
+	BootstrapTimeoutPolicy chains.BootstrapTimeoutPolicy
+
 	// HTTP configuration
 	HTTPPort      uint16
 	EnableHTTPS   bool
 	HTTPSKeyFile  string
 	HTTPSCertFile string
+	// APIRequestTimeout is the maximum duration of an API request. If 0,
+	// requests only time out if the client asks them to.
+	APIRequestTimeout time.Duration
+	// DrainTimeout is how long Drain waits for in-flight API requests
+	DrainTimeout time.Duration
+	// APIRequestLogLevel is the level API requests are logged at. If Off,
+	// they aren't logged.
+	APIRequestLogLevel logging.Level
+	// APIRequestLogJSON logs API requests as JSON objects
+	APIRequestLogJSON bool
+	// SlowQueryThreshold is the duration after which API requests are logged
+	// at the Warn level. If 0, slow requests aren't logged.
+	SlowQueryThreshold time.Duration
 
 	// Enable/Disable APIs
 	AdminAPIEnabled    bool
@@ -66,6 +120,118 @@ type Config struct {
 	// IPCEnabled configuration
 	IPCEnabled bool
 
+	// HealthAPIEnabled exposes the Health API, which reports the node as
+	// unhealthy unless it has bootstrapped and meets HealthThresholds
+	HealthAPIEnabled bool
+	HealthThresholds health.Thresholds
+
 	// Router that is used to handle incoming consensus messages
-	ConsensusRouter router.Router
+	ConsensusRouter router.Router `json:"-"`
+
+	// SelfTest configuration
+	// If true, the node is initialized and then immediately shut down
+	SelfTest bool
+
+	// Feature flags enabled on every chain this node runs
+	Features map[string]bool
+
+	// MinPeersForWrites is the number of peers this node must be connected to
+	// for the timestamp VM to accept proposals
+	MinPeersForWrites int
+
+	// TimestampDBEncryptionKey, if non-empty, is the secret the timestamp VM
+	// derives the key that encrypts its database values from
+	TimestampDBEncryptionKey string
+
+	// TimestampMaxMempoolBytes is the maximum total size of the data in the
+	// timestamp VM's mempool. If 0, the mempool isn't bounded.
+	TimestampMaxMempoolBytes int
+
+	// TimestampSnapshotInterval is how often the timestamp VM exports a
+	// snapshot of its chain to TimestampSnapshotDir. If 0 or the directory is
+	// empty, snapshots aren't exported.
+	TimestampSnapshotInterval time.Duration
+	TimestampSnapshotDir      string
+
+	// TimestampProposeRate is the average number of blocks per second that may
+	// be proposed through the timestamp VM's API, in bursts of up to
+	// TimestampProposeBurst blocks. If 0, proposals aren't rate limited.
+	TimestampProposeRate  float64
+	TimestampProposeBurst float64
+
+	// TimestampMaxReorgDepth is the maximum number of accepted blocks that a
+	// timestamp VM block may replace. If 0, the depth isn't limited.
+	TimestampMaxReorgDepth uint64
+
+	// TimestampCodecName is the name of the codec the timestamp VM serializes
+	// blocks with. If empty, the default codec is used.
+	TimestampCodecName string
+
+	// TimestampMaxMempoolSize is the maximum number of pieces of data in the
+	// timestamp VM's mempool. If 0, the default is used.
+	TimestampMaxMempoolSize int
+
+	// TimestampRequeueRejected re-adds the data of rejected timestamp VM
+	// blocks to the mempool instead of dropping it
+	TimestampRequeueRejected bool
+
+	// TimestampCompactionThreshold is the number of blocks the timestamp VM
+	// prunes before compacting its database. If 0, it's never compacted.
+	TimestampCompactionThreshold int
+
+	// TimestampEnableSearch maintains an index that allows the data of
+	// accepted timestamp VM blocks to be searched
+	TimestampEnableSearch bool
+
+	// TimestampProposeTimeout is how long a synchronous proposal to the
+	// timestamp VM waits for its block to be accepted. If 0, the default is
+	// used.
+	TimestampProposeTimeout time.Duration
+
+	// TimestampMaxFutureDrift is how far ahead of local time a timestamp VM
+	// block's timestamp may be. If 0, the default is used.
+	TimestampMaxFutureDrift time.Duration
+
+	// TimestampMaxPageSize is the maximum number of items returned by a range
+	// query through the timestamp VM's API. If 0, the default is used.
+	TimestampMaxPageSize int
+}
+
+// Valid returns nil if the servers this config describes can be started, or an
+// error describing why they can't
+func (c *Config) Valid() error {
+	switch {
+	case c.HTTPPort == 0:
+		return fmt.Errorf("HTTPPort = %d: Fails the condition that: 0 < HTTPPort", c.HTTPPort)
+	case c.StakingIP.Port == 0:
+		return fmt.Errorf("StakingPort = %d: Fails the condition that: 0 < StakingPort", c.StakingIP.Port)
+	case c.HTTPPort == c.StakingIP.Port:
+		return fmt.Errorf("HTTPPort = %d, StakingPort = %d: Fails the condition that: HTTPPort != StakingPort", c.HTTPPort, c.StakingIP.Port)
+	case c.EnableStaking && (c.StakingIP.IP == nil || c.StakingIP.IP.IsUnspecified()):
+		return fmt.Errorf("StakingIP = %s: Fails the condition that: the staking IP is set when staking is enabled", c.StakingIP)
+	case c.GenesisURL != "" && c.GenesisHash.IsZero():
+		return fmt.Errorf("GenesisURL = %s, GenesisHash = %s: Fails the condition that: the genesis hash is set when the genesis URL is", c.GenesisURL, c.GenesisHash)
+	default:
+		return nil
+	}
+}
+
+// redacted replaces the values of secret fields when a config is serialized
+const redacted = "<redacted>"
+
+// RedactedJSON returns the JSON representation of this config. The values of
+// fields that may reveal secrets, such as private key files, are redacted.
+// Fields that can't be serialized, such as the database, are omitted.
+func (c *Config) RedactedJSON() ([]byte, error) {
+	config := *c
+	for _, secret := range []*string{
+		&config.StakingKeyFile,
+		&config.HTTPSKeyFile,
+		&config.TimestampDBEncryptionKey,
+	} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	return json.MarshalIndent(config, "", "    ")
 }
@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+)
+
+func TestConfigRedactedJSON(t *testing.T) {
+	config := Config{
+		NetworkID:       12345,
+		DB:              memdb.New(),
+		StakingKeyFile:  "keys/staker.key",
+		StakingCertFile: "keys/staker.crt",
+		HTTPPort:        9650,
+	}
+
+	configJSON, err := config.RedactedJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(configJSON), "keys/staker.key") {
+		t.Fatalf("staking key file should have been redacted:\n%s", configJSON)
+	}
+
+	parsed := map[string]interface{}{}
+	if err := json.Unmarshal(configJSON, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile := parsed["StakingKeyFile"]; keyFile != redacted {
+		t.Fatalf("StakingKeyFile should be %q but is %q", redacted, keyFile)
+	}
+	if certFile := parsed["StakingCertFile"]; certFile != "keys/staker.crt" {
+		t.Fatalf("StakingCertFile should be %q but is %q", "keys/staker.crt", certFile)
+	}
+	if httpPort := parsed["HTTPPort"]; httpPort != float64(9650) {
+		t.Fatalf("HTTPPort should be %d but is %v", 9650, httpPort)
+	}
+	if _, ok := parsed["DB"]; ok {
+		t.Fatalf("DB shouldn't have been serialized")
+	}
+
+	// Redacting shouldn't modify the original config
+	if config.StakingKeyFile != "keys/staker.key" {
+		t.Fatalf("RedactedJSON modified the config")
+	}
+}
+
+func TestConfigValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{
+			name: "valid",
+			config: Config{
+				HTTPPort:      9650,
+				StakingIP:     utils.IPDesc{IP: net.IPv4(127, 0, 0, 1), Port: 9651},
+				EnableStaking: true,
+			},
+			valid: true,
+		},
+		{
+			name: "duplicate ports",
+			config: Config{
+				HTTPPort:  9650,
+				StakingIP: utils.IPDesc{IP: net.IPv4(127, 0, 0, 1), Port: 9650},
+			},
+		},
+		{
+			name: "zero HTTP port",
+			config: Config{
+				StakingIP: utils.IPDesc{IP: net.IPv4(127, 0, 0, 1), Port: 9651},
+			},
+		},
+		{
+			name: "zero staking port",
+			config: Config{
+				HTTPPort:  9650,
+				StakingIP: utils.IPDesc{IP: net.IPv4(127, 0, 0, 1)},
+			},
+		},
+		{
+			name: "staking without an IP",
+			config: Config{
+				HTTPPort:      9650,
+				StakingIP:     utils.IPDesc{Port: 9651},
+				EnableStaking: true,
+			},
+		},
+		{
+			name: "no staking without an IP",
+			config: Config{
+				HTTPPort:  9650,
+				StakingIP: utils.IPDesc{Port: 9651},
+			},
+			valid: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Valid()
+			if test.valid && err != nil {
+				t.Fatalf("config should have been valid but returned %s", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("config should have been invalid")
+			}
+		})
+	}
+}
+
+func TestConfigValidGenesisURL(t *testing.T) {
+	config := Config{
+		HTTPPort:   9650,
+		StakingIP:  utils.IPDesc{Port: 9651},
+		GenesisURL: "https://example.com/genesis",
+	}
+	if err := config.Valid(); err == nil {
+		t.Fatal("a genesis URL without a genesis hash should have been invalid")
+	}
+
+	config.GenesisHash = ids.NewID([32]byte{1})
+	if err := config.Valid(); err != nil {
+		t.Fatalf("a genesis URL with a genesis hash should have been valid but returned %s", err)
+	}
+}
@@ -0,0 +1,65 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+const (
+	// maxGenesisSize is the maximum size of genesis data fetched from a URL
+	maxGenesisSize = 16 * 1024 * 1024
+
+	// genesisFetchTimeout is the maximum duration of fetching genesis data
+	genesisFetchTimeout = time.Minute
+)
+
+var (
+	errGenesisNotHTTPS = errors.New("genesis URL must use https")
+	errGenesisTooLarge = fmt.Errorf("genesis data must be at most %d bytes", maxGenesisSize)
+)
+
+// fetchGenesis downloads the genesis data at [genesisURL] with [client] and
+// returns it if its SHA-256 hash is [expectedHash]
+func fetchGenesis(client *http.Client, genesisURL string, expectedHash ids.ID) ([]byte, error) {
+	u, err := url.Parse(genesisURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, errGenesisNotHTTPS
+	}
+
+	resp, err := client.Get(genesisURL)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch genesis from %s: %w", genesisURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("couldn't fetch genesis from %s: %s", genesisURL, resp.Status)
+	}
+
+	genesisBytes, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxGenesisSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch genesis from %s: %w", genesisURL, err)
+	}
+	if len(genesisBytes) > maxGenesisSize {
+		return nil, errGenesisTooLarge
+	}
+
+	hash := ids.NewID(hashing.ComputeHash256Array(genesisBytes))
+	if !hash.Equals(expectedHash) {
+		return nil, fmt.Errorf("genesis fetched from %s has hash %s but expected %s", genesisURL, hash, expectedHash)
+	}
+	return genesisBytes, nil
+}
@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestFetchGenesis(t *testing.T) {
+	genesisBytes := []byte("genesis data")
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/genesis":
+			w.Write(genesisBytes)
+		case "/tampered":
+			w.Write([]byte("tampered genesis data"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	hash := ids.NewID(hashing.ComputeHash256Array(genesisBytes))
+
+	fetched, err := fetchGenesis(client, server.URL+"/genesis", hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fetched, genesisBytes) {
+		t.Fatalf("fetched genesis should have been %q but was %q", genesisBytes, fetched)
+	}
+
+	if _, err := fetchGenesis(client, server.URL+"/tampered", hash); err == nil {
+		t.Fatal("fetching genesis with a mismatched hash should have failed")
+	}
+	if _, err := fetchGenesis(client, server.URL+"/missing", hash); err == nil {
+		t.Fatal("fetching missing genesis should have failed")
+	}
+}
+
+func TestFetchGenesisRequiresHTTPS(t *testing.T) {
+	genesisBytes := []byte("genesis data")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(genesisBytes)
+	}))
+	defer server.Close()
+
+	hash := ids.NewID(hashing.ComputeHash256Array(genesisBytes))
+	if _, err := fetchGenesis(server.Client(), server.URL, hash); err != errGenesisNotHTTPS {
+		t.Fatalf("fetching genesis over http should have failed with %s but returned %v", errGenesisNotHTTPS, err)
+	}
+}
+
+func TestFetchGenesisTooLarge(t *testing.T) {
+	genesisBytes := make([]byte, maxGenesisSize+1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(genesisBytes)
+	}))
+	defer server.Close()
+
+	hash := ids.NewID(hashing.ComputeHash256Array(genesisBytes))
+	if _, err := fetchGenesis(server.Client(), server.URL, hash); err != errGenesisTooLarge {
+		t.Fatalf("fetching too large genesis should have failed with %s but returned %v", errGenesisTooLarge, err)
+	}
+}
@@ -0,0 +1,80 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/go-ethereum/p2p/nat"
+)
+
+const (
+	// natMapTimeout is the lifetime of a port mapping on the gateway
+	natMapTimeout = 20 * time.Minute
+	// natMapUpdateInterval is how often a port mapping is renewed, so that it
+	// doesn't expire
+	natMapUpdateInterval = 15 * time.Minute
+)
+
+var errNoPortMapped = errors.New("couldn't map any port through the NAT")
+
+// PortMapping is a local TCP port to map to the same port on the gateway
+type PortMapping struct {
+	Port uint16
+	Name string
+}
+
+// MapPorts maps each of [mappings] through [n] and keeps the mappings alive
+// until [closer] is closed. Mappings that fail are logged as warnings and
+// retried when they would have been renewed. Returns an error if none of
+// [mappings] could be mapped.
+func MapPorts(n nat.Interface, mappings []PortMapping, closer <-chan struct{}, log logging.Logger) error {
+	if n == nil || len(mappings) == 0 {
+		return nil
+	}
+
+	mapped := 0
+	for _, mapping := range mappings {
+		if addMapping(n, mapping, log) {
+			mapped++
+		}
+		go keepMapped(n, mapping, closer, log)
+	}
+	if mapped == 0 {
+		return errNoPortMapped
+	}
+	return nil
+}
+
+// addMapping maps [mapping] through [n] and returns true if it succeeded
+func addMapping(n nat.Interface, mapping PortMapping, log logging.Logger) bool {
+	port := int(mapping.Port)
+	if err := n.AddMapping("TCP", port, port, mapping.Name, natMapTimeout); err != nil {
+		log.Warn("couldn't map port %d for %s with %s: %s", port, mapping.Name, n, err)
+		return false
+	}
+	log.Info("mapped port %d for %s with %s", port, mapping.Name, n)
+	return true
+}
+
+// keepMapped renews [mapping] until [closer] is closed, and then removes it
+func keepMapped(n nat.Interface, mapping PortMapping, closer <-chan struct{}, log logging.Logger) {
+	refresh := time.NewTicker(natMapUpdateInterval)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-closer:
+			port := int(mapping.Port)
+			if err := n.DeleteMapping("TCP", port, port); err != nil {
+				log.Debug("couldn't remove the mapping of port %d: %s", port, err)
+			}
+			return
+		case <-refresh.C:
+			addMapping(n, mapping, log)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var errTestMapping = errors.New("mapping failed")
+
+// testNAT is a NAT that fails to map the ports in [failing]
+type testNAT struct {
+	lock            sync.Mutex
+	failing         map[int]bool
+	mapped, deleted []int
+}
+
+func (n *testNAT) AddMapping(_ string, extport, _ int, _ string, _ time.Duration) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.failing[extport] {
+		return errTestMapping
+	}
+	n.mapped = append(n.mapped, extport)
+	return nil
+}
+
+func (n *testNAT) DeleteMapping(_ string, extport, _ int) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.deleted = append(n.deleted, extport)
+	return nil
+}
+
+func (n *testNAT) ExternalIP() (net.IP, error) { return net.IPv4zero, nil }
+func (n *testNAT) String() string              { return "testNAT" }
+
+// testWarnLog records the warnings logged to it
+type testWarnLog struct {
+	logging.NoLog
+
+	lock     sync.Mutex
+	warnings []string
+}
+
+func (l *testWarnLog) Warn(format string, args ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func TestMapPortsWarnsOnFailure(t *testing.T) {
+	n := &testNAT{failing: map[int]bool{9651: true}}
+	log := &testWarnLog{}
+	closer := make(chan struct{})
+
+	mappings := []PortMapping{
+		{Port: 9651, Name: "staking"},
+		{Port: 9650, Name: "http"},
+	}
+	if err := MapPorts(n, mappings, closer, log); err != nil {
+		t.Fatalf("mapping should have succeeded when one port was mapped but returned %s", err)
+	}
+	close(closer)
+
+	log.lock.Lock()
+	defer log.lock.Unlock()
+	if len(log.warnings) != 1 {
+		t.Fatalf("expected 1 warning but got %v", log.warnings)
+	}
+	if expected := "couldn't map port 9651 for staking with testNAT: mapping failed"; log.warnings[0] != expected {
+		t.Fatalf("warning should have been %q but was %q", expected, log.warnings[0])
+	}
+}
+
+func TestMapPortsAllFail(t *testing.T) {
+	n := &testNAT{failing: map[int]bool{9651: true, 9650: true}}
+	log := &testWarnLog{}
+	closer := make(chan struct{})
+	defer close(closer)
+
+	mappings := []PortMapping{
+		{Port: 9651, Name: "staking"},
+		{Port: 9650, Name: "http"},
+	}
+	if err := MapPorts(n, mappings, closer, log); err != errNoPortMapped {
+		t.Fatalf("mapping should have failed with %s but returned %v", errNoPortMapped, err)
+	}
+
+	log.lock.Lock()
+	defer log.lock.Unlock()
+	if len(log.warnings) != 2 {
+		t.Fatalf("expected 2 warnings but got %v", log.warnings)
+	}
+}
+
+func TestMapPortsRemovesMappings(t *testing.T) {
+	n := &testNAT{}
+	closer := make(chan struct{})
+
+	if err := MapPorts(n, []PortMapping{{Port: 9650, Name: "http"}}, closer, logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+	close(closer)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		n.lock.Lock()
+		deleted := len(n.deleted)
+		n.lock.Unlock()
+		if deleted == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the mapping should have been removed once the closer was closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMapPortsNoNAT(t *testing.T) {
+	if err := MapPorts(nil, []PortMapping{{Port: 9650, Name: "http"}}, nil, logging.NoLog{}); err != nil {
+		t.Fatalf("mapping without a NAT should have succeeded but returned %s", err)
+	}
+}
@@ -4,23 +4,26 @@
 package node
 
 // #include "salticidae/network.h"
-// void onTerm(int sig, void *);
+// void onShutdownCheck(timerev_t *, void *);
 // void errorHandler(SalticidaeCError *, bool, void *);
 import "C"
 
 import (
-	"crypto/x509"
-	"encoding/pem"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/ava-labs/salticidae-go"
 
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/api/admin"
+	"github.com/ava-labs/gecko/api/health"
 	"github.com/ava-labs/gecko/api/ipcs"
 	"github.com/ava-labs/gecko/api/keystore"
 	"github.com/ava-labs/gecko/api/metrics"
@@ -34,6 +37,7 @@ import (
 	"github.com/ava-labs/gecko/networking/xputtest"
 	"github.com/ava-labs/gecko/snow/triggers"
 	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/logging"
 	"github.com/ava-labs/gecko/utils/wrappers"
@@ -51,10 +55,17 @@ import (
 
 const (
 	maxMessageSize = 1 << 25 // maximum size of a message sent with salticidae
+
+	// shutdownCheckInterval is how often the event loop checks whether the
+	// node should stop dispatching
+	shutdownCheckInterval = 100 * time.Millisecond
 )
 
 var (
 	genesisHashKey = []byte("genesisID")
+
+	errPlatformChainNotCreated = errors.New("platform chain wasn't created")
+	errDialSelf                = errors.New("can't add self as a peer")
 )
 
 // MainNode is the reference for node callbacks
@@ -91,6 +102,9 @@ type Node struct {
 
 	// Event loop manager
 	EC salticidae.EventContext
+	// Periodically stops the event loop if [shutdownCtx] is done
+	shutdownTimer salticidae.TimerEvent
+	shutdownCtx   context.Context
 	// Network that manages validator peers
 	PeerNet salticidae.PeerNetwork
 	// Network that manages clients
@@ -101,6 +115,9 @@ type Node struct {
 	// API that handles voting messages
 	ConsensusAPI *networking.Voting
 
+	// Dials the beacons when this node isn't connected to any of them
+	beaconReconnector *beaconReconnector
+
 	// current validators of the network
 	vdrs validators.Manager
 
@@ -112,6 +129,9 @@ type Node struct {
 	// Handles HTTP API calls
 	APIServer api.Server
 
+	// Genesis data of the Platform chain
+	genesisBytes []byte
+
 	// This node's configuration
 	Config *Config
 }
@@ -122,10 +142,15 @@ type Node struct {
  ******************************************************************************
  */
 
-//export onTerm
-func onTerm(C.int, unsafe.Pointer) {
-	MainNode.Log.Debug("Terminate signal received")
-	MainNode.EC.Stop()
+//export onShutdownCheck
+func onShutdownCheck(*C.timerev_t, unsafe.Pointer) {
+	// Called from within the event loop, so the event loop can be stopped here
+	if MainNode.shutdownCtx.Err() != nil {
+		MainNode.Log.Debug("stopping the node's servers")
+		MainNode.EC.Stop()
+		return
+	}
+	MainNode.shutdownTimer.Add(shutdownCheckInterval.Seconds())
 }
 
 //export errorHandler
@@ -143,12 +168,6 @@ func (n *Node) initNetlib() error {
 	// Create main event context
 	n.EC = salticidae.NewEventContext()
 
-	// Set up interrupt signal and terminate signal handlers
-	evInt := salticidae.NewSigEvent(n.EC, salticidae.SigEventCallback(C.onTerm), nil)
-	evInt.Add(salticidae.SIGINT)
-	evTerm := salticidae.NewSigEvent(n.EC, salticidae.SigEventCallback(C.onTerm), nil)
-	evTerm.Add(salticidae.SIGTERM)
-
 	// Create peer network config, may have tls enabled
 	peerConfig := salticidae.NewPeerNetworkConfig()
 	if n.Config.EnableStaking {
@@ -184,6 +203,66 @@ func (n *Node) initNetlib() error {
 	return nil
 }
 
+// Dial initiates a connection to the peer at [ip] with ID [nodeID]
+func (n *Node) Dial(ip utils.IPDesc, nodeID ids.ShortID) error {
+	peer := Peer{IP: ip, ID: nodeID}
+	if peer.IP.Equal(n.Config.StakingIP) {
+		return errDialSelf
+	}
+
+	err := salticidae.NewError()
+	peerIP := salticidae.NewNetAddrFromIPPortString(peer.IP.String(), true, &err)
+	if code := err.GetCode(); code != 0 {
+		return fmt.Errorf("failed to create peer ip addr: %s", salticidae.StrError(code))
+	}
+	n.PeerNet.AddPeer(peerIP)
+	n.Log.Info("dialing peer %s at %s", peer.ID, peer.IP)
+	return nil
+}
+
+// initBeaconReconnector starts dialing the bootstrap peers whenever this node
+// isn't connected to any of them
+func (n *Node) initBeaconReconnector() {
+	// Don't dial peers while running the self-test
+	if n.Config.BeaconReconnectBackoff == 0 || n.Config.SelfTest {
+		return
+	}
+	beacons := []*Peer(nil)
+	for _, peer := range n.Config.BootstrapPeers {
+		if !peer.IP.Equal(n.Config.StakingIP) {
+			beacons = append(beacons, peer)
+		}
+	}
+	if len(beacons) == 0 {
+		return
+	}
+	n.beaconReconnector = newBeaconReconnector(
+		/*log=*/ n.Log,
+		/*beacons=*/ beacons,
+		/*connections=*/ n.ValidatorAPI.Connections(),
+		/*dialer=*/ n,
+		/*minBackoff=*/ n.Config.BeaconReconnectBackoff,
+		/*maxBackoff=*/ n.Config.BeaconReconnectMaxBackoff,
+	)
+	n.beaconReconnector.Start()
+}
+
+// initBootstrapTimeout applies the bootstrap timeout policy if the Platform
+// chain doesn't bootstrap within the bootstrap timeout
+func (n *Node) initBootstrapTimeout() {
+	if n.Config.SelfTest {
+		return
+	}
+	n.chainManager.MonitorBootstrap(
+		/*timeout=*/ n.Config.BootstrapTimeout,
+		/*policy=*/ n.Config.BootstrapTimeoutPolicy,
+		/*exit=*/ n.EC.Stop,
+	)
+}
+
+// BootstrapStatus returns the progress of bootstrapping the Platform chain
+func (n *Node) BootstrapStatus() chains.BootstrapStatus { return n.chainManager.BootstrapStatus() }
+
 func (n *Node) initValidatorNet() error {
 	// Initialize validator manager and default subnet's validator set
 	defaultSubnetValidators := validators.NewSet()
@@ -200,7 +279,7 @@ func (n *Node) initValidatorNet() error {
 	}
 
 	n.ValidatorAPI = &networking.HandshakeNet
-	n.ValidatorAPI.Initialize(
+	return n.ValidatorAPI.Initialize(
 		/*log=*/ n.Log,
 		/*validators=*/ defaultSubnetValidators,
 		/*myIP=*/ serverIP,
@@ -209,9 +288,9 @@ func (n *Node) initValidatorNet() error {
 		/*metrics=*/ n.Config.ConsensusParams.Metrics,
 		/*enableStaking=*/ n.Config.EnableStaking,
 		/*networkID=*/ n.Config.NetworkID,
+		/*handshakeTimeout=*/ n.Config.HandshakeTimeout,
+		/*minVersion=*/ n.Config.MinPeerVersion,
 	)
-
-	return nil
 }
 
 func (n *Node) initConsensusNet() {
@@ -219,7 +298,7 @@ func (n *Node) initConsensusNet() {
 	n.Log.AssertTrue(ok, "should have initialize the validator set already")
 
 	n.ConsensusAPI = &networking.VotingNet
-	n.ConsensusAPI.Initialize(n.Log, vdrs, n.PeerNet, n.ValidatorAPI.Connections(), n.chainManager.Router(), n.Config.ConsensusParams.Metrics)
+	n.ConsensusAPI.Initialize(n.Log, vdrs, n.PeerNet, n.ValidatorAPI.Connections(), n.chainManager.Router(), n.Config.ConsensusParams.Metrics, n.Config.PeerBandwidth)
 
 	n.Log.AssertNoError(n.ConsensusDispatcher.Register("gossip", n.ConsensusAPI))
 }
@@ -290,20 +369,48 @@ func (n *Node) StartConsensusServer() error {
 // Returns when the node exits.
 func (n *Node) Dispatch() { n.EC.Dispatch() }
 
+// DispatchUntil starts the node's servers and stops them once [ctx] is done.
+// Returns when the node exits. If [ctx] is already done, returns immediately.
+// The event loop can only be stopped from within it, so it checks [ctx] every
+// [shutdownCheckInterval].
+func (n *Node) DispatchUntil(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	n.shutdownCtx = ctx
+	n.shutdownTimer = salticidae.NewTimerEvent(n.EC, salticidae.TimerEventCallback(C.onShutdownCheck), nil)
+	n.shutdownTimer.Add(shutdownCheckInterval.Seconds())
+	n.EC.Dispatch()
+}
+
 /*
  ******************************************************************************
  *********************** End P2P Networking Section ***************************
  ******************************************************************************
  */
 
+// initGenesis loads the genesis data of the Platform chain. If a genesis URL
+// is configured, the genesis data is fetched from it and must have the
+// configured hash. Otherwise, the genesis data of the network is used.
+func (n *Node) initGenesis() error {
+	if n.Config.GenesisURL == "" {
+		genesisBytes, err := genesis.Genesis(n.Config.NetworkID)
+		n.genesisBytes = genesisBytes
+		return err
+	}
+
+	n.Log.Info("fetching genesis from %s", n.Config.GenesisURL)
+	client := &http.Client{Timeout: genesisFetchTimeout}
+	genesisBytes, err := fetchGenesis(client, n.Config.GenesisURL, n.Config.GenesisHash)
+	n.genesisBytes = genesisBytes
+	return err
+}
+
 func (n *Node) initDatabase() error {
 	n.DB = n.Config.DB
 
-	expectedGenesis, err := genesis.Genesis(n.Config.NetworkID)
-	if err != nil {
-		return err
-	}
-	rawExpectedGenesisHash := hashing.ComputeHash256(expectedGenesis)
+	rawExpectedGenesisHash := hashing.ComputeHash256(n.genesisBytes)
 
 	rawGenesisHash, err := n.DB.Get(genesisHashKey)
 	if err == database.ErrNotFound {
@@ -345,12 +452,7 @@ func (n *Node) initNodeID() error {
 		return fmt.Errorf("problem reading staking certificate: %w", err)
 	}
 
-	block, _ := pem.Decode(stakeCert)
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return fmt.Errorf("problem parsing staking certificate: %w", err)
-	}
-	n.ID, err = ids.ToShortID(hashing.PubkeyBytesToAddress(cert.Raw))
+	n.ID, err = StakingCertNodeID(stakeCert)
 	if err != nil {
 		return fmt.Errorf("problem deriving staker ID from certificate: %w", err)
 	}
@@ -363,7 +465,7 @@ func (n *Node) initNodeID() error {
 // The Platform VM is registered in initStaking because
 // its factory needs to reference n.chainManager, which is nil right now
 func (n *Node) initVMManager() error {
-	avaAssetID, err := genesis.AVAAssetID(n.Config.NetworkID)
+	avaAssetID, err := genesis.AVAAssetIDFromBytes(n.genesisBytes)
 	if err != nil {
 		return err
 	}
@@ -379,7 +481,24 @@ func (n *Node) initVMManager() error {
 		n.vmManager.RegisterVMFactory(evm.ID, &evm.Factory{}),
 		n.vmManager.RegisterVMFactory(spdagvm.ID, &spdagvm.Factory{TxFee: n.Config.AvaTxFee}),
 		n.vmManager.RegisterVMFactory(spchainvm.ID, &spchainvm.Factory{}),
-		n.vmManager.RegisterVMFactory(timestampvm.ID, &timestampvm.Factory{}),
+		n.vmManager.RegisterVMFactory(timestampvm.ID, &timestampvm.Factory{
+			MinPeersForWrites:   n.Config.MinPeersForWrites,
+			DBEncryptionKey:     []byte(n.Config.TimestampDBEncryptionKey),
+			MaxMempoolBytes:     n.Config.TimestampMaxMempoolBytes,
+			SnapshotInterval:    n.Config.TimestampSnapshotInterval,
+			SnapshotDir:         n.Config.TimestampSnapshotDir,
+			ProposeRate:         n.Config.TimestampProposeRate,
+			ProposeBurst:        n.Config.TimestampProposeBurst,
+			MaxReorgDepth:       n.Config.TimestampMaxReorgDepth,
+			CodecName:           n.Config.TimestampCodecName,
+			MaxMempoolSize:      n.Config.TimestampMaxMempoolSize,
+			RequeueRejected:     n.Config.TimestampRequeueRejected,
+			CompactionThreshold: n.Config.TimestampCompactionThreshold,
+			EnableSearch:        n.Config.TimestampEnableSearch,
+			ProposeTimeout:      n.Config.TimestampProposeTimeout,
+			MaxFutureDrift:      n.Config.TimestampMaxFutureDrift,
+			MaxPageSize:         n.Config.TimestampMaxPageSize,
+		}),
 		n.vmManager.RegisterVMFactory(secp256k1fx.ID, &secp256k1fx.Factory{}),
 		n.vmManager.RegisterVMFactory(nftfx.ID, &nftfx.Factory{}),
 		n.vmManager.RegisterVMFactory(propertyfx.ID, &propertyfx.Factory{}),
@@ -415,11 +534,11 @@ func (n *Node) initChains() error {
 		vdrs.PutValidatorSet(platformvm.DefaultSubnetID, defaultSubnetValidators)
 	}
 
-	avaAssetID, err := genesis.AVAAssetID(n.Config.NetworkID)
+	avaAssetID, err := genesis.AVAAssetIDFromBytes(n.genesisBytes)
 	if err != nil {
 		return err
 	}
-	createAVMTx, err := genesis.VMGenesis(n.Config.NetworkID, avm.ID)
+	createAVMTx, err := genesis.VMGenesisFromBytes(n.genesisBytes, avm.ID)
 	if err != nil {
 		return err
 	}
@@ -443,16 +562,11 @@ func (n *Node) initChains() error {
 		beacons.Add(validators.NewValidator(peer.ID, 1))
 	}
 
-	genesisBytes, err := genesis.Genesis(n.Config.NetworkID)
-	if err != nil {
-		return err
-	}
-
 	// Create the Platform Chain
 	n.chainManager.ForceCreateChain(chains.ChainParameters{
 		ID:            ids.Empty,
 		SubnetID:      platformvm.DefaultSubnetID,
-		GenesisData:   genesisBytes, // Specifies other chains to create
+		GenesisData:   n.genesisBytes, // Specifies other chains to create
 		VMAlias:       platformvm.ID.String(),
 		CustomBeacons: beacons,
 	})
@@ -465,6 +579,15 @@ func (n *Node) initAPIServer() {
 	n.Log.Info("Initializing API server")
 
 	n.APIServer.Initialize(n.Log, n.LogFactory, n.Config.HTTPPort)
+	n.APIServer.SetRequestTimeout(n.Config.APIRequestTimeout)
+	n.APIServer.SetRequestLogging(n.Config.APIRequestLogLevel, n.Config.APIRequestLogJSON)
+	n.APIServer.SetSlowRequestThreshold(n.Config.SlowQueryThreshold)
+
+	// Don't serve API calls while running the self-test
+	if n.Config.SelfTest {
+		n.Log.Debug("Not dispatching API server during self-test")
+		return
+	}
 
 	if n.Config.EnableHTTPS {
 		n.Log.Debug("Initializing API server with TLS Enabled")
@@ -500,6 +623,8 @@ func (n *Node) initChainManager() {
 		&n.APIServer,
 		&n.keystoreServer,
 		&n.sharedMemory,
+		n.Config.Features,
+		n.ValidatorAPI.Connections(),
 	)
 
 	n.chainManager.AddRegistrant(&n.APIServer)
@@ -537,11 +662,34 @@ func (n *Node) initMetricsAPI() {
 
 // initAdminAPI initializes the Admin API service
 // Assumes n.log, n.chainManager, and n.ValidatorAPI already initialized
-func (n *Node) initAdminAPI() {
-	if n.Config.AdminAPIEnabled {
-		n.Log.Info("initializing Admin API")
-		service := admin.NewService(n.ID, n.Config.NetworkID, n.Log, n.chainManager, n.ValidatorAPI.Connections(), &n.APIServer)
-		n.APIServer.AddRoute(service, &sync.RWMutex{}, "admin", "", n.HTTPLog)
+func (n *Node) initAdminAPI() error {
+	if !n.Config.AdminAPIEnabled {
+		return nil
+	}
+	n.Log.Info("initializing Admin API")
+	var stakingCert *tls.Certificate
+	if n.Config.EnableStaking {
+		cert, err := tls.LoadX509KeyPair(n.Config.StakingCertFile, n.Config.StakingKeyFile)
+		if err != nil {
+			return fmt.Errorf("problem loading staking key: %w", err)
+		}
+		stakingCert = &cert
+	}
+	service := admin.NewService(n.ID, n.Config.NetworkID, n.Log, n.chainManager, n.ValidatorAPI.Connections(), n, stakingCert, &n.APIServer)
+	n.APIServer.AddRoute(service, &sync.RWMutex{}, "admin", "", n.HTTPLog)
+	return nil
+}
+
+// initHealthAPI initializes the Health API service
+// Assumes n.Log, n.chainManager, n.ValidatorAPI and n.ConsensusDispatcher
+// already initialized
+func (n *Node) initHealthAPI() {
+	if n.Config.HealthAPIEnabled {
+		n.Log.Info("initializing Health API")
+		checker := health.NewChecker(n.Config.HealthThresholds, n.ValidatorAPI.Connections(), n.chainManager.BootstrapStatus)
+		n.Log.AssertNoError(n.ConsensusDispatcher.Register("health", checker))
+		service := health.NewService(n.Log, checker)
+		n.APIServer.AddRoute(service, &sync.RWMutex{}, "health", "", n.HTTPLog)
 	}
 }
 
@@ -558,7 +706,7 @@ func (n *Node) initIPCAPI() {
 // Give chains and VMs aliases as specified by the genesis information
 func (n *Node) initAliases() error {
 	n.Log.Info("initializing aliases")
-	defaultAliases, chainAliases, vmAliases, err := genesis.Aliases(n.Config.NetworkID)
+	defaultAliases, chainAliases, vmAliases, err := genesis.AliasesFromBytes(n.genesisBytes)
 	if err != nil {
 		return err
 	}
@@ -599,6 +747,10 @@ func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logg
 	}
 	n.HTTPLog = httpLog
 
+	if err := n.initGenesis(); err != nil { // Load the Platform chain's genesis
+		return fmt.Errorf("problem loading genesis: %w", err)
+	}
+
 	if err := n.initDatabase(); err != nil { // Set up the node's database
 		return fmt.Errorf("problem initializing database: %w", err)
 	}
@@ -630,13 +782,19 @@ func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logg
 	n.initChainManager()    // Set up the chain manager
 	n.initConsensusNet()    // Set up the main consensus network
 
+	n.initBeaconReconnector() // Keep trying to reach the beacons
+	n.initBootstrapTimeout()  // Don't wait forever for bootstrapping
+
 	// TODO: Remove once API is fully featured for throughput tests
 	if n.Config.ThroughputServerEnabled {
 		n.initClients() // Set up the client servers
 	}
 
-	n.initAdminAPI() // Start the Admin API
-	n.initIPCAPI()   // Start the IPC API
+	if err := n.initAdminAPI(); err != nil { // Start the Admin API
+		return fmt.Errorf("problem initializing Admin API: %w", err)
+	}
+	n.initHealthAPI() // Start the Health API
+	n.initIPCAPI()    // Start the IPC API
 
 	if err := n.initAliases(); err != nil { // Set up aliases
 		return err
@@ -644,9 +802,42 @@ func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logg
 	return n.initChains() // Start the Platform chain
 }
 
+// SelfTest initializes this node, including its database, VMs and the
+// Platform chain, without starting the consensus server. The node is then shut
+// down. Returns nil iff every subsystem was initialized successfully.
+func (n *Node) SelfTest(Config *Config, logger logging.Logger, logFactory logging.Factory) error {
+	Config.SelfTest = true
+	if err := n.Initialize(Config, logger, logFactory); err != nil {
+		return err
+	}
+	defer n.Shutdown()
+
+	// Chain creation errors are only logged, so make sure the Platform chain
+	// actually exists
+	if _, err := n.chainManager.Lookup(ids.Empty.String()); err != nil {
+		return errPlatformChainNotCreated
+	}
+	return nil
+}
+
+// Drain stops accepting new API requests, waits up to the drain timeout for
+// in-flight requests to finish, and then shuts down the node. Networking is
+// shut down before the chains, so the block being processed is finished but
+// no new blocks are received.
+func (n *Node) Drain() {
+	n.Log.Info("draining the node")
+	if err := n.APIServer.Drain(n.Config.DrainTimeout); err != nil {
+		n.Log.Warn("shutting down with API requests in flight: %s", err)
+	}
+	n.Shutdown()
+}
+
 // Shutdown this node
 func (n *Node) Shutdown() {
 	n.Log.Info("shutting down the node")
+	if n.beaconReconnector != nil {
+		n.beaconReconnector.Stop()
+	}
 	n.ValidatorAPI.Shutdown()
 	n.ConsensusAPI.Shutdown()
 	n.chainManager.Shutdown()
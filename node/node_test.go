@@ -0,0 +1,66 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/leveldb"
+	"github.com/ava-labs/gecko/genesis"
+	"github.com/ava-labs/gecko/snow/networking/router"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestSelfTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gecko-selftest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.New(path.Join(dir, "db"), 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	config := &Config{
+		NetworkID:    genesis.LocalID,
+		EnableCrypto: true,
+		DB:           db,
+		StakingIP: utils.IPDesc{
+			IP:   net.IPv6loopback,
+			Port: 9651,
+		},
+		HTTPPort:        9650,
+		ConsensusRouter: &router.ChainRouter{},
+	}
+	config.ConsensusParams.K = 1
+	config.ConsensusParams.Alpha = 1
+	config.ConsensusParams.BetaVirtuous = 1
+	config.ConsensusParams.BetaRogue = 2
+	config.ConsensusParams.Parents = 2
+	config.ConsensusParams.BatchSize = 1
+	config.ConsensusParams.ConcurrentRepolls = 1
+
+	n := Node{}
+	if err := n.SelfTest(config, logging.NoLog{}, logging.NoFactory{}); err != nil {
+		t.Fatalf("self-test failed: %s", err)
+	}
+	if !config.SelfTest {
+		t.Fatalf("self-test should have been recorded in the config")
+	}
+
+	// The genesis should have been committed to the temp database
+	if has, err := db.Has(genesisHashKey); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("self-test should have initialized the database")
+	}
+}
@@ -4,6 +4,9 @@
 package node
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils"
 )
@@ -14,4 +17,30 @@ type Peer struct {
 	IP utils.IPDesc
 	// ID of the peer that can be verified during a handshake
 	ID ids.ShortID
+	// LastSeen is when a message was last received from the peer. It's zero
+	// if the peer has never been seen.
+	LastSeen time.Time
+	// Version the peer reported during the handshake. It's empty if the
+	// handshake hasn't finished.
+	Version string
+}
+
+// IsStale returns true if the peer hasn't been seen within [timeout]
+func (p *Peer) IsStale(timeout time.Duration) bool { return p.isStale(time.Now(), timeout) }
+
+// isStale returns true if the peer hasn't been seen within [timeout] of [now].
+// A peer seen exactly [timeout] ago isn't stale. A peer that has never been
+// seen is always stale.
+func (p *Peer) isStale(now time.Time, timeout time.Duration) bool {
+	return p.LastSeen.IsZero() || now.Sub(p.LastSeen) > timeout
 }
+
+// String returns the peer formatted as <ID>@<IP>:<port>
+func (p Peer) String() string { return fmt.Sprintf("%s@%s", p.ID, p.IP) }
+
+// Equals returns true if [other] is the same node at the same address
+func (p Peer) Equals(other Peer) bool { return p.SameNode(other) && p.IP.Equal(other.IP) }
+
+// SameNode returns true if [other] is the same node, which may have reconnected
+// from a different address
+func (p Peer) SameNode(other Peer) bool { return p.ID.Equals(other.ID) }
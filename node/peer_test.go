@@ -0,0 +1,141 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+)
+
+func TestPeerIsStale(t *testing.T) {
+	now := time.Unix(1000, 0)
+	timeout := time.Minute
+	tests := []struct {
+		lastSeen time.Time
+		stale    bool
+	}{
+		{lastSeen: now, stale: false},
+		{lastSeen: now.Add(-timeout + time.Nanosecond), stale: false},
+		{lastSeen: now.Add(-timeout), stale: false},
+		{lastSeen: now.Add(-timeout - time.Nanosecond), stale: true},
+		{lastSeen: time.Time{}, stale: true},
+	}
+	for _, test := range tests {
+		peer := Peer{LastSeen: test.lastSeen}
+		if stale := peer.isStale(now, timeout); stale != test.stale {
+			t.Fatalf("peer last seen at %s should have had staleness %t at %s", test.lastSeen, test.stale, now)
+		}
+	}
+
+	peer := Peer{LastSeen: time.Now()}
+	if peer.IsStale(time.Hour) {
+		t.Fatal("a peer that was just seen shouldn't be stale")
+	}
+}
+
+func TestPeerJSON(t *testing.T) {
+	peer := Peer{
+		IP:       utils.IPDesc{IP: []byte{127, 0, 0, 1}, Port: 9651},
+		ID:       ids.NewShortID([20]byte{1}),
+		LastSeen: time.Unix(1000, 0).UTC(),
+		Version:  "avalanche/0.5.7",
+	}
+	peerJSON, err := json.Marshal(&peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := Peer{}
+	if err := json.Unmarshal(peerJSON, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.LastSeen.Equal(peer.LastSeen) || parsed.Version != peer.Version {
+		t.Fatalf("peer should have been %+v but was %+v", peer, parsed)
+	}
+}
+
+func TestPeerString(t *testing.T) {
+	id := ids.NewShortID([20]byte{1})
+	tests := []struct {
+		peer     Peer
+		expected string
+	}{
+		{
+			peer:     Peer{IP: utils.IPDesc{IP: []byte{127, 0, 0, 1}, Port: 9651}, ID: id},
+			expected: id.String() + "@127.0.0.1:9651",
+		},
+		{
+			peer:     Peer{IP: utils.IPDesc{IP: net.IPv6loopback, Port: 9651}, ID: id},
+			expected: id.String() + "@[::1]:9651",
+		},
+		{
+			peer:     Peer{IP: utils.IPDesc{IP: []byte{10, 0, 0, 2}, Port: 0}},
+			expected: "nil@10.0.0.2:0",
+		},
+	}
+	for _, test := range tests {
+		if str := test.peer.String(); str != test.expected {
+			t.Fatalf("peer should have been formatted as %q but was %q", test.expected, str)
+		}
+	}
+}
+
+func TestPeerEquals(t *testing.T) {
+	id := ids.NewShortID([20]byte{1})
+	peer := Peer{IP: utils.IPDesc{IP: []byte{127, 0, 0, 1}, Port: 9651}, ID: id}
+	tests := []struct {
+		name     string
+		other    Peer
+		equals   bool
+		sameNode bool
+	}{
+		{
+			name:     "same",
+			other:    Peer{IP: utils.IPDesc{IP: []byte{127, 0, 0, 1}, Port: 9651}, ID: ids.NewShortID([20]byte{1})},
+			equals:   true,
+			sameNode: true,
+		},
+		{
+			name:     "new IP",
+			other:    Peer{IP: utils.IPDesc{IP: []byte{10, 0, 0, 2}, Port: 9651}, ID: id},
+			equals:   false,
+			sameNode: true,
+		},
+		{
+			name:     "new port",
+			other:    Peer{IP: utils.IPDesc{IP: []byte{127, 0, 0, 1}, Port: 9652}, ID: id},
+			equals:   false,
+			sameNode: true,
+		},
+		{
+			name:     "different node",
+			other:    Peer{IP: utils.IPDesc{IP: []byte{127, 0, 0, 1}, Port: 9651}, ID: ids.NewShortID([20]byte{2})},
+			equals:   false,
+			sameNode: false,
+		},
+		{
+			name:     "no ID",
+			other:    Peer{IP: utils.IPDesc{IP: []byte{127, 0, 0, 1}, Port: 9651}},
+			equals:   false,
+			sameNode: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if equals := peer.Equals(test.other); equals != test.equals {
+				t.Fatalf("Equals returned %t, expected %t", equals, test.equals)
+			}
+			if equals := test.other.Equals(peer); equals != test.equals {
+				t.Fatalf("Equals isn't symmetric")
+			}
+			if sameNode := peer.SameNode(test.other); sameNode != test.sameNode {
+				t.Fatalf("SameNode returned %t, expected %t", sameNode, test.sameNode)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+const (
+	// StagedKeySuffix is appended to the paths of the staking key and
+	// certificate to get the paths a rotated key and certificate are staged at
+	StagedKeySuffix = ".new"
+	// BackupKeySuffix is appended to the paths of the staking key and
+	// certificate to get the paths they are moved to when replaced
+	BackupKeySuffix = ".old"
+
+	// Staking certificates are valid for as many days as those made by
+	// keys/genStaker.sh
+	stakingCertValidityDays = 365250
+)
+
+// stakingKeyBits is the size of generated staking keys
+var stakingKeyBits = 4096
+
+var errNoCertPEM = errors.New("staking certificate isn't PEM encoded")
+
+// NewStakingKey returns a new PEM encoded RSA private key and self-signed
+// certificate for staking connections
+func NewStakingKey() ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, stakingKeyBits)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't generate staking key: %w", err)
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't generate certificate serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Country:      []string{"US"},
+			Province:     []string{"NY"},
+			Organization: []string{"Avalabs"},
+			CommonName:   "ava",
+		},
+		NotBefore:             now,
+		NotAfter:              now.AddDate(0, 0, stakingCertValidityDays),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't create staking certificate: %w", err)
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})
+	return keyPEM, certPEM, nil
+}
+
+// StakingCertNodeID returns the ID of the node whose staking certificate is
+// [certPEM]
+func StakingCertNodeID(certPEM []byte) (ids.ShortID, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return ids.ShortID{}, errNoCertPEM
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ids.ShortID{}, fmt.Errorf("problem parsing staking certificate: %w", err)
+	}
+	return ids.ToShortID(hashing.PubkeyBytesToAddress(cert.Raw))
+}
+
+// StageStakingKey generates a new staking key and certificate, and writes them
+// next to [keyFile] and [certFile] with StagedKeySuffix appended. The current
+// key and certificate aren't modified. The staged pair is verified to load
+// before the ID of the node using it is returned. Fails if a key is already
+// staged.
+func StageStakingKey(keyFile, certFile string) (ids.ShortID, error) {
+	keyPEM, certPEM, err := NewStakingKey()
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+
+	stagedKeyFile := keyFile + StagedKeySuffix
+	stagedCertFile := certFile + StagedKeySuffix
+	if err := writeNewFile(stagedKeyFile, keyPEM, 0600); err != nil {
+		return ids.ShortID{}, err
+	}
+	if err := writeNewFile(stagedCertFile, certPEM, 0644); err != nil {
+		os.Remove(stagedKeyFile)
+		return ids.ShortID{}, err
+	}
+	return verifyStakingKey(stagedKeyFile, stagedCertFile)
+}
+
+// AdoptStakingKey replaces the staking key and certificate at [keyFile] and
+// [certFile] with the ones staged by StageStakingKey. The staged pair is
+// verified to load before anything is replaced. The replaced files are kept
+// with BackupKeySuffix appended. Fails if a backup already exists, so that
+// the backup of an earlier key is never overwritten. Returns the ID of the
+// node using the new key.
+func AdoptStakingKey(keyFile, certFile string) (ids.ShortID, error) {
+	stagedKeyFile := keyFile + StagedKeySuffix
+	stagedCertFile := certFile + StagedKeySuffix
+	nodeID, err := verifyStakingKey(stagedKeyFile, stagedCertFile)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	for _, backupFile := range []string{keyFile + BackupKeySuffix, certFile + BackupKeySuffix} {
+		if _, err := os.Lstat(backupFile); err == nil {
+			return ids.ShortID{}, fmt.Errorf("%s already exists, move it before adopting another key", backupFile)
+		} else if !os.IsNotExist(err) {
+			return ids.ShortID{}, err
+		}
+	}
+
+	// Back up both current files before moving either staged file, so that a
+	// failure never leaves a key paired with the wrong certificate
+	if err := os.Rename(keyFile, keyFile+BackupKeySuffix); err != nil {
+		return ids.ShortID{}, err
+	}
+	if err := os.Rename(certFile, certFile+BackupKeySuffix); err != nil {
+		os.Rename(keyFile+BackupKeySuffix, keyFile)
+		return ids.ShortID{}, err
+	}
+	if err := os.Rename(stagedKeyFile, keyFile); err != nil {
+		restoreStakingKey(keyFile, certFile)
+		return ids.ShortID{}, err
+	}
+	if err := os.Rename(stagedCertFile, certFile); err != nil {
+		os.Rename(keyFile, stagedKeyFile)
+		restoreStakingKey(keyFile, certFile)
+		return ids.ShortID{}, err
+	}
+	return nodeID, nil
+}
+
+// restoreStakingKey moves the backed up key and certificate back into place
+func restoreStakingKey(keyFile, certFile string) {
+	os.Rename(keyFile+BackupKeySuffix, keyFile)
+	os.Rename(certFile+BackupKeySuffix, certFile)
+}
+
+// verifyStakingKey returns the ID of the node using the staking key and
+// certificate at [keyFile] and [certFile] if they can be loaded as a TLS key
+// pair
+func verifyStakingKey(keyFile, certFile string) (ids.ShortID, error) {
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		return ids.ShortID{}, fmt.Errorf("staking key doesn't load: %w", err)
+	}
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return StakingCertNodeID(certPEM)
+}
+
+// writeNewFile writes [data] to the file at [path], which must not exist
+func writeNewFile(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,138 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateStakingKey(t *testing.T) {
+	// Smaller keys are much faster to generate
+	defer func(bits int) { stakingKeyBits = bits }(stakingKeyBits)
+	stakingKeyBits = 2048
+
+	dir, err := ioutil.TempDir("", "staking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, "staker.key")
+	certFile := filepath.Join(dir, "staker.crt")
+	oldKey, oldCert, err := NewStakingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, oldKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, oldCert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldID, err := StakingCertNodeID(oldCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Staging doesn't touch the current key
+	stagedID, err := StageStakingKey(keyFile, certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stagedID.Equals(oldID) {
+		t.Fatal("Rotated key should have a new node ID")
+	}
+	if currentKey, err := ioutil.ReadFile(keyFile); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(currentKey, oldKey) {
+		t.Fatal("Staging should have left the current key in place")
+	}
+	if _, err := StageStakingKey(keyFile, certFile); err == nil {
+		t.Fatal("Should have refused to overwrite the staged key")
+	}
+
+	adoptedID, err := AdoptStakingKey(keyFile, certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !adoptedID.Equals(stagedID) {
+		t.Fatalf("Adopted node ID %s should have been the staged node ID %s", adoptedID, stagedID)
+	}
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		t.Fatalf("Adopted key should have loaded: %s", err)
+	}
+	if backupKey, err := ioutil.ReadFile(keyFile + BackupKeySuffix); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(backupKey, oldKey) {
+		t.Fatal("Adopting should have backed up the old key")
+	}
+	if _, err := os.Stat(keyFile + StagedKeySuffix); !os.IsNotExist(err) {
+		t.Fatal("Adopting should have consumed the staged key")
+	}
+
+	// A second rotation doesn't overwrite the backup of the original key
+	if _, err := StageStakingKey(keyFile, certFile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AdoptStakingKey(keyFile, certFile); err == nil {
+		t.Fatal("Should have refused to overwrite the backed up key")
+	}
+	if backupKey, err := ioutil.ReadFile(keyFile + BackupKeySuffix); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(backupKey, oldKey) {
+		t.Fatal("The backup of the original key should have been kept")
+	}
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		t.Fatalf("The adopted key should have been kept: %s", err)
+	}
+}
+
+func TestAdoptInvalidStakingKey(t *testing.T) {
+	defer func(bits int) { stakingKeyBits = bits }(stakingKeyBits)
+	stakingKeyBits = 2048
+
+	dir, err := ioutil.TempDir("", "staking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, "staker.key")
+	certFile := filepath.Join(dir, "staker.crt")
+	oldKey, oldCert, err := NewStakingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, oldKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, oldCert, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A staged key that doesn't match its certificate isn't adopted
+	newKey, _, err := NewStakingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile+StagedKeySuffix, newKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile+StagedKeySuffix, oldCert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AdoptStakingKey(keyFile, certFile); err == nil {
+		t.Fatal("Should have refused to adopt a mismatched key")
+	}
+	if currentKey, err := ioutil.ReadFile(keyFile); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(currentKey, oldKey) {
+		t.Fatal("Failed adoption should have left the current key in place")
+	}
+}
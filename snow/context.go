@@ -36,10 +36,18 @@ type AliasLookup interface {
 	PrimaryAlias(id ids.ID) (string, error)
 }
 
+// PeerSet is the set of peers this node is connected to
+type PeerSet interface {
+	// Len returns the number of connected peers
+	Len() int
+}
+
 // Context is information about the current execution.
 // [NetworkID] is the ID of the network this context exists within.
 // [ChainID] is the ID of the chain this context exists within.
 // [NodeID] is the ID of this node
+// [Features] are the feature flags enabled on this node
+// [Peers] are the peers this node is connected to
 type Context struct {
 	NetworkID           uint32
 	ChainID             ids.ID
@@ -52,8 +60,13 @@ type Context struct {
 	Keystore            Keystore
 	SharedMemory        SharedMemory
 	BCLookup            AliasLookup
+	Features            map[string]bool
+	Peers               PeerSet
 }
 
+// Feature returns true iff the feature flag [name] is enabled
+func (ctx *Context) Feature(name string) bool { return ctx.Features[name] }
+
 // DefaultContextTest ...
 func DefaultContextTest() *Context {
 	decisionED := triggers.EventDispatcher{}
@@ -26,16 +26,33 @@ type Bootstrapper struct {
 // Initialize implements the Engine interface.
 func (b *Bootstrapper) Initialize(config Config) {
 	b.Config = config
+	b.reset()
+}
 
+// reset the progress of bootstrapping, so that every beacon is asked for its
+// accepted frontier again
+func (b *Bootstrapper) reset() {
+	b.pendingAcceptedFrontier.Clear()
+	b.pendingAccepted.Clear()
 	for _, vdr := range b.Beacons.List() {
 		vdrID := vdr.ID()
 		b.pendingAcceptedFrontier.Add(vdrID)
 		b.pendingAccepted.Add(vdrID)
 	}
 
+	b.acceptedFrontier.Clear()
 	b.acceptedVotes = make(map[[32]byte]uint64)
 }
 
+// Restart bootstrapping from scratch by asking every beacon for its accepted
+// frontier again. Responses to the requests sent before the restart are
+// dropped.
+func (b *Bootstrapper) Restart() {
+	b.Context.Log.Info("Restarting bootstrapping")
+	b.reset()
+	b.Startup()
+}
+
 // Startup implements the Engine interface.
 func (b *Bootstrapper) Startup() {
 	if b.pendingAcceptedFrontier.Len() == 0 {
@@ -63,7 +80,7 @@ func (b *Bootstrapper) GetAcceptedFrontierFailed(validatorID ids.ShortID, reques
 
 // AcceptedFrontier implements the Engine interface.
 func (b *Bootstrapper) AcceptedFrontier(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
-	if !b.pendingAcceptedFrontier.Contains(validatorID) {
+	if requestID != b.RequestID || !b.pendingAcceptedFrontier.Contains(validatorID) {
 		b.Context.Log.Debug("Received an AcceptedFrontier message from %s unexpectedly", validatorID)
 		return
 	}
@@ -92,7 +109,7 @@ func (b *Bootstrapper) GetAcceptedFailed(validatorID ids.ShortID, requestID uint
 
 // Accepted implements the Engine interface.
 func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
-	if !b.pendingAccepted.Contains(validatorID) {
+	if requestID != b.RequestID || !b.pendingAccepted.Contains(validatorID) {
 		b.Context.Log.Debug("Received an Accepted message from %s unexpectedly", validatorID)
 		return
 	}
@@ -21,4 +21,7 @@ const (
 type HTTPHandler struct {
 	LockOptions LockOption
 	Handler     http.Handler
+	// Methods are the HTTP methods the handler serves, such as "GET". If
+	// empty, every method is served.
+	Methods []string
 }
@@ -56,6 +56,14 @@ type VM interface {
 	CreateHandlers() map[string]*HTTPHandler
 }
 
+// BootstrapListener is an optional interface that a VM can implement to be
+// notified when its chain has finished bootstrapping
+type BootstrapListener interface {
+	// Bootstrapped is called once the chain has finished bootstrapping and
+	// consensus has started. The context lock is held during this call.
+	Bootstrapped()
+}
+
 // StaticVM describes the functionality that allows a user to interact with a VM
 // statically.
 type StaticVM interface {
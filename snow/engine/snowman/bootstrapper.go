@@ -171,6 +171,14 @@ func (b *bootstrapper) storeBlock(blk snowman.Block) {
 	b.numPendingRequests.Set(float64(numPending))
 }
 
+// Restart bootstrapping from scratch, unless it has finished
+func (b *bootstrapper) Restart() {
+	if b.finished {
+		return
+	}
+	b.Bootstrapper.Restart()
+}
+
 func (b *bootstrapper) finish() {
 	if b.finished {
 		return
@@ -476,3 +476,44 @@ func TestBootstrapperPartialFetch(t *testing.T) {
 		t.Fatalf("wrong number pending")
 	}
 }
+
+func TestBootstrapperRestart(t *testing.T) {
+	config, peerID, sender, _ := newConfig(t)
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	requestIDs := []uint32(nil)
+	sender.GetAcceptedFrontierF = func(vdrs ids.ShortSet, requestID uint32) {
+		if !vdrs.Contains(peerID) {
+			t.Fatalf("Should have requested the accepted frontier from %s", peerID)
+		}
+		requestIDs = append(requestIDs, requestID)
+	}
+
+	// Every beacon is asked for its accepted frontier again
+	bs.Startup()
+	bs.Restart()
+	if len(requestIDs) != 2 || requestIDs[0] == requestIDs[1] {
+		t.Fatalf("Should have requested the accepted frontier again with a new request ID but sent %v", requestIDs)
+	}
+
+	// A response to the request sent before the restart is dropped
+	bs.AcceptedFrontier(peerID, requestIDs[0], ids.Set{})
+
+	// The response to the new request moves bootstrapping on
+	requestedAccepted := false
+	sender.GetAcceptedF = func(ids.ShortSet, uint32, ids.Set) { requestedAccepted = true }
+	bs.AcceptedFrontier(peerID, requestIDs[1], ids.Set{})
+	if !requestedAccepted {
+		t.Fatalf("Should have requested the accepted containers")
+	}
+
+	// Finished bootstrapping isn't restarted
+	bs.finished = true
+	bs.Restart()
+	if len(requestIDs) != 2 {
+		t.Fatalf("Shouldn't have restarted after bootstrapping finished")
+	}
+}
@@ -0,0 +1,68 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errInvalidEnvelopeSig = errors.New("envelope signature is invalid")
+	errEnvelopeLength     = errors.New("envelope payload doesn't match its length")
+)
+
+// PackSignedEnvelope appends an envelope to [p] containing the bytes packed by
+// [payload] followed by [signer]'s signature over the hash of those bytes.
+// The payload is packed in place, so its offsets and errors are those of [p].
+// Since the wrappers package can't depend on this one, this is a function
+// rather than a method of the packer.
+func PackSignedEnvelope(p *wrappers.Packer, payload func(*wrappers.Packer), signer PrivateKey) {
+	lenOffset := p.Reserve(wrappers.IntLen)
+	start := p.Offset
+	payload(p)
+	if p.Errored() {
+		return
+	}
+	payloadBytes := p.Bytes[start:p.Offset]
+	sig, err := signer.SignHash(hashing.ComputeHash256(payloadBytes))
+	if err != nil {
+		p.Add(err)
+		return
+	}
+	size := [wrappers.IntLen]byte{}
+	binary.BigEndian.PutUint32(size[:], uint32(len(payloadBytes)))
+	p.WriteAt(lenOffset, size[:])
+	p.PackBytes(sig)
+}
+
+// UnpackSignedEnvelope unpacks an envelope packed by PackSignedEnvelope from
+// [p], unpacking its payload in place with [payload]. Returns an error if the
+// payload wasn't signed by [pubKey], in which case the values unpacked by
+// [payload] must be discarded. If [EnableCrypto] is false, the signature isn't
+// verified.
+func UnpackSignedEnvelope(p *wrappers.Packer, pubKey PublicKey, payload func(*wrappers.Packer)) error {
+	size := p.UnpackInt()
+	p.CheckSpace(int(size))
+	if p.Errored() {
+		return p.Err
+	}
+	start := p.Offset
+	payload(p)
+	if !p.Errored() && p.Offset-start != int(size) {
+		p.Add(errEnvelopeLength)
+	}
+	sig := p.UnpackBytes()
+	if p.Errored() {
+		return p.Err
+	}
+	if EnableCrypto && !pubKey.VerifyHash(hashing.ComputeHash256(p.Bytes[start:start+int(size)]), sig) {
+		p.Add(errInvalidEnvelopeSig)
+		return p.Err
+	}
+	return nil
+}
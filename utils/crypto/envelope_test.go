@@ -0,0 +1,180 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+func packTestEnvelope(t *testing.T, signer PrivateKey) []byte {
+	p := wrappers.Packer{MaxSize: 1024}
+	PackSignedEnvelope(&p, func(p *wrappers.Packer) {
+		p.PackStr("hello")
+		p.PackLong(42)
+	}, signer)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	return p.Bytes
+}
+
+// unpackTestPayload unpacks the payload packed by packTestEnvelope
+func unpackTestPayload(str *string, val *uint64) func(*wrappers.Packer) {
+	return func(p *wrappers.Packer) {
+		*str = p.UnpackStr()
+		*val = p.UnpackLong()
+	}
+}
+
+func TestSignedEnvelope(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := packTestEnvelope(t, key)
+
+	p := wrappers.Packer{Bytes: envelope}
+	var (
+		str string
+		val uint64
+	)
+	if err := UnpackSignedEnvelope(&p, key.PublicKey(), unpackTestPayload(&str, &val)); err != nil {
+		t.Fatal(err)
+	}
+	if p.Offset != len(p.Bytes) {
+		t.Fatalf("UnpackSignedEnvelope left %d unread bytes", len(p.Bytes)-p.Offset)
+	}
+	if str != "hello" {
+		t.Fatalf("Unpacked %q, expected %q", str, "hello")
+	}
+	if val != 42 {
+		t.Fatalf("Unpacked %d, expected %d", val, 42)
+	}
+}
+
+func TestSignedEnvelopeOuterOffset(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The envelope follows another field, and its payload sees the offsets of
+	// the outer packer
+	p := wrappers.Packer{MaxSize: 1024}
+	p.PackInt(7)
+	payloadOffset := 0
+	PackSignedEnvelope(&p, func(p *wrappers.Packer) {
+		payloadOffset = p.Offset
+		p.PackStr("hello")
+		p.PackLong(42)
+	}, key)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if expected := 2 * wrappers.IntLen; payloadOffset != expected {
+		t.Fatalf("payload was packed at offset %d, expected %d", payloadOffset, expected)
+	}
+
+	unpacker := wrappers.Packer{Bytes: p.Bytes}
+	if unpacker.UnpackInt() != 7 {
+		t.Fatal("wrong leading field")
+	}
+	var (
+		str string
+		val uint64
+	)
+	if err := UnpackSignedEnvelope(&unpacker, key.PublicKey(), unpackTestPayload(&str, &val)); err != nil {
+		t.Fatal(err)
+	}
+	if str != "hello" || val != 42 {
+		t.Fatalf("Unpacked %q and %d", str, val)
+	}
+
+	// A payload that reads past its length fails
+	unpacker = wrappers.Packer{Bytes: p.Bytes, Offset: wrappers.IntLen}
+	err = UnpackSignedEnvelope(&unpacker, key.PublicKey(), func(p *wrappers.Packer) {
+		unpackTestPayload(&str, &val)(p)
+		p.UnpackByte()
+	})
+	if err != errEnvelopeLength {
+		t.Fatalf("UnpackSignedEnvelope should have failed with %s but returned %v", errEnvelopeLength, err)
+	}
+}
+
+func TestSignedEnvelopeTampered(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := packTestEnvelope(t, key)
+	// Flip a bit of the payload, which starts after its length prefix
+	envelope[wrappers.IntLen+wrappers.ShortLen] ^= 1
+
+	var (
+		str string
+		val uint64
+	)
+	p := wrappers.Packer{Bytes: envelope}
+	if err := UnpackSignedEnvelope(&p, key.PublicKey(), unpackTestPayload(&str, &val)); err == nil {
+		t.Fatalf("Should have failed to verify a tampered payload")
+	}
+
+	otherKey, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = wrappers.Packer{Bytes: packTestEnvelope(t, key)}
+	if err := UnpackSignedEnvelope(&p, otherKey.PublicKey(), unpackTestPayload(&str, &val)); err == nil {
+		t.Fatalf("Should have failed to verify with a different key")
+	}
+}
+
+func TestSignedEnvelopeCryptoDisabled(t *testing.T) {
+	EnableCrypto = false
+	defer func() { EnableCrypto = true }()
+
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := packTestEnvelope(t, key)
+	envelope[wrappers.IntLen+wrappers.ShortLen] ^= 1
+
+	p := wrappers.Packer{Bytes: envelope}
+	var (
+		str string
+		val uint64
+	)
+	if err := UnpackSignedEnvelope(&p, key.PublicKey(), unpackTestPayload(&str, &val)); err != nil {
+		t.Fatal(err)
+	}
+	// The first byte of the string was flipped
+	if str != "iello" || val != 42 {
+		t.Fatalf("Returned the wrong payload %q, %d", str, val)
+	}
+}
+
+func TestUnpackSignedEnvelopeTruncated(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := packTestEnvelope(t, key)
+
+	p := wrappers.Packer{Bytes: envelope[:len(envelope)-1]}
+	var (
+		str string
+		val uint64
+	)
+	if err := UnpackSignedEnvelope(&p, key.PublicKey(), unpackTestPayload(&str, &val)); err == nil {
+		t.Fatalf("Should have failed to unpack a truncated envelope")
+	}
+}
@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errEmptyVoteID    = errors.New("vote has an empty block ID")
+	errInvalidVoteSig = errors.New("vote signature is invalid")
+)
+
+// Vote is a signed preference for, or against, a block
+type Vote struct {
+	BlockID   ids.ID
+	Preferred bool
+	Sig       []byte
+}
+
+// voteHash returns the hash that is signed to vote on [blockID]
+func voteHash(blockID ids.ID, preferred bool) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, hashing.HashLen+wrappers.BoolLen)}
+	p.PackFixedBytes(blockID.Bytes())
+	p.PackBool(preferred)
+	return hashing.ComputeHash256(p.Bytes)
+}
+
+// SignVote returns [signer]'s signature of a vote on [blockID]
+func SignVote(signer PrivateKey, blockID ids.ID, preferred bool) ([]byte, error) {
+	if blockID.IsZero() {
+		return nil, errEmptyVoteID
+	}
+	return signer.SignHash(voteHash(blockID, preferred))
+}
+
+// PackVote appends a vote on [blockID] with signature [sig] to [p]. Since the
+// wrappers package can't depend on the ids package, this is a function rather
+// than a method of the packer.
+func PackVote(p *wrappers.Packer, blockID ids.ID, preferred bool, sig []byte) {
+	if blockID.IsZero() {
+		p.Add(errEmptyVoteID)
+		return
+	}
+	p.PackFixedBytes(blockID.Bytes())
+	p.PackBool(preferred)
+	p.PackBytes(sig)
+}
+
+// UnpackVote unpacks a vote packed by PackVote from [p]. The signature isn't
+// verified, see Vote.Verify.
+func UnpackVote(p *wrappers.Packer) Vote {
+	blockIDBytes := p.UnpackFixedBytes(hashing.HashLen)
+	preferred := p.UnpackBool()
+	sig := p.UnpackBytes()
+	if p.Errored() {
+		return Vote{}
+	}
+	blockID, err := ids.ToID(blockIDBytes)
+	if err != nil {
+		p.Add(err)
+		return Vote{}
+	}
+	return Vote{
+		BlockID:   blockID,
+		Preferred: preferred,
+		Sig:       sig,
+	}
+}
+
+// Verify returns an error if the vote wasn't signed by [pubKey]. If
+// [EnableCrypto] is false, the signature isn't verified.
+func (v *Vote) Verify(pubKey PublicKey) error {
+	if v.BlockID.IsZero() {
+		return errEmptyVoteID
+	}
+	if EnableCrypto && !pubKey.VerifyHash(voteHash(v.BlockID, v.Preferred), v.Sig) {
+		return errInvalidVoteSig
+	}
+	return nil
+}
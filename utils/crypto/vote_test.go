@@ -0,0 +1,118 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+func packTestVote(t *testing.T, signer PrivateKey, blockID ids.ID, preferred bool) []byte {
+	sig, err := SignVote(signer, blockID, preferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := wrappers.Packer{MaxSize: 1024}
+	PackVote(&p, blockID, preferred, sig)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	return p.Bytes
+}
+
+func TestVote(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID := ids.NewID(hashing.ComputeHash256Array([]byte("block")))
+
+	p := wrappers.Packer{Bytes: packTestVote(t, key, blockID, true)}
+	vote := UnpackVote(&p)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if p.Remaining() != 0 {
+		t.Fatalf("UnpackVote left %d unread bytes", p.Remaining())
+	}
+	if !vote.BlockID.Equals(blockID) || !vote.Preferred {
+		t.Fatalf("Unpacked a vote on (%s, %v), expected (%s, %v)", vote.BlockID, vote.Preferred, blockID, true)
+	}
+	if err := vote.Verify(key.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vote.Verify(otherKey.PublicKey()); err != errInvalidVoteSig {
+		t.Fatalf("Should have failed to verify with a different key but returned %v", err)
+	}
+}
+
+func TestVoteTamperedPreference(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID := ids.NewID(hashing.ComputeHash256Array([]byte("block")))
+	vote := packTestVote(t, key, blockID, true)
+	// Flip the preference, which follows the block ID
+	vote[hashing.HashLen] = 0
+
+	p := wrappers.Packer{Bytes: vote}
+	tampered := UnpackVote(&p)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if tampered.Preferred {
+		t.Fatal("Should have unpacked the tampered preference")
+	}
+	if err := tampered.Verify(key.PublicKey()); err != errInvalidVoteSig {
+		t.Fatalf("Should have failed to verify a tampered preference but returned %v", err)
+	}
+
+	EnableCrypto = false
+	defer func() { EnableCrypto = true }()
+	if err := tampered.Verify(key.PublicKey()); err != nil {
+		t.Fatalf("Shouldn't have verified the signature with crypto disabled but returned %v", err)
+	}
+}
+
+func TestVoteEmptyBlockID(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignVote(key, ids.ID{}, true); err != errEmptyVoteID {
+		t.Fatalf("Should have failed to sign a vote on an empty ID but returned %v", err)
+	}
+
+	p := wrappers.Packer{MaxSize: 1024}
+	if PackVote(&p, ids.ID{}, true, nil); p.Err != errEmptyVoteID {
+		t.Fatalf("Should have failed to pack a vote on an empty ID but failed with %v", p.Err)
+	}
+}
+
+func TestUnpackVoteTruncated(t *testing.T) {
+	f := FactorySECP256K1R{}
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID := ids.NewID(hashing.ComputeHash256Array([]byte("block")))
+	vote := packTestVote(t, key, blockID, false)
+
+	p := wrappers.Packer{Bytes: vote[:len(vote)-1]}
+	if unpacked := UnpackVote(&p); !p.Errored() || !unpacked.BlockID.IsZero() {
+		t.Fatal("Should have failed to unpack a truncated vote")
+	}
+}
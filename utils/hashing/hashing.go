@@ -9,6 +9,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 
 	"golang.org/x/crypto/ripemd160"
@@ -43,6 +44,10 @@ func ComputeHash256(buf []byte) []byte {
 	return arr[:]
 }
 
+// NewHash256 returns a hash that incrementally computes the same 256 bit hash
+// as ComputeHash256 of the bytes written to it.
+func NewHash256() hash.Hash { return sha256.New() }
+
 // ByteArraysToHash256Array takes in byte arrays and outputs a fixed 32 length
 //					byte array for the hash
 func ByteArraysToHash256Array(byteArray ...[]byte) [32]byte {
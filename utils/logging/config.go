@@ -19,6 +19,10 @@ type Config struct {
 	DisableLogging, DisableDisplaying, DisableContextualDisplaying, DisableFlushOnWrite, Assertions bool
 	LogLevel, DisplayLevel                                                                          Level
 	Directory, MsgPrefix                                                                            string
+	// SampleRates maps a level to N, so that only 1 in N messages of that level
+	// are logged. The number of dropped messages is logged periodically. Levels
+	// that aren't in the map, or whose N is at most 1, aren't sampled.
+	SampleRates map[Level]int
 }
 
 // DefaultConfig ...
@@ -9,6 +9,10 @@ import (
 	"github.com/ava-labs/gecko/ids"
 )
 
+// shortChainIDLen is the number of characters of a chain's ID that prefix the
+// lines of the chain's log
+const shortChainIDLen = 8
+
 // Factory ...
 type Factory interface {
 	Make() (Logger, error)
@@ -40,10 +44,11 @@ func (f *factory) Make() (Logger, error) {
 	return l, err
 }
 
-// MakeChain ...
+// MakeChain returns a log that writes to a directory named after [chainID] and
+// prefixes every line with the chain's short ID
 func (f *factory) MakeChain(chainID ids.ID, subdir string) (Logger, error) {
 	config := f.config
-	config.MsgPrefix = "SN " + chainID.String()
+	config.MsgPrefix = "SN " + shortChainID(chainID)
 	config.Directory = path.Join(config.Directory, "chain", chainID.String(), subdir)
 
 	log, err := New(config)
@@ -53,6 +58,16 @@ func (f *factory) MakeChain(chainID ids.ID, subdir string) (Logger, error) {
 	return log, err
 }
 
+// shortChainID returns the first characters of [chainID]'s string
+// representation, which are enough to tell chains apart in a log
+func shortChainID(chainID ids.ID) string {
+	str := chainID.String()
+	if len(str) > shortChainIDLen {
+		str = str[:shortChainIDLen]
+	}
+	return str
+}
+
 // MakeSubdir ...
 func (f *factory) MakeSubdir(subdir string) (Logger, error) {
 	config := f.config
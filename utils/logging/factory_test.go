@@ -0,0 +1,68 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestFactoryMakeChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logging-factory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config, err := DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Directory = dir
+	config.DisableDisplaying = true
+	f := NewFactory(config)
+
+	chainIDs := []ids.ID{
+		ids.NewID([32]byte{1}),
+		ids.NewID([32]byte{2}),
+	}
+	for _, chainID := range chainIDs {
+		log, err := f.MakeChain(chainID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		log.Info("hello from %s", chainID)
+	}
+	f.Close()
+
+	for _, chainID := range chainIDs {
+		contents, err := ioutil.ReadFile(path.Join(dir, "chain", chainID.String(), "0.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		if len(lines) != 1 {
+			t.Fatalf("chain %s's log should have had 1 line but had %d", chainID, len(lines))
+		}
+		prefix := " <SN " + chainID.String()[:shortChainIDLen] + "> "
+		if !strings.Contains(lines[0], prefix) || !strings.HasSuffix(lines[0], "hello from "+chainID.String()) {
+			t.Fatalf("chain %s's log line should have had prefix %q but was %q", chainID, prefix, lines[0])
+		}
+	}
+}
+
+func TestShortChainID(t *testing.T) {
+	chainID := ids.NewID([32]byte{1, 2, 3})
+	if short := shortChainID(chainID); short != chainID.String()[:shortChainIDLen] {
+		t.Fatalf("short chain ID should have been %s but was %s", chainID.String()[:shortChainIDLen], short)
+	}
+	if shortChainID(ids.Empty) == shortChainID(chainID) {
+		t.Fatal("different chains should have different short IDs")
+	}
+}
@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// missingValue is the value of a key passed without one
+const missingValue = "<missing>"
+
+// kvMessage is formatted by formatKV. It's only formatted if it's logged.
+type kvMessage struct {
+	msg string
+	kv  []interface{}
+}
+
+func (m kvMessage) String() string { return formatKV(m.msg, m.kv) }
+
+// formatKV returns [msg] followed by the alternating keys and values of [kv]
+// formatted as key=value. Keys and values that are empty or contain spaces,
+// quotes or '=' are quoted, so that the pairs can be parsed back. If a key has
+// no value, its value is [missingValue].
+func formatKV(msg string, kv []interface{}) string {
+	sb := strings.Builder{}
+	sb.WriteString(msg)
+	for i := 0; i < len(kv); i += 2 {
+		value := interface{}(missingValue)
+		if i+1 < len(kv) {
+			value = kv[i+1]
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(quoteKV(fmt.Sprint(kv[i])))
+		sb.WriteByte('=')
+		sb.WriteString(quoteKV(fmt.Sprint(value)))
+	}
+	return sb.String()
+}
+
+// quoteKV quotes [s] if it couldn't be parsed back from a key=value pair
+func quoteKV(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") || !strconv.CanBackquote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
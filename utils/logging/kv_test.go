@@ -0,0 +1,52 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFormatKV(t *testing.T) {
+	tests := []struct {
+		kv       []interface{}
+		expected string
+	}{
+		{kv: nil, expected: "msg"},
+		{kv: []interface{}{"height", 5, "accepted", true}, expected: "msg height=5 accepted=true"},
+		{kv: []interface{}{"peer", "1.2.3.4:9651", "err", nil}, expected: "msg peer=1.2.3.4:9651 err=<nil>"},
+		{kv: []interface{}{"reason", "timed out", "empty", ""}, expected: `msg reason="timed out" empty=""`},
+		{kv: []interface{}{"query", `a="b"`}, expected: `msg query="a=\"b\""`},
+		{kv: []interface{}{"height", 5, "orphan"}, expected: "msg height=5 orphan=" + missingValue},
+	}
+	for _, test := range tests {
+		if formatted := formatKV("msg", test.kv); formatted != test.expected {
+			t.Fatalf("formatKV(%v) returned %q, expected %q", test.kv, formatted, test.expected)
+		}
+	}
+}
+
+func TestLogKV(t *testing.T) {
+	l := &Log{config: Config{LogLevel: Info, DisableDisplaying: true}}
+	l.needsFlush = sync.NewCond(&l.flushLock)
+
+	l.Infow("block accepted", "height", 7, "id", "abc")
+	l.Warnw("peer dropped", "peer", "1.2.3.4:9651", "reason")
+	l.Debugw("not logged", "height", 8)
+	if len(l.messages) != 2 {
+		t.Fatalf("expected 2 messages to be logged but %d were: %v", len(l.messages), l.messages)
+	}
+
+	if msg := l.messages[0]; !strings.HasPrefix(msg, Info.String()) ||
+		!strings.Contains(msg, "kv_test.go") ||
+		!strings.HasSuffix(msg, "block accepted height=7 id=abc\n") {
+		t.Fatalf("wrong message: %q", msg)
+	}
+	// The value of a key without one is replaced by a placeholder
+	if msg := l.messages[1]; !strings.HasPrefix(msg, Warn.String()) ||
+		!strings.HasSuffix(msg, "peer dropped peer=1.2.3.4:9651 reason="+missingValue+"\n") {
+		t.Fatalf("wrong message: %q", msg)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ava-labs/gecko/utils/timer"
 )
 
 // Log ...
@@ -27,8 +29,18 @@ type Log struct {
 	w                                *bufio.Writer
 
 	closed bool
+
+	// Number of messages of each sampled level that have been passed to the
+	// logger, and how many of those have been dropped since the last summary
+	sampleCounts, dropped map[Level]int
+	lastSummary           time.Time
+	clock                 timer.Clock
 }
 
+// sampleSummaryInterval is how often the number of messages dropped by sampling
+// is logged
+const sampleSummaryInterval = time.Minute
+
 // New ...
 func New(config Config) (*Log, error) {
 	if err := os.MkdirAll(config.Directory, os.ModePerm); err != nil {
@@ -111,6 +123,10 @@ func (l *Log) Write(p []byte) (int, error) {
 
 // Stop ...
 func (l *Log) Stop() {
+	l.configLock.Lock()
+	l.logDropped(true)
+	l.configLock.Unlock()
+
 	l.flushLock.Lock()
 	l.closed = true
 	l.needsFlush.Signal()
@@ -134,8 +150,19 @@ func (l *Log) log(level Level, format string, args ...interface{}) {
 	if !shouldLog && !shouldDisplay {
 		return
 	}
+	if l.sampleOut(level) {
+		return
+	}
+	l.logDropped(false)
 
-	output := l.format(level, format, args...)
+	l.write(level, l.format(level, format, args...), fmt.Sprintf(format, args...))
+}
+
+// write [output] to the log file and [msg] to the display, depending on the
+// config. Should only be called with [l.configLock] held.
+func (l *Log) write(level Level, output, msg string) {
+	shouldLog := !l.config.DisableLogging && level <= l.config.LogLevel
+	shouldDisplay := (!l.config.DisableDisplaying && level <= l.config.DisplayLevel) || level == Fatal
 
 	if shouldLog {
 		l.flushLock.Lock()
@@ -147,7 +174,7 @@ func (l *Log) log(level Level, format string, args ...interface{}) {
 
 	if shouldDisplay {
 		if l.config.DisableContextualDisplaying {
-			fmt.Println(fmt.Sprintf(format, args...))
+			fmt.Println(msg)
 		} else {
 			fmt.Print(level.Color().Wrap(output))
 		}
@@ -162,8 +189,10 @@ func (l *Log) format(level Level, format string, args ...interface{}) string {
 	if i := strings.Index(loc, "gecko/"); i != -1 {
 		loc = loc[i+5:]
 	}
-	text := fmt.Sprintf("%s: %s", loc, fmt.Sprintf(format, args...))
+	return l.formatText(level, fmt.Sprintf("%s: %s", loc, fmt.Sprintf(format, args...)))
+}
 
+func (l *Log) formatText(level Level, text string) string {
 	prefix := ""
 	if l.config.MsgPrefix != "" {
 		prefix = fmt.Sprintf(" <%s>", l.config.MsgPrefix)
@@ -176,6 +205,49 @@ func (l *Log) format(level Level, format string, args ...interface{}) string {
 		text)
 }
 
+// sampleOut returns true if the message at [level] should be dropped because
+// only 1 in [l.config.SampleRates[level]] messages of that level are logged.
+// Should only be called with [l.configLock] held.
+func (l *Log) sampleOut(level Level) bool {
+	rate := l.config.SampleRates[level]
+	if rate <= 1 {
+		return false
+	}
+	if l.sampleCounts == nil {
+		l.sampleCounts = make(map[Level]int)
+		l.dropped = make(map[Level]int)
+		l.lastSummary = l.clock.Time()
+	}
+	count := l.sampleCounts[level]
+	l.sampleCounts[level] = count + 1
+	if count%rate == 0 {
+		return false
+	}
+	l.dropped[level]++
+	return true
+}
+
+// logDropped logs the number of messages of each level dropped by sampling
+// since the last summary, if [sampleSummaryInterval] has passed since then or
+// [force]. Should only be called with [l.configLock] held.
+func (l *Log) logDropped(force bool) {
+	if len(l.dropped) == 0 {
+		return
+	}
+	now := l.clock.Time()
+	if !force && now.Sub(l.lastSummary) < sampleSummaryInterval {
+		return
+	}
+	l.lastSummary = now
+	for level := Fatal; level <= Verbo; level++ {
+		if numDropped := l.dropped[level]; numDropped > 0 {
+			msg := fmt.Sprintf("dropped %d %s messages", numDropped, strings.TrimSpace(level.String()))
+			l.write(level, l.formatText(level, "log sampler: "+msg), msg)
+			delete(l.dropped, level)
+		}
+	}
+}
+
 // Fatal ...
 func (l *Log) Fatal(format string, args ...interface{}) { l.log(Fatal, format, args...) }
 
@@ -194,6 +266,18 @@ func (l *Log) Debug(format string, args ...interface{}) { l.log(Debug, format, a
 // Verbo ...
 func (l *Log) Verbo(format string, args ...interface{}) { l.log(Verbo, format, args...) }
 
+// Errorw ...
+func (l *Log) Errorw(msg string, kv ...interface{}) { l.log(Error, "%s", kvMessage{msg, kv}) }
+
+// Warnw ...
+func (l *Log) Warnw(msg string, kv ...interface{}) { l.log(Warn, "%s", kvMessage{msg, kv}) }
+
+// Infow ...
+func (l *Log) Infow(msg string, kv ...interface{}) { l.log(Info, "%s", kvMessage{msg, kv}) }
+
+// Debugw ...
+func (l *Log) Debugw(msg string, kv ...interface{}) { l.log(Debug, "%s", kvMessage{msg, kv}) }
+
 // AssertNoError ...
 func (l *Log) AssertNoError(err error) {
 	if err != nil {
@@ -221,7 +305,7 @@ func (l *Log) AssertDeferredTrue(f func() bool, format string, args ...interface
 	// Note, the logger will only be notified here if assertions are enabled
 	if l.config.Assertions && !f() {
 		err := fmt.Sprintf(format, args...)
-		l.log(Fatal, err)
+		l.log(Fatal, "%s", err)
 		l.Stop()
 		panic(err)
 	}
@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestLog returns a log that keeps its messages in memory
+func newTestLog(config Config) *Log {
+	l := &Log{config: config}
+	l.needsFlush = sync.NewCond(&l.flushLock)
+	l.clock.Set(time.Unix(1000, 0))
+	return l
+}
+
+func TestLogSampleRate(t *testing.T) {
+	l := newTestLog(Config{
+		LogLevel:          Debug,
+		DisableDisplaying: true,
+		SampleRates:       map[Level]int{Debug: 100},
+	})
+
+	for i := 0; i < 1000; i++ {
+		l.Debug("message %d", i)
+	}
+	l.Info("unsampled")
+	if len(l.messages) != 11 {
+		t.Fatalf("expected 10 debug messages and 1 info message to be logged but %d were", len(l.messages))
+	}
+	for i, msg := range l.messages[:10] {
+		if !strings.HasSuffix(msg, fmt.Sprintf(" message %d\n", i*100)) {
+			t.Fatalf("message %d should have been the %dth debug message but was %q", i, i*100, msg)
+		}
+	}
+
+	// The dropped messages are summarized once the summary interval passes
+	l.clock.Set(l.clock.Time().Add(sampleSummaryInterval))
+	l.Debug("message %d", 1000)
+	if len(l.messages) != 13 {
+		t.Fatalf("expected a summary and a debug message to be logged but %d messages were", len(l.messages)-11)
+	}
+	if summary := l.messages[11]; !strings.HasPrefix(summary, Debug.String()) ||
+		!strings.HasSuffix(summary, "dropped 990 DEBUG messages\n") {
+		t.Fatalf("wrong summary: %q", summary)
+	}
+	if msg := l.messages[12]; !strings.HasSuffix(msg, "message 1000\n") {
+		t.Fatalf("wrong message after the summary: %q", msg)
+	}
+}
+
+func TestLogSampleRateConcurrent(t *testing.T) {
+	l := newTestLog(Config{
+		LogLevel:          Verbo,
+		DisableDisplaying: true,
+		SampleRates:       map[Level]int{Debug: 10, Verbo: 50},
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Debug("debug")
+				l.Verbo("verbo")
+			}
+		}()
+	}
+	wg.Wait()
+
+	numDebug, numVerbo := 0, 0
+	for _, msg := range l.messages {
+		switch {
+		case strings.HasPrefix(msg, Debug.String()):
+			numDebug++
+		case strings.HasPrefix(msg, Verbo.String()):
+			numVerbo++
+		}
+	}
+	if numDebug != 100 || numVerbo != 20 {
+		t.Fatalf("expected 100 debug and 20 verbo messages to be logged but %d and %d were", numDebug, numVerbo)
+	}
+	if l.dropped[Debug] != 900 || l.dropped[Verbo] != 980 {
+		t.Fatalf("expected 900 debug and 980 verbo messages to be dropped but %d and %d were", l.dropped[Debug], l.dropped[Verbo])
+	}
+}
@@ -31,6 +31,13 @@ type Logger interface {
 	// aspect of the program
 	Verbo(format string, args ...interface{})
 
+	// Log [msg] at the level of the method, followed by the alternating keys
+	// and values of [kv] formatted as key=value
+	Errorw(msg string, kv ...interface{})
+	Warnw(msg string, kv ...interface{})
+	Infow(msg string, kv ...interface{})
+	Debugw(msg string, kv ...interface{})
+
 	// If assertions are enabled, will result in a panic if err is non-nil
 	AssertNoError(err error)
 	// If assertions are enabled, will result in a panic if b is false
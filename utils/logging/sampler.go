@@ -0,0 +1,81 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// Sampler limits how often a log call site writes to a logger. At most [limit]
+// messages are logged per [interval]. Further messages in the interval are
+// suppressed, and the number suppressed is logged when the next message after
+// the interval arrives.
+//
+// A Sampler should be shared by the calls of a single call site, as the limit
+// is applied to all the messages passed to it.
+type Sampler struct {
+	log      Logger
+	limit    int
+	interval time.Duration
+
+	lock        sync.Mutex
+	clock       timer.Clock
+	windowStart time.Time
+	logged      int
+	suppressed  int
+}
+
+// NewSampler returns a sampler that writes at most [limit] messages per
+// [interval] to [log]
+func NewSampler(log Logger, limit int, interval time.Duration) *Sampler {
+	return &Sampler{
+		log:      log,
+		limit:    limit,
+		interval: interval,
+	}
+}
+
+// Error ...
+func (s *Sampler) Error(format string, args ...interface{}) {
+	s.sample(s.log.Error, format, args...)
+}
+
+// Warn ...
+func (s *Sampler) Warn(format string, args ...interface{}) {
+	s.sample(s.log.Warn, format, args...)
+}
+
+// Info ...
+func (s *Sampler) Info(format string, args ...interface{}) {
+	s.sample(s.log.Info, format, args...)
+}
+
+// Debug ...
+func (s *Sampler) Debug(format string, args ...interface{}) {
+	s.sample(s.log.Debug, format, args...)
+}
+
+func (s *Sampler) sample(logf func(string, ...interface{}), format string, args ...interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if now := s.clock.Time(); !now.Before(s.windowStart.Add(s.interval)) {
+		if s.suppressed > 0 {
+			logf("suppressed %d messages in the last %s", s.suppressed, now.Sub(s.windowStart))
+		}
+		s.windowStart = now
+		s.logged = 0
+		s.suppressed = 0
+	}
+
+	if s.logged >= s.limit {
+		s.suppressed++
+		return
+	}
+	s.logged++
+	logf(format, args...)
+}
@@ -0,0 +1,57 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type recordLog struct {
+	NoLog
+	messages []string
+}
+
+func (l *recordLog) Warn(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestSampler(t *testing.T) {
+	log := &recordLog{}
+	sampler := NewSampler(log, 2, time.Minute)
+	start := time.Unix(1000, 0)
+	sampler.clock.Set(start)
+
+	for i := 0; i < 5; i++ {
+		sampler.Warn("message %d", i)
+	}
+	expected := []string{"message 0", "message 1"}
+	if fmt.Sprint(log.messages) != fmt.Sprint(expected) {
+		t.Fatalf("Logged %q, expected %q", log.messages, expected)
+	}
+
+	// Still in the same window
+	sampler.clock.Set(start.Add(time.Minute - time.Second))
+	sampler.Warn("message 5")
+	if len(log.messages) != 2 {
+		t.Fatalf("Logged %q, expected the message to be suppressed", log.messages)
+	}
+
+	// The next window starts with a summary of the suppressed messages
+	sampler.clock.Set(start.Add(time.Minute))
+	sampler.Warn("message 6")
+	expected = append(expected, "suppressed 4 messages in the last 1m0s", "message 6")
+	if fmt.Sprint(log.messages) != fmt.Sprint(expected) {
+		t.Fatalf("Logged %q, expected %q", log.messages, expected)
+	}
+
+	// No summary is logged if nothing was suppressed
+	sampler.clock.Set(start.Add(3 * time.Minute))
+	sampler.Warn("message 7")
+	expected = append(expected, "message 7")
+	if fmt.Sprint(log.messages) != fmt.Sprint(expected) {
+		t.Fatalf("Logged %q, expected %q", log.messages, expected)
+	}
+}
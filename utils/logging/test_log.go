@@ -34,6 +34,18 @@ func (NoLog) Debug(format string, args ...interface{}) {}
 // Verbo ...
 func (NoLog) Verbo(format string, args ...interface{}) {}
 
+// Errorw ...
+func (NoLog) Errorw(msg string, kv ...interface{}) {}
+
+// Warnw ...
+func (NoLog) Warnw(msg string, kv ...interface{}) {}
+
+// Infow ...
+func (NoLog) Infow(msg string, kv ...interface{}) {}
+
+// Debugw ...
+func (NoLog) Debugw(msg string, kv ...interface{}) {}
+
 // AssertNoError ...
 func (NoLog) AssertNoError(error) {}
 
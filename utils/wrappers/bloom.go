@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+const (
+	// MaxBloomBits is the maximum number of bits in a bloom filter
+	MaxBloomBits = 8 * 1024 * 1024
+	// MaxBloomHashes is the maximum number of hash functions of a bloom filter
+	MaxBloomHashes = 32
+)
+
+var errBadBloomParams = errors.New("bloom filter parameters are invalid")
+
+// BloomFilter is a probabilistic set. Contains never returns false for an
+// element that was added, but may return true for an element that wasn't.
+type BloomFilter struct {
+	numHashes int
+	numBits   int
+	bits      []byte
+}
+
+// NewBloomFilter returns an empty bloom filter sized such that, after
+// [maxElements] elements have been added, Contains returns true for an element
+// that wasn't added with probability at most [falsePositiveRate].
+func NewBloomFilter(maxElements int, falsePositiveRate float64) (*BloomFilter, error) {
+	if maxElements <= 0 || falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errBadBloomParams
+	}
+
+	// The optimal number of bits is -n*ln(p)/ln(2)^2 and the optimal number of
+	// hashes is (m/n)*ln(2)
+	numBits := math.Ceil(-float64(maxElements) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	if numBits > MaxBloomBits {
+		return nil, errBadBloomParams
+	}
+	numHashes := int(math.Round(numBits / float64(maxElements) * math.Ln2))
+	switch {
+	case numHashes < 1:
+		numHashes = 1
+	case numHashes > MaxBloomHashes:
+		numHashes = MaxBloomHashes
+	}
+	return newBloomFilter(numHashes, int(numBits)), nil
+}
+
+func newBloomFilter(numHashes, numBits int) *BloomFilter {
+	return &BloomFilter{
+		numHashes: numHashes,
+		numBits:   numBits,
+		bits:      make([]byte, (numBits+7)/8),
+	}
+}
+
+// Add [elem] to the filter
+func (b *BloomFilter) Add(elem []byte) {
+	h1, h2 := bloomHashes(elem)
+	for i := 0; i < b.numHashes; i++ {
+		bit := b.bit(h1, h2, i)
+		b.bits[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Contains returns true if [elem] may have been added to the filter and false
+// if it definitely wasn't
+func (b *BloomFilter) Contains(elem []byte) bool {
+	h1, h2 := bloomHashes(elem)
+	for i := 0; i < b.numHashes; i++ {
+		bit := b.bit(h1, h2, i)
+		if b.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bit returns the bit set by the [i]th hash function, derived from the two
+// base hashes [h1] and [h2] using double hashing
+func (b *BloomFilter) bit(h1, h2 uint64, i int) uint64 {
+	return (h1 + uint64(i)*h2) % uint64(b.numBits)
+}
+
+func bloomHashes(elem []byte) (uint64, uint64) {
+	hash := hashing.ComputeHash256(elem)
+	return binary.BigEndian.Uint64(hash), binary.BigEndian.Uint64(hash[LongLen:])
+}
+
+// PackBloom appends [b] to the byte array
+func (p *Packer) PackBloom(b *BloomFilter) {
+	p.PackByte(byte(b.numHashes))
+	p.PackInt(uint32(b.numBits))
+	p.PackFixedBytes(b.bits)
+}
+
+// UnpackBloom unpacks a bloom filter from the byte array
+func (p *Packer) UnpackBloom() *BloomFilter {
+	numHashes := p.UnpackByte()
+	numBits := p.UnpackInt()
+	if p.Errored() {
+		return nil
+	}
+	if numHashes < 1 || numHashes > MaxBloomHashes || numBits < 1 || numBits > MaxBloomBits {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	b := newBloomFilter(int(numHashes), int(numBits))
+	copy(b.bits, p.UnpackFixedBytes(len(b.bits)))
+	if p.Errored() {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,111 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	b, err := NewBloomFilter(1000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		b.Add([]byte(fmt.Sprintf("added %d", i)))
+	}
+	for i := 0; i < 1000; i++ {
+		if elem := []byte(fmt.Sprintf("added %d", i)); !b.Contains(elem) {
+			t.Fatalf("BloomFilter should contain %q", elem)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if b.Contains([]byte(fmt.Sprintf("not added %d", i))) {
+			falsePositives++
+		}
+	}
+	// The expected number of false positives is 100
+	if falsePositives > 200 {
+		t.Fatalf("BloomFilter returned %d false positives out of 10000", falsePositives)
+	}
+}
+
+func TestNewBloomFilterBadParams(t *testing.T) {
+	params := []struct {
+		maxElements       int
+		falsePositiveRate float64
+	}{
+		{maxElements: 0, falsePositiveRate: 0.01},
+		{maxElements: 10, falsePositiveRate: 0},
+		{maxElements: 10, falsePositiveRate: 1},
+		{maxElements: 1 << 30, falsePositiveRate: 0.01},
+	}
+	for _, param := range params {
+		if _, err := NewBloomFilter(param.maxElements, param.falsePositiveRate); err == nil {
+			t.Fatalf("NewBloomFilter(%d, %f) should have failed", param.maxElements, param.falsePositiveRate)
+		}
+	}
+}
+
+func TestPackerBloom(t *testing.T) {
+	b, err := NewBloomFilter(100, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		b.Add([]byte{byte(i)})
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackBloom(b)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	b2 := p2.UnpackBloom()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if p2.Offset != len(p2.Bytes) {
+		t.Fatalf("Packer.UnpackBloom left %d unread bytes", len(p2.Bytes)-p2.Offset)
+	}
+
+	for i := 0; i < 256; i++ {
+		elem := []byte{0, byte(i)}
+		if b.Contains(elem) != b2.Contains(elem) {
+			t.Fatalf("unpacked BloomFilter disagrees with the original on %v", elem)
+		}
+		if elem := []byte{byte(i)}; b.Contains(elem) != b2.Contains(elem) {
+			t.Fatalf("unpacked BloomFilter disagrees with the original on %v", elem)
+		}
+	}
+
+	p3 := Packer{MaxSize: 1024}
+	p3.PackBloom(b2)
+	if !bytes.Equal(p.Bytes, p3.Bytes) {
+		t.Fatal("repacking the unpacked BloomFilter produced different bytes")
+	}
+}
+
+func TestPackerUnpackBloomBadParams(t *testing.T) {
+	tests := [][]byte{
+		{0x00, 0x00, 0x00, 0x00, 0x08, 0xff},               // No hashes
+		{0x01, 0x00, 0x00, 0x00, 0x00},                     // No bits
+		{0x01, 0xff, 0xff, 0xff, 0xff, 0xff},               // Too many bits
+		{0x01, 0x00, 0x00, 0x00, 0x10, 0xff},               // Missing bits
+		{MaxBloomHashes + 1, 0x00, 0x00, 0x00, 0x08, 0xff}, // Too many hashes
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test}
+		if p.UnpackBloom(); !p.Errored() {
+			t.Fatalf("Packer.UnpackBloom(%v) should have failed", test)
+		}
+	}
+}
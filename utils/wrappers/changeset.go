@@ -0,0 +1,96 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// minChangesetOpLen is the minimum number of bytes of a packed operation: an
+// empty key and value, the timestamp and the node ID
+const minChangesetOpLen = 2*IntLen + LongLen + hashing.AddrLen
+
+// ChangesetOp sets [Key] to [Value]. [Timestamp] is the Lamport timestamp of
+// the operation on node [NodeID]. When operations on the same key conflict, the
+// one with the greatest (Timestamp, NodeID) wins.
+type ChangesetOp struct {
+	Key       []byte
+	Value     []byte
+	Timestamp uint64
+	NodeID    [hashing.AddrLen]byte
+}
+
+// wins returns true if [op] wins a conflict with [other]. Operations with the
+// same timestamp and node ID are ordered by value, so that merging is
+// deterministic even if a node reuses a timestamp.
+func (op *ChangesetOp) wins(other *ChangesetOp) bool {
+	switch {
+	case op.Timestamp != other.Timestamp:
+		return op.Timestamp > other.Timestamp
+	case op.NodeID != other.NodeID:
+		return bytes.Compare(op.NodeID[:], other.NodeID[:]) > 0
+	default:
+		return bytes.Compare(op.Value, other.Value) > 0
+	}
+}
+
+// PackChangeset appends [ops] to the byte array
+func (p *Packer) PackChangeset(ops []ChangesetOp) {
+	p.PackVarInt(uint64(len(ops)))
+	for _, op := range ops {
+		p.PackBytes(op.Key)
+		p.PackBytes(op.Value)
+		p.PackLong(op.Timestamp)
+		p.PackFixedBytes(op.NodeID[:])
+	}
+}
+
+// UnpackChangeset unpacks the changeset packed by PackChangeset from the byte
+// array
+func (p *Packer) UnpackChangeset() []ChangesetOp {
+	numOps := p.UnpackVarInt()
+	if p.Errored() {
+		return nil
+	}
+	if numOps > uint64(p.Remaining())/minChangesetOpLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	ops := make([]ChangesetOp, numOps)
+	for i := range ops {
+		ops[i].Key = p.UnpackBytes()
+		ops[i].Value = p.UnpackBytes()
+		ops[i].Timestamp = p.UnpackLong()
+		copy(ops[i].NodeID[:], p.UnpackFixedBytes(hashing.AddrLen))
+		if p.Errored() {
+			return nil
+		}
+	}
+	return ops
+}
+
+// MergeChangesets returns the last write to each key in [a] and [b], ordered by
+// key. The result doesn't depend on the order of the changesets, or of the
+// operations in them, so nodes that merge the same operations agree.
+func MergeChangesets(a, b []ChangesetOp) []ChangesetOp {
+	latest := make(map[string]ChangesetOp, len(a)+len(b))
+	for _, ops := range [][]ChangesetOp{a, b} {
+		for _, op := range ops {
+			if prev, exists := latest[string(op.Key)]; !exists || op.wins(&prev) {
+				latest[string(op.Key)] = op
+			}
+		}
+	}
+
+	merged := make([]ChangesetOp, 0, len(latest))
+	for _, op := range latest {
+		merged = append(merged, op)
+	}
+	sort.Slice(merged, func(i, j int) bool { return bytes.Compare(merged[i].Key, merged[j].Key) < 0 })
+	return merged
+}
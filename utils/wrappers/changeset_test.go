@@ -0,0 +1,74 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	nodeA = [hashing.AddrLen]byte{'a'}
+	nodeB = [hashing.AddrLen]byte{'b'}
+)
+
+func TestPackerChangeset(t *testing.T) {
+	ops := []ChangesetOp{
+		{Key: []byte("color"), Value: []byte("red"), Timestamp: 1, NodeID: nodeA},
+		{Key: []byte("size"), Value: []byte{}, Timestamp: 7, NodeID: nodeB},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackChangeset(ops)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	if unpacked := p2.UnpackChangeset(); p2.Errored() {
+		t.Fatal(p2.Err)
+	} else if !reflect.DeepEqual(unpacked, ops) {
+		t.Fatalf("Packer.UnpackChangeset returned %v, expected %v", unpacked, ops)
+	}
+
+	p3 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if unpacked := p3.UnpackChangeset(); !p3.Errored() || unpacked != nil {
+		t.Fatal("Packer.UnpackChangeset should have failed on a truncated changeset")
+	}
+}
+
+func TestMergeChangesets(t *testing.T) {
+	a := []ChangesetOp{
+		{Key: []byte("color"), Value: []byte("red"), Timestamp: 2, NodeID: nodeA},
+		{Key: []byte("shape"), Value: []byte("circle"), Timestamp: 5, NodeID: nodeA},
+		{Key: []byte("size"), Value: []byte("small"), Timestamp: 3, NodeID: nodeA},
+	}
+	b := []ChangesetOp{
+		{Key: []byte("size"), Value: []byte("large"), Timestamp: 4, NodeID: nodeB},
+		{Key: []byte("color"), Value: []byte("blue"), Timestamp: 2, NodeID: nodeB},
+		{Key: []byte("shape"), Value: []byte("square"), Timestamp: 1, NodeID: nodeB},
+	}
+	expected := []ChangesetOp{
+		b[1], // Same timestamp, so the greater node ID wins
+		a[1], // Later timestamp wins
+		b[0], // Later timestamp wins
+	}
+
+	if merged := MergeChangesets(a, b); !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("MergeChangesets returned %v, expected %v", merged, expected)
+	}
+	if merged := MergeChangesets(b, a); !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("MergeChangesets should be commutative but returned %v", merged)
+	}
+
+	// Merging is idempotent and associative
+	if merged := MergeChangesets(MergeChangesets(a, b), a); !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("merging a changeset again shouldn't have changed the result but returned %v", merged)
+	}
+	if merged := MergeChangesets(a[:1], MergeChangesets(b, a[1:])); !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("MergeChangesets should be associative but returned %v", merged)
+	}
+}
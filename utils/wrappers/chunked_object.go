@@ -0,0 +1,104 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"math"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errCorruptChunk        = errors.New("chunk doesn't match its checksum")
+	errChunkedObjectHash   = errors.New("chunked object doesn't match its hash")
+	errBadChunkedObjectLen = errors.New("chunked object's chunks don't match its manifest")
+)
+
+// PackChunkedObject appends [object] to the byte array split into chunks of
+// [chunkSize] bytes, the last of which may be shorter. The object is packed as
+// a manifest followed by the chunks:
+// * Manifest: the size of [object], [chunkSize], the number of chunks, and
+// the SHA-256 hash of [object]
+// * Chunk: its length prefixed bytes and their CRC-32C
+// Each chunk can be verified as soon as it's received, and the receiver knows
+// from the manifest which chunks are missing.
+func (p *Packer) PackChunkedObject(object []byte, chunkSize int) {
+	if chunkSize <= 0 || uint64(chunkSize) > math.MaxUint32 || uint64(len(object)) > math.MaxUint32 {
+		p.Add(errInvalidInput)
+		return
+	}
+	numChunks := (len(object) + chunkSize - 1) / chunkSize
+
+	p.PackInt(uint32(len(object)))
+	p.PackInt(uint32(chunkSize))
+	p.PackInt(uint32(numChunks))
+	p.PackFixedBytes(hashing.ComputeHash256(object))
+	for i := 0; i < numChunks; i++ {
+		end := (i + 1) * chunkSize
+		if end > len(object) {
+			end = len(object)
+		}
+		chunk := object[i*chunkSize : end]
+		p.PackBytes(chunk)
+		p.PackInt(crc32.Checksum(chunk, crcTable))
+	}
+}
+
+// UnpackChunkedObject unpacks an object packed by PackChunkedObject from the
+// byte array. Every chunk's checksum and the object's hash are verified.
+func (p *Packer) UnpackChunkedObject() []byte {
+	size := p.UnpackInt()
+	chunkSize := p.UnpackInt()
+	numChunks := p.UnpackInt()
+	hash := p.UnpackFixedBytes(hashing.HashLen)
+	if p.Errored() {
+		return nil
+	}
+	if chunkSize == 0 || uint64(numChunks) != (uint64(size)+uint64(chunkSize)-1)/uint64(chunkSize) {
+		p.Add(errBadChunkedObjectLen)
+		return nil
+	}
+	// Each chunk takes at least its size and checksum
+	if uint64(size)+uint64(numChunks)*2*IntLen > uint64(p.Remaining()) {
+		p.Add(errBadLength)
+		return nil
+	}
+	p.spend(int(size))
+	if p.Errored() {
+		return nil
+	}
+
+	object := make([]byte, 0, size)
+	for i := uint32(0); i < numChunks; i++ {
+		chunkLen := p.UnpackInt()
+		if p.Errored() {
+			return nil
+		}
+		// Every chunk but the last must be full, and the last must be the
+		// remainder of the object
+		if remaining := size - uint32(len(object)); chunkLen > chunkSize || chunkLen > remaining ||
+			(i < numChunks-1 && chunkLen != chunkSize) || (i == numChunks-1 && chunkLen != remaining) {
+			p.Add(errBadChunkedObjectLen)
+			return nil
+		}
+		chunk := p.UnpackFixedBytes(int(chunkLen))
+		checksum := p.UnpackInt()
+		if p.Errored() {
+			return nil
+		}
+		if crc32.Checksum(chunk, crcTable) != checksum {
+			p.Add(errCorruptChunk)
+			return nil
+		}
+		object = append(object, chunk...)
+	}
+	if !bytes.Equal(hashing.ComputeHash256(object), hash) {
+		p.Add(errChunkedObjectHash)
+		return nil
+	}
+	return object
+}
@@ -0,0 +1,110 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestPackerChunkedObject(t *testing.T) {
+	object := make([]byte, 1000)
+	for i := range object {
+		object[i] = byte(i * 7)
+	}
+
+	for _, chunkSize := range []int{1, 64, 100, 999, 1000, 4096} {
+		p := Packer{MaxSize: 16 * 1024}
+		p.PackChunkedObject(object, chunkSize)
+		if p.Errored() {
+			t.Fatal(p.Err)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		unpacked := p2.UnpackChunkedObject()
+		if p2.Errored() {
+			t.Fatalf("chunk size %d: %s", chunkSize, p2.Err)
+		}
+		if !bytes.Equal(unpacked, object) {
+			t.Fatalf("chunk size %d: Packer.UnpackChunkedObject returned the wrong object", chunkSize)
+		}
+		if p2.Offset != len(p2.Bytes) {
+			t.Fatalf("chunk size %d: Packer.UnpackChunkedObject left %d unread bytes", chunkSize, len(p2.Bytes)-p2.Offset)
+		}
+	}
+}
+
+func TestPackerChunkedObjectEmpty(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackChunkedObject(nil, 64)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	if unpacked := p2.UnpackChunkedObject(); p2.Errored() || len(unpacked) != 0 {
+		t.Fatalf("Packer.UnpackChunkedObject returned (%v, %v)", unpacked, p2.Err)
+	}
+}
+
+func TestPackerChunkedObjectCorruptChunk(t *testing.T) {
+	object := bytes.Repeat([]byte("chunked"), 100)
+
+	p := Packer{MaxSize: 4096}
+	p.PackChunkedObject(object, 128)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// Flip a bit in the second chunk, after the manifest and the first chunk
+	manifestLen := 3*IntLen + hashing.HashLen
+	chunkLen := IntLen + 128 + IntLen
+	corrupted := append([]byte(nil), p.Bytes...)
+	corrupted[manifestLen+chunkLen+IntLen+5] ^= 1
+
+	p2 := Packer{Bytes: corrupted}
+	if unpacked := p2.UnpackChunkedObject(); p2.Err != errCorruptChunk || unpacked != nil {
+		t.Fatalf("Packer.UnpackChunkedObject should have failed with %s but returned (%v, %v)", errCorruptChunk, unpacked, p2.Err)
+	}
+
+	// The chunks must also match the object's hash
+	corrupted = append([]byte(nil), p.Bytes...)
+	corrupted[3*IntLen] ^= 1
+	p3 := Packer{Bytes: corrupted}
+	if unpacked := p3.UnpackChunkedObject(); p3.Err != errChunkedObjectHash || unpacked != nil {
+		t.Fatalf("Packer.UnpackChunkedObject should have failed with %s but returned (%v, %v)", errChunkedObjectHash, unpacked, p3.Err)
+	}
+}
+
+func TestPackerUnpackChunkedObjectBadManifest(t *testing.T) {
+	object := bytes.Repeat([]byte("chunked"), 10)
+
+	p := Packer{MaxSize: 4096}
+	p.PackChunkedObject(object, 16)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// Claim one fewer chunk than the object needs
+	corrupted := append([]byte(nil), p.Bytes...)
+	corrupted[3*IntLen-1]--
+	p2 := Packer{Bytes: corrupted}
+	if p2.UnpackChunkedObject(); p2.Err != errBadChunkedObjectLen {
+		t.Fatalf("Packer.UnpackChunkedObject should have failed with %s but got %v", errBadChunkedObjectLen, p2.Err)
+	}
+
+	// Every chunk must be present
+	p3 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if p3.UnpackChunkedObject(); !p3.Errored() {
+		t.Fatal("Packer.UnpackChunkedObject should have failed on a truncated object")
+	}
+
+	p4 := Packer{MaxSize: 4096}
+	p4.PackChunkedObject(object, 0)
+	if !p4.Errored() {
+		t.Fatal("Packer.PackChunkedObject should have rejected a chunk size of 0")
+	}
+}
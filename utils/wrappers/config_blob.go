@@ -0,0 +1,108 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"sort"
+)
+
+// Type tags of config blob values
+const (
+	configInt    byte = 0 // Packed with PackLong
+	configString byte = 1 // Packed with PackStr
+	configBool   byte = 2 // Packed with PackBool
+	configBytes  byte = 3 // Packed with PackBytes
+)
+
+// minConfigEntryLen is the minimum number of bytes of a packed entry: an empty
+// key, a type tag, and a bool
+const minConfigEntryLen = ShortLen + 1 + BoolLen
+
+var (
+	errUnsupportedConfigType = errors.New("unsupported config value type")
+	errConfigKeysOutOfOrder  = errors.New("config keys must be sorted and unique")
+)
+
+// PackConfigBlob appends [config] to the byte array as the number of entries
+// followed by each entry's key, type tag and value, sorted by key. Values must
+// be an int, int64, string, bool or []byte. Ints are unpacked as int64s.
+func (p *Packer) PackConfigBlob(config map[string]interface{}) {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	p.PackInt(uint32(len(keys)))
+	for _, key := range keys {
+		p.PackStr(key)
+		switch value := config[key].(type) {
+		case int:
+			p.PackByte(configInt)
+			p.PackLong(uint64(value))
+		case int64:
+			p.PackByte(configInt)
+			p.PackLong(uint64(value))
+		case string:
+			p.PackByte(configString)
+			p.PackStr(value)
+		case bool:
+			p.PackByte(configBool)
+			p.PackBool(value)
+		case []byte:
+			p.PackByte(configBytes)
+			p.PackBytes(value)
+		default:
+			p.Add(errUnsupportedConfigType)
+		}
+		if p.Errored() {
+			return
+		}
+	}
+}
+
+// UnpackConfigBlob unpacks a config packed by PackConfigBlob from the byte
+// array. Entries must be sorted by key, so every config has one encoding.
+func (p *Packer) UnpackConfigBlob() (map[string]interface{}, error) {
+	numEntries := p.UnpackInt()
+	if p.Errored() {
+		return nil, p.Err
+	}
+	if numEntries > uint32(p.Remaining())/minConfigEntryLen {
+		p.Add(errInvalidInput)
+		return nil, p.Err
+	}
+
+	config := make(map[string]interface{}, numEntries)
+	prevKey := ""
+	for i := uint32(0); i < numEntries; i++ {
+		key := p.UnpackStr()
+		if p.Errored() {
+			return nil, p.Err
+		}
+		if i > 0 && key <= prevKey {
+			p.Add(errConfigKeysOutOfOrder)
+			return nil, p.Err
+		}
+		prevKey = key
+
+		switch tag := p.UnpackByte(); tag {
+		case configInt:
+			config[key] = int64(p.UnpackLong())
+		case configString:
+			config[key] = p.UnpackStr()
+		case configBool:
+			config[key] = p.UnpackBool()
+		case configBytes:
+			config[key] = p.UnpackBytes()
+		default:
+			p.Add(errUnsupportedConfigType)
+		}
+		if p.Errored() {
+			return nil, p.Err
+		}
+	}
+	return config, nil
+}
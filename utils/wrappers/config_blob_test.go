@@ -0,0 +1,123 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestPackerConfigBlob(t *testing.T) {
+	config := map[string]interface{}{
+		"port":       9650,
+		"maxLatency": int64(math.MinInt64),
+		"name":       "node",
+		"staking":    true,
+		"empty":      "",
+		"seed":       []byte{1, 2, 3},
+		"disabled":   false,
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackConfigBlob(config)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// Packing is independent of map iteration order
+	p2 := Packer{MaxSize: 1024}
+	p2.PackConfigBlob(config)
+	if !bytes.Equal(p.Bytes, p2.Bytes) {
+		t.Fatalf("Packer.PackConfigBlob isn't deterministic")
+	}
+
+	p3 := Packer{Bytes: p.Bytes}
+	unpacked, err := p3.UnpackConfigBlob()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p3.Offset != len(p3.Bytes) {
+		t.Fatalf("Packer.UnpackConfigBlob left %d unread bytes", len(p3.Bytes)-p3.Offset)
+	}
+
+	expected := map[string]interface{}{
+		"port":       int64(9650),
+		"maxLatency": int64(math.MinInt64),
+		"name":       "node",
+		"staking":    true,
+		"empty":      "",
+		"disabled":   false,
+	}
+	if len(unpacked) != len(config) {
+		t.Fatalf("Packer.UnpackConfigBlob returned %d entries, expected %d", len(unpacked), len(config))
+	}
+	for key, value := range expected {
+		if unpacked[key] != value {
+			t.Fatalf("Packer.UnpackConfigBlob returned %v (%T) for %q, expected %v (%T)", unpacked[key], unpacked[key], key, value, value)
+		}
+	}
+	if seed, ok := unpacked["seed"].([]byte); !ok || !bytes.Equal(seed, []byte{1, 2, 3}) {
+		t.Fatalf("Packer.UnpackConfigBlob returned %v for %q", unpacked["seed"], "seed")
+	}
+}
+
+func TestPackerConfigBlobUnsupportedType(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackConfigBlob(map[string]interface{}{
+		"name":    "node",
+		"weights": []float64{0.5},
+	})
+	if p.Err != errUnsupportedConfigType {
+		t.Fatalf("Packer.PackConfigBlob should have failed with %s but got %v", errUnsupportedConfigType, p.Err)
+	}
+}
+
+func TestPackerUnpackConfigBlobInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytes    []byte
+		expected error
+	}{
+		{
+			name: "unknown tag",
+			bytes: []byte{
+				0x00, 0x00, 0x00, 0x01, // 1 entry
+				0x00, 0x01, 'a', // key
+				0x09, // tag
+				0x00,
+			},
+			expected: errUnsupportedConfigType,
+		},
+		{
+			name: "unsorted keys",
+			bytes: []byte{
+				0x00, 0x00, 0x00, 0x02, // 2 entries
+				0x00, 0x01, 'b', configBool, 0x01,
+				0x00, 0x01, 'a', configBool, 0x01,
+			},
+			expected: errConfigKeysOutOfOrder,
+		},
+		{
+			name: "duplicate keys",
+			bytes: []byte{
+				0x00, 0x00, 0x00, 0x02, // 2 entries
+				0x00, 0x01, 'a', configBool, 0x01,
+				0x00, 0x01, 'a', configBool, 0x00,
+			},
+			expected: errConfigKeysOutOfOrder,
+		},
+		{
+			name:     "too many entries",
+			bytes:    []byte{0xff, 0xff, 0xff, 0xff},
+			expected: errInvalidInput,
+		},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		if _, err := p.UnpackConfigBlob(); err != test.expected {
+			t.Fatalf("%s: Packer.UnpackConfigBlob should have failed with %s but got %v", test.name, test.expected, err)
+		}
+	}
+}
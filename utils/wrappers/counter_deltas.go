@@ -0,0 +1,95 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// minCounterDeltaLen is the minimum number of bytes of a packed delta: an
+// empty key and a one byte varint
+const minCounterDeltaLen = ShortLen + 1
+
+var (
+	errCounterDecreased = errors.New("counters can only increase")
+	errCounterOverflow  = errors.New("counter overflowed")
+)
+
+// PackCounterDeltas appends the changes that turn the counters in [base] into
+// the counters in [current] to the byte array. Only counters that changed are
+// packed, as their key and the amount they increased by, sorted by key.
+// Counters are append-only, so every counter in [base] must be in [current]
+// with at least the same count.
+func (p *Packer) PackCounterDeltas(base, current map[string]uint64) {
+	for key, count := range base {
+		if current[key] < count {
+			p.Add(errCounterDecreased)
+			return
+		}
+	}
+
+	keys := []string(nil)
+	for key, count := range current {
+		if count != base[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	p.PackVarInt(uint64(len(keys)))
+	for _, key := range keys {
+		p.PackStr(key)
+		p.PackVarInt(current[key] - base[key])
+	}
+}
+
+// UnpackCounterDeltas unpacks the changes packed by PackCounterDeltas from the
+// byte array. They can be applied with ApplyCounterDeltas.
+func (p *Packer) UnpackCounterDeltas() map[string]uint64 {
+	numDeltas := p.UnpackVarInt()
+	if p.Errored() {
+		return nil
+	}
+	if numDeltas > uint64(p.Remaining())/minCounterDeltaLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	deltas := make(map[string]uint64, numDeltas)
+	prevKey := ""
+	for i := uint64(0); i < numDeltas; i++ {
+		key := p.UnpackStr()
+		delta := p.UnpackVarInt()
+		if p.Errored() {
+			return nil
+		}
+		// Keys must be sorted and unique, and only changes are packed, so that
+		// every change has one encoding
+		if (i > 0 && key <= prevKey) || delta == 0 {
+			p.Add(errInvalidInput)
+			return nil
+		}
+		deltas[key] = delta
+		prevKey = key
+	}
+	return deltas
+}
+
+// ApplyCounterDeltas returns the counters in [base] increased by [deltas].
+// [base] isn't modified.
+func ApplyCounterDeltas(base, deltas map[string]uint64) (map[string]uint64, error) {
+	current := make(map[string]uint64, len(base)+len(deltas))
+	for key, count := range base {
+		current[key] = count
+	}
+	for key, delta := range deltas {
+		if delta > math.MaxUint64-current[key] {
+			return nil, errCounterOverflow
+		}
+		current[key] += delta
+	}
+	return current, nil
+}
@@ -0,0 +1,108 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPackerCounterDeltas(t *testing.T) {
+	base := map[string]uint64{
+		"blocks":   100,
+		"requests": 5000,
+		"errors":   3,
+	}
+	current := map[string]uint64{
+		"blocks":   102,
+		"requests": 5000,
+		"errors":   3,
+		"timeouts": 1,
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackCounterDeltas(base, current)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// Only the changed counters are packed, sorted by key
+	expected := []byte{
+		0x02, // 2 deltas
+		0x00, 0x06, 'b', 'l', 'o', 'c', 'k', 's', 0x02,
+		0x00, 0x08, 't', 'i', 'm', 'e', 'o', 'u', 't', 's', 0x01,
+	}
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackCounterDeltas wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	deltas := p2.UnpackCounterDeltas()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	reconstructed, err := ApplyCounterDeltas(base, deltas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reconstructed, current) {
+		t.Fatalf("ApplyCounterDeltas returned %v, expected %v", reconstructed, current)
+	}
+	if base["blocks"] != 100 {
+		t.Fatal("ApplyCounterDeltas shouldn't have modified the base counters")
+	}
+}
+
+func TestPackerCounterDeltasUnchanged(t *testing.T) {
+	counters := map[string]uint64{"blocks": 1}
+
+	p := Packer{MaxSize: 1024}
+	p.PackCounterDeltas(counters, counters)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if !bytes.Equal(p.Bytes, []byte{0x00}) {
+		t.Fatalf("Packer.PackCounterDeltas wrote %v for unchanged counters", p.Bytes)
+	}
+}
+
+func TestPackerCounterDeltasDecreased(t *testing.T) {
+	for _, current := range []map[string]uint64{
+		{"blocks": 99},
+		{}, // Counters can't be removed
+	} {
+		p := Packer{MaxSize: 1024}
+		p.PackCounterDeltas(map[string]uint64{"blocks": 100}, current)
+		if p.Err != errCounterDecreased {
+			t.Fatalf("Packer.PackCounterDeltas should have failed with %s but got %v", errCounterDecreased, p.Err)
+		}
+	}
+}
+
+func TestPackerUnpackCounterDeltasInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+	}{
+		{name: "unsorted keys", bytes: []byte{0x02, 0x00, 0x01, 'b', 0x01, 0x00, 0x01, 'a', 0x01}},
+		{name: "duplicate keys", bytes: []byte{0x02, 0x00, 0x01, 'a', 0x01, 0x00, 0x01, 'a', 0x01}},
+		{name: "zero delta", bytes: []byte{0x01, 0x00, 0x01, 'a', 0x00}},
+		{name: "too many deltas", bytes: []byte{0x7f}},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		if deltas := p.UnpackCounterDeltas(); !p.Errored() || deltas != nil {
+			t.Fatalf("%s: Packer.UnpackCounterDeltas should have errored", test.name)
+		}
+	}
+}
+
+func TestApplyCounterDeltasOverflow(t *testing.T) {
+	base := map[string]uint64{"blocks": math.MaxUint64}
+	if _, err := ApplyCounterDeltas(base, map[string]uint64{"blocks": 1}); err != errCounterOverflow {
+		t.Fatalf("ApplyCounterDeltas should have failed with %s but got %v", errCounterOverflow, err)
+	}
+}
@@ -0,0 +1,142 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+const (
+	// deltaBlockLen is the length of the chunks of the base that are indexed
+	// when computing a delta. Matches shorter than this aren't found.
+	deltaBlockLen = 8
+
+	// Operations of a delta
+	deltaCopy   byte = 0 // Copy a range of the base to the target
+	deltaInsert byte = 1 // Append new bytes to the target
+)
+
+// deltaOp is an operation of a delta. If [insert] is nil, [length] bytes
+// starting at [offset] are copied from the base. Otherwise [insert] is
+// appended.
+type deltaOp struct {
+	offset, length int
+	insert         []byte
+}
+
+// PackBlobDelta appends a delta that transforms [base] into [target] to the
+// byte array. The delta is a sequence of operations that either copy a range
+// of [base] or insert new bytes. Matches are found by indexing [base] in
+// chunks, so the delta is small when [target] shares long runs with [base],
+// but isn't necessarily minimal.
+func (p *Packer) PackBlobDelta(base, target []byte) {
+	ops := computeDelta(base, target)
+	p.PackVarInt(uint64(len(ops)))
+	for _, op := range ops {
+		if op.insert == nil {
+			p.PackByte(deltaCopy)
+			p.PackVarInt(uint64(op.offset))
+			p.PackVarInt(uint64(op.length))
+		} else {
+			p.PackByte(deltaInsert)
+			p.PackVarInt(uint64(len(op.insert)))
+			p.PackFixedBytes(op.insert)
+		}
+	}
+}
+
+// UnpackBlobDelta unpacks a delta packed by PackBlobDelta and returns the
+// result of applying it to [base]. The size of the result counts against the
+// packer's allocation budget.
+func (p *Packer) UnpackBlobDelta(base []byte) []byte {
+	numOps := p.UnpackVarInt()
+	if p.Errored() {
+		return nil
+	}
+	// Every operation takes at least 2 bytes
+	if numOps > uint64(p.Remaining())/2 {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	target := []byte{}
+	for i := uint64(0); i < numOps && !p.Errored(); i++ {
+		switch op := p.UnpackByte(); op {
+		case deltaCopy:
+			offset := p.UnpackVarInt()
+			length := p.UnpackVarInt()
+			if p.Errored() {
+				break
+			}
+			if offset > uint64(len(base)) || length > uint64(len(base))-offset {
+				p.Add(errInvalidInput)
+				break
+			}
+			p.spend(int(length))
+			if !p.Errored() {
+				target = append(target, base[offset:offset+length]...)
+			}
+		case deltaInsert:
+			length := p.UnpackVarInt()
+			if p.Errored() {
+				break
+			}
+			if length > uint64(p.Remaining()) {
+				p.Add(errBadLength)
+				break
+			}
+			p.spend(int(length))
+			target = append(target, p.UnpackFixedBytes(int(length))...)
+		default:
+			p.Add(errInvalidInput)
+		}
+	}
+	if p.Errored() {
+		return nil
+	}
+	return target
+}
+
+// computeDelta returns operations that transform [base] into [target]
+func computeDelta(base, target []byte) []deltaOp {
+	// Index the offset of the first occurrence of every aligned chunk of base
+	chunks := make(map[string]int)
+	for offset := 0; offset+deltaBlockLen <= len(base); offset += deltaBlockLen {
+		chunk := string(base[offset : offset+deltaBlockLen])
+		if _, exists := chunks[chunk]; !exists {
+			chunks[chunk] = offset
+		}
+	}
+
+	ops := []deltaOp(nil)
+	insertStart := 0
+	for i := 0; i < len(target); {
+		offset, exists := -1, false
+		if i+deltaBlockLen <= len(target) {
+			offset, exists = chunks[string(target[i:i+deltaBlockLen])]
+		}
+		if !exists {
+			i++
+			continue
+		}
+
+		// Extend the match backwards into the pending insert, then forwards
+		start := i
+		for start > insertStart && offset > 0 && base[offset-1] == target[start-1] {
+			start--
+			offset--
+		}
+		end := i + deltaBlockLen
+		for end < len(target) && offset+end-start < len(base) && base[offset+end-start] == target[end] {
+			end++
+		}
+
+		if start > insertStart {
+			ops = append(ops, deltaOp{insert: target[insertStart:start]})
+		}
+		ops = append(ops, deltaOp{offset: offset, length: end - start})
+		i = end
+		insertStart = end
+	}
+	if insertStart < len(target) {
+		ops = append(ops, deltaOp{insert: target[insertStart:]})
+	}
+	return ops
+}
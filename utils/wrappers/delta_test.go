@@ -0,0 +1,88 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPackerBlobDelta(t *testing.T) {
+	base := []byte("The quick brown fox jumps over the lazy dog. " +
+		"Pack my box with five dozen liquor jugs. " +
+		"How vexingly quick daft zebras jump!")
+
+	tests := []struct {
+		name   string
+		target []byte
+	}{
+		{name: "unchanged", target: base},
+		{name: "empty target", target: []byte{}},
+		{name: "append", target: append(append([]byte{}, base...), " The end."...)},
+		{name: "prepend", target: append([]byte("Preface: "), base...)},
+		{name: "truncate", target: base[:50]},
+		{name: "delete middle", target: append(append([]byte{}, base[:20]...), base[60:]...)},
+		{name: "insert middle", target: append(append(append([]byte{}, base[:45]...), "Sphinx of black quartz, judge my vow. "...), base[45:]...)},
+		{name: "replace", target: bytes.Replace(base, []byte("quick"), []byte("slow"), -1)},
+		{name: "reorder", target: append(append([]byte{}, base[45:]...), base[:45]...)},
+		{name: "repeat", target: bytes.Repeat(base[:45], 3)},
+		{name: "unrelated", target: []byte("Nothing in common with the base at all, really.")},
+	}
+	for _, test := range tests {
+		p := Packer{MaxSize: 1024}
+		p.PackBlobDelta(base, test.target)
+		if p.Errored() {
+			t.Fatalf("%s: %s", test.name, p.Err)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		target := p2.UnpackBlobDelta(base)
+		if p2.Errored() {
+			t.Fatalf("%s: %s", test.name, p2.Err)
+		}
+		if !bytes.Equal(target, test.target) {
+			t.Fatalf("%s: reconstructed:\n%q\nExpected:\n%q", test.name, target, test.target)
+		}
+		if p2.Offset != len(p2.Bytes) {
+			t.Fatalf("%s: Packer.UnpackBlobDelta left %d unread bytes", test.name, len(p2.Bytes)-p2.Offset)
+		}
+	}
+}
+
+func TestPackerBlobDeltaSize(t *testing.T) {
+	base := bytes.Repeat([]byte("0123456789abcdef"), 256)
+	target := append(append(append([]byte{}, base[:2000]...), "edit"...), base[2000:]...)
+
+	p := Packer{MaxSize: 1 << 16}
+	p.PackBlobDelta(base, target)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if len(p.Bytes) > 32 {
+		t.Fatalf("delta of a small edit to a %d byte blob is %d bytes", len(base), len(p.Bytes))
+	}
+}
+
+func TestPackerUnpackBlobDeltaInvalid(t *testing.T) {
+	base := []byte("base")
+	tests := [][]byte{
+		{0x01, deltaCopy, 0x00, 0x05},  // Copy past the end of the base
+		{0x01, deltaCopy, 0x05, 0x00},  // Copy from past the end of the base
+		{0x01, deltaInsert, 0x05, 'a'}, // Insert past the end of the delta
+		{0x01, 0x02, 0x00},             // Unknown op
+		{0x10, deltaCopy, 0x00, 0x01},  // More ops than bytes
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test}
+		if p.UnpackBlobDelta(base); !p.Errored() {
+			t.Fatalf("Packer.UnpackBlobDelta(%v) should have failed", test)
+		}
+	}
+
+	// Copying the base repeatedly exceeds the allocation budget
+	p := Packer{Bytes: []byte{0x02, deltaCopy, 0x00, 0x04, deltaCopy, 0x00, 0x04}, AllocBudget: 6}
+	if p.UnpackBlobDelta(base); p.Err != errAllocBudget {
+		t.Fatalf("Packer.UnpackBlobDelta should have failed with %s but failed with %v", errAllocBudget, p.Err)
+	}
+}
@@ -0,0 +1,80 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+)
+
+// MaxDictionaryRecordLen is the maximum length of the data of a record packed
+// with PackWithDictionary
+const MaxDictionaryRecordLen = 1 << 20
+
+// PackWithDictionary appends [data] to the byte array compressed with flate,
+// using [dict] as the preset dictionary. Records that share content with
+// [dict], such as repeated JSON keys, compress much better than they would
+// alone. The record can only be unpacked with the same [dict]. A record is:
+// * The length of [data] (4 bytes)
+// * The length of the compressed data (4 bytes)
+// * The compressed data
+func (p *Packer) PackWithDictionary(data []byte, dict []byte) {
+	if len(data) > MaxDictionaryRecordLen {
+		p.Add(errInvalidInput)
+		return
+	}
+
+	compressed := bytes.Buffer{}
+	w, err := flate.NewWriterDict(&compressed, flate.BestCompression, dict)
+	if err != nil {
+		p.Add(err)
+		return
+	}
+	if _, err := w.Write(data); err != nil {
+		p.Add(err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		p.Add(err)
+		return
+	}
+
+	p.PackInt(uint32(len(data)))
+	p.PackBytes(compressed.Bytes())
+}
+
+// UnpackWithDictionary unpacks and decompresses a record packed by
+// PackWithDictionary with the preset dictionary [dict] from the byte array
+func (p *Packer) UnpackWithDictionary(dict []byte) []byte {
+	dataLen := p.UnpackInt()
+	if p.Errored() {
+		return nil
+	}
+	if dataLen > MaxDictionaryRecordLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	p.spend(int(dataLen))
+	compressed := p.UnpackBytes()
+	if p.Errored() {
+		return nil
+	}
+
+	r := flate.NewReaderDict(bytes.NewReader(compressed), dict)
+	defer r.Close()
+	// Read one byte more than expected, so that data longer than its declared
+	// length is detected without decompressing all of it
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(dataLen)+1))
+	if err != nil {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	if len(data) != int(dataLen) {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,78 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+)
+
+var (
+	testDictionary = []byte(`{"blockID":"","parentID":"","timestamp":0,"height":0,"data":"","proposer":""}`)
+	testRecord     = []byte(`{"blockID":"2Z36RnQuk1hvsnFeGWzfZUfXNr7w1SjzmDQ78YxfTVNAkDq3nZ","parentID":"nLMnRrCg2dH2pdpadstMQEnkCJfiX9Jt23ATDvrJWpgtFGUgh","timestamp":1588000000,"height":42,"data":"aGVsbG8=","proposer":"7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg"}`)
+)
+
+func TestPackerWithDictionary(t *testing.T) {
+	p := Packer{MaxSize: 4096}
+	p.PackWithDictionary(testRecord, testDictionary)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	data := p2.UnpackWithDictionary(testDictionary)
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if !bytes.Equal(data, testRecord) {
+		t.Fatalf("Packer.UnpackWithDictionary returned %q, expected %q", data, testRecord)
+	}
+	if p2.Remaining() != 0 {
+		t.Fatalf("record should have been fully consumed but %d bytes remain", p2.Remaining())
+	}
+}
+
+func TestPackerWithDictionaryCompressesBetter(t *testing.T) {
+	withDict := Packer{MaxSize: 4096}
+	withDict.PackWithDictionary(testRecord, testDictionary)
+	withoutDict := Packer{MaxSize: 4096}
+	withoutDict.PackWithDictionary(testRecord, nil)
+	if withDict.Errored() || withoutDict.Errored() {
+		t.Fatalf("packing failed: %v, %v", withDict.Err, withoutDict.Err)
+	}
+	if len(withDict.Bytes) >= len(withoutDict.Bytes) {
+		t.Fatalf("record should have compressed smaller with the dictionary (%d bytes) than without (%d bytes)", len(withDict.Bytes), len(withoutDict.Bytes))
+	}
+
+	p := Packer{Bytes: withoutDict.Bytes}
+	if data := p.UnpackWithDictionary(nil); p.Errored() || !bytes.Equal(data, testRecord) {
+		t.Fatalf("record packed without a dictionary should have round-tripped: %v", p.Err)
+	}
+}
+
+func TestPackerWithDictionaryInvalid(t *testing.T) {
+	p := Packer{MaxSize: 4096}
+	p.PackWithDictionary(testRecord, testDictionary)
+
+	// The wrong dictionary can't decompress the record
+	p2 := Packer{Bytes: p.Bytes}
+	if data := p2.UnpackWithDictionary([]byte("a different dictionary")); !p2.Errored() && bytes.Equal(data, testRecord) {
+		t.Fatal("Packer.UnpackWithDictionary shouldn't have returned the record with the wrong dictionary")
+	}
+
+	// A declared length that doesn't match the data is rejected
+	corrupted := append([]byte(nil), p.Bytes...)
+	corrupted[IntLen-1]--
+	p3 := Packer{Bytes: corrupted}
+	if p3.UnpackWithDictionary(testDictionary); p3.Err != errInvalidInput {
+		t.Fatalf("Packer.UnpackWithDictionary should have failed with %s but returned %v", errInvalidInput, p3.Err)
+	}
+
+	// Records longer than the maximum length are rejected
+	p4 := Packer{MaxSize: 2 * MaxDictionaryRecordLen}
+	p4.PackWithDictionary(make([]byte, MaxDictionaryRecordLen+1), testDictionary)
+	if p4.Err != errInvalidInput {
+		t.Fatalf("Packer.PackWithDictionary should have failed with %s but returned %v", errInvalidInput, p4.Err)
+	}
+}
@@ -0,0 +1,71 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import "errors"
+
+var errEnumTooWide = errors.New("enum value doesn't fit in the bit width")
+
+// enumSeqLen returns the number of bytes that [count] values of
+// [bitsPerValue] bits are packed into
+func enumSeqLen(count int, bitsPerValue uint8) int {
+	return (count*int(bitsPerValue) + 7) / 8
+}
+
+// PackEnumSeq appends [values] to the byte array, packing each value into
+// [bitsPerValue] bits, which must be in [1, 8]. Value i occupies bits
+// [i*bitsPerValue, (i+1)*bitsPerValue), starting from the least significant bit
+// of the first byte. The number of values isn't packed.
+func (p *Packer) PackEnumSeq(values []byte, bitsPerValue uint8) {
+	if bitsPerValue == 0 || bitsPerValue > 8 {
+		p.Add(errInvalidInput)
+		return
+	}
+	seq := make([]byte, enumSeqLen(len(values), bitsPerValue))
+	bit := 0
+	for _, value := range values {
+		if bitsPerValue < 8 && value>>bitsPerValue != 0 {
+			p.Add(errEnumTooWide)
+			return
+		}
+		for i := uint8(0); i < bitsPerValue; i++ {
+			seq[bit/8] |= (value >> i & 1) << (bit % 8)
+			bit++
+		}
+	}
+	p.PackFixedBytes(seq)
+}
+
+// UnpackEnumSeq unpacks [count] values of [bitsPerValue] bits packed by
+// PackEnumSeq from the byte array. The bits after the last value must be 0.
+func (p *Packer) UnpackEnumSeq(count int, bitsPerValue uint8) []byte {
+	if count < 0 || bitsPerValue == 0 || bitsPerValue > 8 {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	seqLen := enumSeqLen(count, bitsPerValue)
+	if seqLen > p.Remaining() {
+		p.Add(errBadLength)
+		return nil
+	}
+	seq := p.UnpackFixedBytes(seqLen)
+	if p.Errored() {
+		return nil
+	}
+
+	values := make([]byte, count)
+	bit := 0
+	for i := range values {
+		for j := uint8(0); j < bitsPerValue; j++ {
+			values[i] |= (seq[bit/8] >> (bit % 8) & 1) << j
+			bit++
+		}
+	}
+	// Padding must be 0 so that each sequence has one encoding
+	if bit%8 != 0 && seq[bit/8]>>(bit%8) != 0 {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	return values
+}
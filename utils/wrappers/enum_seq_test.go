@@ -0,0 +1,82 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPackerEnumSeq(t *testing.T) {
+	// Statuses of 4 possible values take 2 bits each
+	values := []byte{0, 1, 2, 3, 3, 2, 1, 0, 1, 1, 2, 3, 0, 0, 2, 1, 3, 2}
+
+	p := Packer{MaxSize: len(values)}
+	p.PackEnumSeq(values, 2)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if expectedLen := (len(values)*2 + 7) / 8; len(p.Bytes) != expectedLen {
+		t.Fatalf("Packer.PackEnumSeq wrote %d bytes, expected %d", len(p.Bytes), expectedLen)
+	}
+	if expected := []byte{0xe4, 0x1b, 0xe5, 0x60, 0x0b}; !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackEnumSeq wrote %x, expected %x", p.Bytes, expected)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	if unpacked := p2.UnpackEnumSeq(len(values), 2); p2.Errored() {
+		t.Fatal(p2.Err)
+	} else if !bytes.Equal(unpacked, values) {
+		t.Fatalf("Packer.UnpackEnumSeq returned %v, expected %v", unpacked, values)
+	}
+	if p2.Offset != len(p2.Bytes) {
+		t.Fatalf("Packer.UnpackEnumSeq left %d unread bytes", len(p2.Bytes)-p2.Offset)
+	}
+}
+
+func TestPackerEnumSeqWidths(t *testing.T) {
+	for bitsPerValue := uint8(1); bitsPerValue <= 8; bitsPerValue++ {
+		max := byte(1<<bitsPerValue - 1)
+		values := []byte{max, 0, max / 2, 1, max}
+
+		p := Packer{MaxSize: len(values)}
+		p.PackEnumSeq(values, bitsPerValue)
+		p2 := Packer{Bytes: p.Bytes}
+		if unpacked := p2.UnpackEnumSeq(len(values), bitsPerValue); p2.Errored() {
+			t.Fatalf("%d bits: %s", bitsPerValue, p2.Err)
+		} else if !bytes.Equal(unpacked, values) {
+			t.Fatalf("%d bits: Packer.UnpackEnumSeq returned %v, expected %v", bitsPerValue, unpacked, values)
+		}
+	}
+
+	p := Packer{MaxSize: 1}
+	if p.PackEnumSeq(nil, 3); p.Errored() || len(p.Bytes) != 0 {
+		t.Fatal("an empty sequence should have been packed into 0 bytes")
+	}
+}
+
+func TestPackerEnumSeqInvalid(t *testing.T) {
+	p := Packer{MaxSize: 16}
+	if p.PackEnumSeq([]byte{0, 4, 1}, 2); p.Err != errEnumTooWide {
+		t.Fatalf("Packer.PackEnumSeq should have failed with %s but returned %v", errEnumTooWide, p.Err)
+	}
+	for _, bitsPerValue := range []uint8{0, 9} {
+		p := Packer{MaxSize: 16}
+		if p.PackEnumSeq([]byte{0}, bitsPerValue); p.Err != errInvalidInput {
+			t.Fatalf("%d bits: Packer.PackEnumSeq should have failed with %s but returned %v", bitsPerValue, errInvalidInput, p.Err)
+		}
+	}
+
+	// Non-zero padding
+	p2 := Packer{Bytes: []byte{0x40}}
+	if p2.UnpackEnumSeq(3, 2); p2.Err != errInvalidInput {
+		t.Fatalf("Packer.UnpackEnumSeq should have failed with %s but returned %v", errInvalidInput, p2.Err)
+	}
+
+	// Not enough bytes
+	p3 := Packer{Bytes: []byte{0xff}}
+	if values := p3.UnpackEnumSeq(5, 2); !p3.Errored() || values != nil {
+		t.Fatal("Packer.UnpackEnumSeq should have failed on a truncated sequence")
+	}
+}
@@ -0,0 +1,90 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"time"
+)
+
+// minEventLogEntryLen is the minimum number of bytes of a packed entry: a one
+// byte varint sequence, a timestamp, and an empty payload
+const minEventLogEntryLen = 1 + LongLen + IntLen
+
+var errEventsOutOfOrder = errors.New("event sequence numbers must be increasing")
+
+// EventLogEntry is an event in an ordered event log
+type EventLogEntry struct {
+	Seq     uint64
+	Time    time.Time
+	Payload []byte
+}
+
+// PackEventLogEntry appends an event with sequence number [seq], timestamp [t]
+// and [payload] to the byte array. [t] is packed with nanosecond precision and
+// its location isn't preserved.
+func (p *Packer) PackEventLogEntry(seq uint64, t time.Time, payload []byte) {
+	p.PackVarInt(seq)
+	p.PackLong(uint64(t.UnixNano()))
+	p.PackBytes(payload)
+}
+
+// UnpackEventLogEntry unpacks an event packed by PackEventLogEntry from the
+// byte array and returns its sequence number, timestamp and payload
+func (p *Packer) UnpackEventLogEntry() (uint64, time.Time, []byte) {
+	seq := p.UnpackVarInt()
+	t := time.Unix(0, int64(p.UnpackLong()))
+	payload := p.UnpackBytes()
+	if p.Errored() {
+		return 0, time.Time{}, nil
+	}
+	return seq, t, payload
+}
+
+// PackEventLog appends [entries], whose sequence numbers must be increasing, to
+// the byte array. Each entry is packed by PackEventLogEntry with its sequence
+// number replaced by the difference from the previous entry's, so consecutive
+// sequence numbers take a single byte.
+func (p *Packer) PackEventLog(entries []EventLogEntry) {
+	p.PackVarInt(uint64(len(entries)))
+	prevSeq := uint64(0)
+	for i, entry := range entries {
+		if i > 0 && entry.Seq <= prevSeq {
+			p.Add(errEventsOutOfOrder)
+			return
+		}
+		p.PackEventLogEntry(entry.Seq-prevSeq, entry.Time, entry.Payload)
+		prevSeq = entry.Seq
+	}
+}
+
+// UnpackEventLog unpacks the entries packed by PackEventLog from the byte array
+func (p *Packer) UnpackEventLog() []EventLogEntry {
+	numEntries := p.UnpackVarInt()
+	if p.Errored() {
+		return nil
+	}
+	if numEntries > uint64(p.Remaining())/minEventLogEntryLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	entries := make([]EventLogEntry, numEntries)
+	prevSeq := uint64(0)
+	for i := range entries {
+		delta, t, payload := p.UnpackEventLogEntry()
+		if p.Errored() {
+			return nil
+		}
+		// Every entry after the first must have a larger sequence number
+		seq := prevSeq + delta
+		if i > 0 && (delta == 0 || seq < prevSeq) {
+			p.Add(errEventsOutOfOrder)
+			return nil
+		}
+		entries[i] = EventLogEntry{Seq: seq, Time: t, Payload: payload}
+		prevSeq = seq
+	}
+	return entries
+}
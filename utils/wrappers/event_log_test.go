@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPackerEventLogEntry(t *testing.T) {
+	now := time.Unix(1588000000, 123456789)
+
+	p := Packer{MaxSize: 1024}
+	p.PackEventLogEntry(7, now, []byte("created"))
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	seq, eventTime, payload := p2.UnpackEventLogEntry()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if seq != 7 || !eventTime.Equal(now) || !bytes.Equal(payload, []byte("created")) {
+		t.Fatalf("Packer.UnpackEventLogEntry returned (%d, %s, %q)", seq, eventTime, payload)
+	}
+	if p2.Offset != len(p2.Bytes) {
+		t.Fatalf("Packer.UnpackEventLogEntry left %d unread bytes", len(p2.Bytes)-p2.Offset)
+	}
+}
+
+func TestPackerEventLog(t *testing.T) {
+	start := time.Unix(1588000000, 0)
+	entries := []EventLogEntry{
+		{Seq: 1000, Time: start, Payload: []byte("created")},
+		{Seq: 1001, Time: start.Add(time.Millisecond), Payload: []byte("updated")},
+		{Seq: 1002, Time: start.Add(time.Second), Payload: []byte{}},
+		{Seq: 5000, Time: start.Add(time.Second), Payload: []byte("deleted")},
+		{Seq: math.MaxUint64, Time: start.Add(-time.Hour), Payload: []byte("restored")},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackEventLog(entries)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpacked := p2.UnpackEventLog()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if len(unpacked) != len(entries) {
+		t.Fatalf("Packer.UnpackEventLog returned %d entries, expected %d", len(unpacked), len(entries))
+	}
+	for i, entry := range unpacked {
+		expected := entries[i]
+		if entry.Seq != expected.Seq || !entry.Time.Equal(expected.Time) || !bytes.Equal(entry.Payload, expected.Payload) {
+			t.Fatalf("Packer.UnpackEventLog returned %+v at index %d, expected %+v", entry, i, expected)
+		}
+	}
+	if p2.Offset != len(p2.Bytes) {
+		t.Fatalf("Packer.UnpackEventLog left %d unread bytes", len(p2.Bytes)-p2.Offset)
+	}
+}
+
+func TestPackerPackEventLogOutOfOrder(t *testing.T) {
+	now := time.Unix(1588000000, 0)
+	for _, entries := range [][]EventLogEntry{
+		{{Seq: 2, Time: now}, {Seq: 1, Time: now}},
+		{{Seq: 2, Time: now}, {Seq: 2, Time: now}},
+	} {
+		p := Packer{MaxSize: 1024}
+		p.PackEventLog(entries)
+		if !p.Errored() {
+			t.Fatalf("Packer.PackEventLog should have errored on sequences %d, %d", entries[0].Seq, entries[1].Seq)
+		}
+	}
+}
+
+func TestPackerUnpackEventLogInvalid(t *testing.T) {
+	now := time.Unix(1588000000, 0)
+
+	// A zero delta after the first entry repeats a sequence number
+	p := Packer{MaxSize: 1024}
+	p.PackVarInt(2)
+	p.PackEventLogEntry(5, now, nil)
+	p.PackEventLogEntry(0, now, nil)
+	p2 := Packer{Bytes: p.Bytes}
+	if entries := p2.UnpackEventLog(); !p2.Errored() || entries != nil {
+		t.Fatalf("Packer.UnpackEventLog should have errored on a repeated sequence number")
+	}
+
+	// A delta that overflows the sequence number
+	p = Packer{MaxSize: 1024}
+	p.PackVarInt(2)
+	p.PackEventLogEntry(5, now, nil)
+	p.PackEventLogEntry(math.MaxUint64, now, nil)
+	p2 = Packer{Bytes: p.Bytes}
+	if entries := p2.UnpackEventLog(); !p2.Errored() || entries != nil {
+		t.Fatalf("Packer.UnpackEventLog should have errored on an overflowing sequence number")
+	}
+
+	// More entries than could fit in the remaining bytes
+	p2 = Packer{Bytes: []byte{0x10, 0x00}}
+	if entries := p2.UnpackEventLog(); !p2.Errored() || entries != nil {
+		t.Fatalf("Packer.UnpackEventLog should have errored on too many entries")
+	}
+}
@@ -0,0 +1,54 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+// Markers that precede each field of an extensible tail
+const (
+	tailEnd  byte = 0 // No more fields follow
+	tailMore byte = 1 // A length prefixed field follows
+)
+
+// PackExtensibleTail appends optional trailing [fields] to the byte array.
+// Each field is packed as a continuation marker followed by the field's bytes,
+// and the tail is terminated by an end marker. Fields should only ever be added
+// to the end of a tail, so that readers that know about fewer fields can skip
+// the ones they don't recognize.
+func (p *Packer) PackExtensibleTail(fields [][]byte) {
+	for _, field := range fields {
+		p.PackByte(tailMore)
+		p.PackBytes(field)
+	}
+	p.PackByte(tailEnd)
+}
+
+// UnpackExtensibleTail unpacks a tail packed by PackExtensibleTail from the
+// byte array. Up to [known] fields are returned. Fewer are returned if the
+// writer packed fewer, and any fields after the first [known] are skipped
+// without being allocated.
+func (p *Packer) UnpackExtensibleTail(known int) [][]byte {
+	fields := [][]byte(nil)
+	for !p.Errored() {
+		switch marker := p.UnpackByte(); marker {
+		case tailEnd:
+			if p.Errored() {
+				return nil
+			}
+			return fields
+		case tailMore:
+			if len(fields) < known {
+				fields = append(fields, p.UnpackBytes())
+				continue
+			}
+			// Skip a field added by a newer writer
+			size := int(p.UnpackInt())
+			p.CheckSpace(size)
+			if !p.Errored() {
+				p.Offset += size
+			}
+		default:
+			p.Add(errInvalidInput)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+)
+
+// packVersionedMessage packs a message with a fixed id and name, followed by
+// a tail of the optional [fields] known by the writer's version
+func packVersionedMessage(fields [][]byte) []byte {
+	p := Packer{MaxSize: 1024}
+	p.PackInt(7)
+	p.PackStr("node")
+	p.PackExtensibleTail(fields)
+	p.PackStr("trailer")
+	return p.Bytes
+}
+
+func TestPackerExtensibleTailOldReader(t *testing.T) {
+	// The new format added two optional fields after the description
+	newFields := [][]byte{[]byte("description"), {0x00, 0x01}, []byte("extra")}
+	msg := packVersionedMessage(newFields)
+
+	// An old reader only knows about the description
+	p := Packer{Bytes: msg}
+	id := p.UnpackInt()
+	name := p.UnpackStr()
+	fields := p.UnpackExtensibleTail(1)
+	trailer := p.UnpackStr()
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if id != 7 || name != "node" || trailer != "trailer" {
+		t.Fatalf("Packer.UnpackExtensibleTail misaligned the message: (%d, %q, %q)", id, name, trailer)
+	}
+	if len(fields) != 1 || !bytes.Equal(fields[0], newFields[0]) {
+		t.Fatalf("Packer.UnpackExtensibleTail returned %v, expected %v", fields, newFields[:1])
+	}
+	if p.Offset != len(p.Bytes) {
+		t.Fatalf("Packer left %d unread bytes", p.Remaining())
+	}
+}
+
+func TestPackerExtensibleTailNewReader(t *testing.T) {
+	oldFields := [][]byte{[]byte("description")}
+	msg := packVersionedMessage(oldFields)
+
+	// A new reader knows about more fields than the writer packed
+	p := Packer{Bytes: msg}
+	p.UnpackInt()
+	p.UnpackStr()
+	fields := p.UnpackExtensibleTail(3)
+	trailer := p.UnpackStr()
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if len(fields) != 1 || !bytes.Equal(fields[0], oldFields[0]) {
+		t.Fatalf("Packer.UnpackExtensibleTail returned %v, expected %v", fields, oldFields)
+	}
+	if trailer != "trailer" {
+		t.Fatalf("Packer.UnpackExtensibleTail misaligned the message: %q", trailer)
+	}
+}
+
+func TestPackerExtensibleTailEmpty(t *testing.T) {
+	p := Packer{MaxSize: 1}
+	p.PackExtensibleTail(nil)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if !bytes.Equal(p.Bytes, []byte{tailEnd}) {
+		t.Fatalf("Packer.PackExtensibleTail packed %v", p.Bytes)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	if fields := p2.UnpackExtensibleTail(2); p2.Errored() || len(fields) != 0 {
+		t.Fatalf("Packer.UnpackExtensibleTail returned (%v, %v)", fields, p2.Err)
+	}
+}
+
+func TestPackerUnpackExtensibleTailInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+	}{
+		{name: "bad marker", bytes: []byte{0x02}},
+		{name: "missing terminator", bytes: []byte{tailMore, 0x00, 0x00, 0x00, 0x00}},
+		{name: "truncated skipped field", bytes: []byte{tailMore, 0x00, 0x00, 0x00, 0x00, tailMore, 0x00, 0x00, 0x00, 0x05, 0x01}},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		if fields := p.UnpackExtensibleTail(1); !p.Errored() || fields != nil {
+			t.Fatalf("%s: Packer.UnpackExtensibleTail should have failed but returned %v", test.name, fields)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// MaxGeohashLen is the maximum number of characters of a geohash. A
+	// geohash of this length locates a point to within a few centimeters.
+	MaxGeohashLen = 12
+
+	// geohashAlphabet is the base32 alphabet of geohashes. It omits a, i, l
+	// and o.
+	geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+var (
+	errInvalidGeohash = errors.New("geohash must be 1 to 12 characters of the geohash alphabet")
+	errInvalidLatLon  = errors.New("latitude must be in [-90, 90] and longitude must be in [-180, 180]")
+)
+
+// Geohash returns the geohash of the point at [lat], [lon] with [precision]
+// characters. Each character halves the cell that contains the point 5 times,
+// alternating between longitude and latitude, starting with longitude.
+func Geohash(lat, lon float64, precision int) (string, error) {
+	if precision < 1 || precision > MaxGeohashLen {
+		return "", errInvalidGeohash
+	}
+	// Written so that NaN fails the checks
+	if !(lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180) {
+		return "", errInvalidLatLon
+	}
+
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	geohash := make([]byte, precision)
+	evenBit := true
+	for i := range geohash {
+		index := 0
+		for bit := 0; bit < 5; bit++ {
+			rng, val := &latRange, lat
+			if evenBit {
+				rng, val = &lonRange, lon
+			}
+			mid := (rng[0] + rng[1]) / 2
+			index <<= 1
+			if val >= mid {
+				index |= 1
+				rng[0] = mid
+			} else {
+				rng[1] = mid
+			}
+			evenBit = !evenBit
+		}
+		geohash[i] = geohashAlphabet[index]
+	}
+	return string(geohash), nil
+}
+
+// validGeohash returns true if [geohash] is a geohash of at most
+// [MaxGeohashLen] characters
+func validGeohash(geohash string) bool {
+	if len(geohash) == 0 || len(geohash) > MaxGeohashLen {
+		return false
+	}
+	for i := 0; i < len(geohash); i++ {
+		if strings.IndexByte(geohashAlphabet, geohash[i]) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// PackGeoRecord appends a record located at [geohash] to the byte array.
+// Records that share a geohash prefix are near each other, so records can be
+// indexed by their geohash for spatial queries. [geohash] must be lowercase.
+func (p *Packer) PackGeoRecord(geohash string, payload []byte) {
+	if !validGeohash(geohash) {
+		p.Add(errInvalidGeohash)
+		return
+	}
+	p.PackStr(geohash)
+	p.PackBytes(payload)
+}
+
+// UnpackGeoRecord unpacks the geohash and payload of a record packed by
+// PackGeoRecord from the byte array
+func (p *Packer) UnpackGeoRecord() (string, []byte) {
+	geohash := p.UnpackLimitedStr(MaxGeohashLen)
+	if p.Errored() {
+		return "", nil
+	}
+	if !validGeohash(geohash) {
+		p.Add(errInvalidGeohash)
+		return "", nil
+	}
+	payload := p.UnpackBytes()
+	if p.Errored() {
+		return "", nil
+	}
+	return geohash, payload
+}
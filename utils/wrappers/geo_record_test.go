@@ -0,0 +1,91 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestGeohash(t *testing.T) {
+	tests := []struct {
+		lat, lon  float64
+		precision int
+		expected  string
+	}{
+		{lat: 57.64911, lon: 10.40744, precision: 11, expected: "u4pruydqqvj"},
+		{lat: 42.6, lon: -5.6, precision: 5, expected: "ezs42"},
+		{lat: -25.382708, lon: -49.265506, precision: 8, expected: "6gkzwgjz"},
+		{lat: 0, lon: 0, precision: 1, expected: "s"},
+		{lat: -90, lon: -180, precision: MaxGeohashLen, expected: "000000000000"},
+	}
+	for _, test := range tests {
+		geohash, err := Geohash(test.lat, test.lon, test.precision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if geohash != test.expected {
+			t.Fatalf("geohash of (%f, %f) should have been %s but was %s", test.lat, test.lon, test.expected, geohash)
+		}
+	}
+
+	for _, precision := range []int{0, MaxGeohashLen + 1} {
+		if _, err := Geohash(0, 0, precision); err != errInvalidGeohash {
+			t.Fatalf("precision %d should have failed with %s but returned %v", precision, errInvalidGeohash, err)
+		}
+	}
+	for _, latLon := range [][2]float64{{91, 0}, {0, -181}, {math.NaN(), 0}} {
+		if _, err := Geohash(latLon[0], latLon[1], 5); err != errInvalidLatLon {
+			t.Fatalf("(%f, %f) should have failed with %s but returned %v", latLon[0], latLon[1], errInvalidLatLon, err)
+		}
+	}
+}
+
+func TestPackerGeoRecord(t *testing.T) {
+	geohash, err := Geohash(57.64911, 10.40744, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("lighthouse")
+
+	p := Packer{MaxSize: 1024}
+	p.PackGeoRecord(geohash, payload)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpackedGeohash, unpackedPayload := p2.UnpackGeoRecord()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if unpackedGeohash != geohash || !bytes.Equal(unpackedPayload, payload) {
+		t.Fatalf("Packer.UnpackGeoRecord returned (%s, %q), expected (%s, %q)", unpackedGeohash, unpackedPayload, geohash, payload)
+	}
+
+	p3 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if unpackedGeohash, _ := p3.UnpackGeoRecord(); !p3.Errored() || unpackedGeohash != "" {
+		t.Fatal("Packer.UnpackGeoRecord should have failed on a truncated record")
+	}
+}
+
+func TestPackerGeoRecordInvalidGeohash(t *testing.T) {
+	for _, geohash := range []string{"", "u4pra", "U4PRU", "u4pruydqqvjxx", "u4 pr"} {
+		p := Packer{MaxSize: 1024}
+		p.PackGeoRecord(geohash, []byte("payload"))
+		if p.Err != errInvalidGeohash {
+			t.Fatalf("packing geohash %q should have failed with %s but returned %v", geohash, errInvalidGeohash, p.Err)
+		}
+	}
+
+	// A record with an invalid geohash can't be unpacked
+	p := Packer{MaxSize: 1024}
+	p.PackStr("u4pri")
+	p.PackBytes([]byte("payload"))
+	p2 := Packer{Bytes: p.Bytes}
+	if p2.UnpackGeoRecord(); p2.Err != errInvalidGeohash {
+		t.Fatalf("Packer.UnpackGeoRecord should have failed with %s but returned %v", errInvalidGeohash, p2.Err)
+	}
+}
@@ -0,0 +1,51 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"time"
+)
+
+// PackHistogram appends a histogram to the byte array. Bucket i counts the
+// events in [baseTime + i*bucketWidth, baseTime + (i+1)*bucketWidth), where
+// [baseTime] is in nanoseconds since the Unix epoch. Since the counts are
+// packed as varints, empty and small buckets take a single byte each.
+// [bucketWidth] must be positive.
+func (p *Packer) PackHistogram(baseTime int64, bucketWidth time.Duration, counts []uint64) {
+	if bucketWidth <= 0 {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackLong(uint64(baseTime))
+	p.PackLong(uint64(bucketWidth))
+	p.PackVarInt(uint64(len(counts)))
+	for _, count := range counts {
+		p.PackVarInt(count)
+	}
+}
+
+// UnpackHistogram unpacks a histogram packed by PackHistogram from the byte
+// array. Returns its base time, bucket width and counts.
+func (p *Packer) UnpackHistogram() (int64, time.Duration, []uint64) {
+	baseTime := int64(p.UnpackLong())
+	bucketWidth := time.Duration(p.UnpackLong())
+	numBuckets := p.UnpackVarInt()
+	if p.Errored() {
+		return 0, 0, nil
+	}
+	// Every count takes at least 1 byte
+	if bucketWidth <= 0 || numBuckets > uint64(p.Remaining()) {
+		p.Add(errInvalidInput)
+		return 0, 0, nil
+	}
+
+	counts := make([]uint64, numBuckets)
+	for i := range counts {
+		counts[i] = p.UnpackVarInt()
+	}
+	if p.Errored() {
+		return 0, 0, nil
+	}
+	return baseTime, bucketWidth, counts
+}
@@ -0,0 +1,68 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPackerHistogram(t *testing.T) {
+	baseTime := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	counts := []uint64{0, 0, 3, 0, 127, 128, 0, math.MaxUint64, 0, 1}
+
+	p := Packer{MaxSize: 1024}
+	p.PackHistogram(baseTime, time.Minute, counts)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	// The base time, bucket width, number of buckets, and 1 byte per count
+	// except for 128 (2 bytes) and MaxUint64 (10 bytes)
+	if expected := 2*LongLen + 1 + len(counts) + 1 + 9; len(p.Bytes) != expected {
+		t.Fatalf("Packer.PackHistogram packed %d bytes, expected %d", len(p.Bytes), expected)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpackedBaseTime, bucketWidth, unpacked := p2.UnpackHistogram()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if unpackedBaseTime != baseTime || bucketWidth != time.Minute {
+		t.Fatalf("Packer.UnpackHistogram returned base time %d and width %s, expected %d and %s", unpackedBaseTime, bucketWidth, baseTime, time.Minute)
+	}
+	if len(unpacked) != len(counts) {
+		t.Fatalf("Packer.UnpackHistogram returned %d buckets, expected %d", len(unpacked), len(counts))
+	}
+	for i, count := range unpacked {
+		if count != counts[i] {
+			t.Fatalf("bucket %d has count %d, expected %d", i, count, counts[i])
+		}
+	}
+}
+
+func TestPackerHistogramInvalid(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	if p.PackHistogram(0, 0, nil); p.Err != errInvalidInput {
+		t.Fatalf("Packer.PackHistogram should have failed with %s but failed with %v", errInvalidInput, p.Err)
+	}
+
+	// Zero bucket width
+	p2 := Packer{MaxSize: 1024}
+	p2.PackLong(0)
+	p2.PackLong(0)
+	p2.PackVarInt(0)
+	p3 := Packer{Bytes: p2.Bytes}
+	if _, _, counts := p3.UnpackHistogram(); p3.Err != errInvalidInput || counts != nil {
+		t.Fatalf("Packer.UnpackHistogram should have failed with %s but failed with %v", errInvalidInput, p3.Err)
+	}
+
+	// Truncated counts
+	p4 := Packer{MaxSize: 1024}
+	p4.PackHistogram(0, time.Second, []uint64{1, 2, 300})
+	p5 := Packer{Bytes: p4.Bytes[:len(p4.Bytes)-1]}
+	if _, _, counts := p5.UnpackHistogram(); !p5.Errored() || counts != nil {
+		t.Fatal("Packer.UnpackHistogram should have failed on truncated counts")
+	}
+}
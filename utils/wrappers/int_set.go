@@ -0,0 +1,137 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"sort"
+)
+
+// Encodings of a set of integers
+const (
+	intSetList   byte = 0 // Varint count followed by varint deltas
+	intSetBitmap byte = 1 // One bit per integer in the universe
+)
+
+// PackIntSetBitmap appends the set of [indices], each of which must be less
+// than [universe], to the byte array. The set is packed as a bitmap of
+// [universe] bits if that's smaller than packing a sorted list of its elements,
+// which is the case when the set is dense. A header byte marks which encoding
+// was chosen. Duplicate indices are only packed once.
+func (p *Packer) PackIntSetBitmap(indices []uint32, universe uint32) {
+	sorted := make([]uint32, 0, len(indices))
+	for _, index := range indices {
+		if index >= universe {
+			p.Add(errInvalidInput)
+			return
+		}
+		sorted = append(sorted, index)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	set := sorted[:0]
+	for i, index := range sorted {
+		if i == 0 || index != sorted[i-1] {
+			set = append(set, index)
+		}
+	}
+
+	// Each element of the list is packed as the difference from the previous
+	// element
+	listLen := varIntLen(uint64(len(set)))
+	prev := uint32(0)
+	for _, index := range set {
+		listLen += varIntLen(uint64(index - prev))
+		prev = index
+	}
+	bitmapLen := int((uint64(universe) + 7) / 8)
+
+	if bitmapLen < listLen {
+		bitmap := make([]byte, bitmapLen)
+		for _, index := range set {
+			bitmap[index/8] |= 1 << (index % 8)
+		}
+		p.PackByte(intSetBitmap)
+		p.PackFixedBytes(bitmap)
+		return
+	}
+
+	p.PackByte(intSetList)
+	p.PackVarInt(uint64(len(set)))
+	prev = 0
+	for _, index := range set {
+		p.PackVarInt(uint64(index - prev))
+		prev = index
+	}
+}
+
+// UnpackIntSetBitmap unpacks a set packed by PackIntSetBitmap with the same
+// [universe] from the byte array. The indices are returned in increasing order.
+func (p *Packer) UnpackIntSetBitmap(universe uint32) []uint32 {
+	switch p.UnpackByte() {
+	case intSetBitmap:
+		bitmap := p.UnpackFixedBytes(int((uint64(universe) + 7) / 8))
+		if p.Errored() {
+			return nil
+		}
+		set := []uint32{}
+		for i, b := range bitmap {
+			for bit := uint32(0); bit < 8 && b != 0; bit++ {
+				if b&(1<<bit) == 0 {
+					continue
+				}
+				index := uint32(i)*8 + bit
+				if index >= universe {
+					// Bits past the universe must be unset
+					p.Add(errInvalidInput)
+					return nil
+				}
+				set = append(set, index)
+			}
+		}
+		return set
+	case intSetList:
+		numIndices := p.UnpackVarInt()
+		if p.Errored() {
+			return nil
+		}
+		// Every index takes at least 1 byte
+		if numIndices > uint64(universe) || numIndices > uint64(p.Remaining()) {
+			p.Add(errInvalidInput)
+			return nil
+		}
+		set := make([]uint32, numIndices)
+		index := uint64(0)
+		for i := range set {
+			delta := p.UnpackVarInt()
+			if p.Errored() {
+				return nil
+			}
+			// Indices must be unique and increasing
+			if i > 0 && delta == 0 {
+				p.Add(errInvalidInput)
+				return nil
+			}
+			index += delta
+			if delta >= uint64(universe) || index >= uint64(universe) {
+				p.Add(errInvalidInput)
+				return nil
+			}
+			set[i] = uint32(index)
+		}
+		return set
+	default:
+		if !p.Errored() {
+			p.Add(errInvalidInput)
+		}
+		return nil
+	}
+}
+
+// varIntLen returns the number of bytes PackVarInt packs [val] into
+func varIntLen(val uint64) int {
+	n := 1
+	for ; val >= 0x80; val >>= 7 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPackerIntSetBitmap(t *testing.T) {
+	dense := []uint32(nil)
+	for i := uint32(0); i < 100; i += 2 {
+		dense = append(dense, i)
+	}
+
+	tests := []struct {
+		name      string
+		indices   []uint32
+		universe  uint32
+		encoding  byte
+		packedLen int
+		expected  []uint32
+	}{
+		{
+			name:      "dense",
+			indices:   dense,
+			universe:  100,
+			encoding:  intSetBitmap,
+			packedLen: 1 + 13,
+			expected:  dense,
+		},
+		{
+			name:      "sparse",
+			indices:   []uint32{5000, 3, 70000},
+			universe:  100000,
+			encoding:  intSetList,
+			packedLen: 1 + 1 + 1 + 2 + 3,
+			expected:  []uint32{3, 5000, 70000},
+		},
+		{
+			name:      "duplicates",
+			indices:   []uint32{7, 7, 1},
+			universe:  1000,
+			encoding:  intSetList,
+			packedLen: 1 + 1 + 1 + 1,
+			expected:  []uint32{1, 7},
+		},
+		{
+			name:      "full",
+			indices:   []uint32{0, 1, 2, 3, 4, 5, 6, 7, 8},
+			universe:  9,
+			encoding:  intSetBitmap,
+			packedLen: 1 + 2,
+			expected:  []uint32{0, 1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			name:      "empty",
+			indices:   nil,
+			universe:  64,
+			encoding:  intSetList,
+			packedLen: 1 + 1,
+			expected:  []uint32{},
+		},
+	}
+	for _, test := range tests {
+		p := Packer{MaxSize: 1024}
+		p.PackIntSetBitmap(test.indices, test.universe)
+		if p.Errored() {
+			t.Fatalf("%s: %s", test.name, p.Err)
+		}
+		if p.Bytes[0] != test.encoding {
+			t.Fatalf("%s: Packer.PackIntSetBitmap chose encoding %d, expected %d", test.name, p.Bytes[0], test.encoding)
+		}
+		if len(p.Bytes) != test.packedLen {
+			t.Fatalf("%s: Packer.PackIntSetBitmap wrote %d bytes, expected %d", test.name, len(p.Bytes), test.packedLen)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		set := p2.UnpackIntSetBitmap(test.universe)
+		if p2.Errored() {
+			t.Fatalf("%s: %s", test.name, p2.Err)
+		}
+		if !reflect.DeepEqual(set, test.expected) {
+			t.Fatalf("%s: Packer.UnpackIntSetBitmap returned %v, expected %v", test.name, set, test.expected)
+		}
+		if p2.Offset != len(p2.Bytes) {
+			t.Fatalf("%s: Packer.UnpackIntSetBitmap left %d unread bytes", test.name, len(p2.Bytes)-p2.Offset)
+		}
+	}
+}
+
+func TestPackerPackIntSetBitmapOutOfUniverse(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackIntSetBitmap([]uint32{1, 10}, 10)
+	if !p.Errored() {
+		t.Fatal("Packer.PackIntSetBitmap should have errored on an index outside the universe")
+	}
+}
+
+func TestPackerUnpackIntSetBitmapInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+	}{
+		{name: "unknown encoding", bytes: []byte{2}},
+		{name: "bit past the universe", bytes: []byte{intSetBitmap, 0x00, 0x04}},
+		{name: "truncated bitmap", bytes: []byte{intSetBitmap, 0x00}},
+		{name: "repeated index", bytes: []byte{intSetList, 0x02, 0x01, 0x00}},
+		{name: "index past the universe", bytes: []byte{intSetList, 0x01, 0x0a}},
+		{name: "too many indices", bytes: []byte{intSetList, 0x0b, 0x00}},
+		{name: "truncated list", bytes: []byte{intSetList, 0x02, 0x01}},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		if set := p.UnpackIntSetBitmap(10); !p.Errored() || set != nil {
+			t.Fatalf("%s: Packer.UnpackIntSetBitmap should have errored", test.name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// minLogChainEntryLen is the minimum number of bytes of a packed entry: the
+// previous entry's hash and an empty payload
+const minLogChainEntryLen = hashing.HashLen + IntLen
+
+var errBrokenLogChain = errors.New("log entry doesn't follow the previous entry")
+
+// LogChainEntry is an entry of a hash-chained log. Each entry commits to the
+// entry before it, so that entries can't be modified, removed or reordered
+// without breaking the chain.
+type LogChainEntry struct {
+	PrevHash [hashing.HashLen]byte
+	Payload  []byte
+}
+
+// Hash returns the hash of this entry, which the next entry must carry as its
+// PrevHash
+func (e LogChainEntry) Hash() [hashing.HashLen]byte {
+	p := Packer{MaxSize: minLogChainEntryLen + len(e.Payload)}
+	p.PackFixedBytes(e.PrevHash[:])
+	p.PackBytes(e.Payload)
+	return hashing.ComputeHash256Array(p.Bytes)
+}
+
+// PackLogChain appends a log segment holding [payloads] to the byte array. The
+// first entry follows the entry with hash [prevHash]. Returns the hash of the
+// last entry, which the next segment should follow, or [prevHash] if there are
+// no payloads.
+func (p *Packer) PackLogChain(prevHash [hashing.HashLen]byte, payloads [][]byte) [hashing.HashLen]byte {
+	p.PackVarInt(uint64(len(payloads)))
+	for _, payload := range payloads {
+		entry := LogChainEntry{PrevHash: prevHash, Payload: payload}
+		p.PackFixedBytes(entry.PrevHash[:])
+		p.PackBytes(entry.Payload)
+		prevHash = entry.Hash()
+	}
+	return prevHash
+}
+
+// UnpackLogChain unpacks the log segment packed by PackLogChain from the byte
+// array. The entries aren't verified, see VerifyLogChain.
+func (p *Packer) UnpackLogChain() []LogChainEntry {
+	numEntries := p.UnpackVarInt()
+	if p.Errored() {
+		return nil
+	}
+	if numEntries > uint64(p.Remaining())/minLogChainEntryLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	entries := make([]LogChainEntry, numEntries)
+	for i := range entries {
+		copy(entries[i].PrevHash[:], p.UnpackFixedBytes(hashing.HashLen))
+		entries[i].Payload = p.UnpackBytes()
+		if p.Errored() {
+			return nil
+		}
+	}
+	return entries
+}
+
+// VerifyLogChain returns an error if an entry of [segment] doesn't carry the
+// hash of the entry before it. The first entry can only be checked against the
+// hash of the entry before the segment, and the last entry against the hash
+// returned by PackLogChain, so the caller must check those.
+func VerifyLogChain(segment []LogChainEntry) error {
+	for i := 1; i < len(segment); i++ {
+		if segment[i].PrevHash != segment[i-1].Hash() {
+			return fmt.Errorf("%w: entry %d", errBrokenLogChain, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestPackerLogChain(t *testing.T) {
+	genesis := hashing.ComputeHash256Array([]byte("genesis"))
+	payloads := [][]byte{
+		[]byte("opened"),
+		[]byte("deposited"),
+		{},
+		[]byte("closed"),
+	}
+
+	p := Packer{MaxSize: 1024}
+	head := p.PackLogChain(genesis, payloads)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	segment := p2.UnpackLogChain()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if len(segment) != len(payloads) {
+		t.Fatalf("Packer.UnpackLogChain returned %d entries, expected %d", len(segment), len(payloads))
+	}
+	for i, entry := range segment {
+		if !bytes.Equal(entry.Payload, payloads[i]) {
+			t.Fatalf("entry %d has payload %q, expected %q", i, entry.Payload, payloads[i])
+		}
+	}
+	if segment[0].PrevHash != genesis {
+		t.Fatal("the first entry should have followed the genesis hash")
+	}
+	if head != segment[len(segment)-1].Hash() {
+		t.Fatal("Packer.PackLogChain should have returned the hash of the last entry")
+	}
+	if err := VerifyLogChain(segment); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next segment continues the chain
+	p3 := Packer{MaxSize: 1024}
+	if next := p3.PackLogChain(head, nil); next != head {
+		t.Fatal("an empty segment should have returned the hash it follows")
+	}
+}
+
+func TestVerifyLogChainTampered(t *testing.T) {
+	payloads := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+
+	p := Packer{MaxSize: 1024}
+	p.PackLogChain([hashing.HashLen]byte{}, payloads)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// Modify the payload of a middle entry
+	tampered := append([]byte(nil), p.Bytes...)
+	i := bytes.Index(tampered, []byte{0, 0, 0, 1, 'b'})
+	tampered[i+IntLen] = 'x'
+
+	p2 := Packer{Bytes: tampered}
+	segment := p2.UnpackLogChain()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if err := VerifyLogChain(segment); !errors.Is(err, errBrokenLogChain) {
+		t.Fatalf("VerifyLogChain should have detected the modified entry but returned %v", err)
+	}
+
+	// Reorder two entries
+	p3 := Packer{Bytes: p.Bytes}
+	segment = p3.UnpackLogChain()
+	segment[1], segment[2] = segment[2], segment[1]
+	if err := VerifyLogChain(segment); !errors.Is(err, errBrokenLogChain) {
+		t.Fatalf("VerifyLogChain should have detected the reordered entries but returned %v", err)
+	}
+
+	// Remove an entry
+	p4 := Packer{Bytes: p.Bytes}
+	segment = p4.UnpackLogChain()
+	segment = append(segment[:1], segment[2:]...)
+	if err := VerifyLogChain(segment); !errors.Is(err, errBrokenLogChain) {
+		t.Fatalf("VerifyLogChain should have detected the removed entry but returned %v", err)
+	}
+}
+
+func TestPackerUnpackLogChainTruncated(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackLogChain([hashing.HashLen]byte{}, [][]byte{[]byte("a"), []byte("b")})
+
+	p2 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if segment := p2.UnpackLogChain(); !p2.Errored() || segment != nil {
+		t.Fatal("Packer.UnpackLogChain should have failed on a truncated segment")
+	}
+}
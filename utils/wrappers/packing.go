@@ -6,7 +6,10 @@ package wrappers
 import (
 	"encoding/binary"
 	"errors"
+	"hash"
 	"math"
+	"net"
+	"time"
 
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/hashing"
@@ -26,6 +29,10 @@ const (
 	LongLen = 8
 	// BoolLen is the number of bytes per bool
 	BoolLen = 1
+	// UUIDLen is the number of bytes per UUID
+	UUIDLen = 16
+	// MaxVarIntLen is the maximum number of bytes per varint
+	MaxVarIntLen = binary.MaxVarintLen64
 )
 
 var (
@@ -34,6 +41,10 @@ var (
 	errInvalidInput   = errors.New("input does not match expected format")
 	errBadType        = errors.New("wrong type passed")
 	errBadBool        = errors.New("unexpected value when unpacking bool")
+	errAllocBudget    = errors.New("variable length fields exceed the allocation budget")
+	errNoRunningHash  = errors.New("running hash isn't enabled")
+	errHashedWrite    = errors.New("can't overwrite bytes that have been hashed")
+	errInvertedRange  = errors.New("range start is after its end")
 )
 
 // Packer packs and unpacks a byte array from/to standard values
@@ -46,6 +57,85 @@ type Packer struct {
 	Bytes []byte
 	// The offset that is being written to in the byte array
 	Offset int
+	// If true, varints that aren't minimally encoded can be unpacked
+	AllowNonCanonicalVarInts bool
+	// The maximum total size of the variable length fields that can be
+	// unpacked. If 0, there is no limit.
+	AllocBudget int
+	// The total size of the variable length fields unpacked so far
+	allocated int
+
+	// If non-nil, the bytes packed so far are being hashed
+	hasher hash.Hash
+	// The number of bytes that have been written to [hasher]
+	hashed int
+}
+
+// Reset empties the packer so that it can pack again, reusing the capacity of
+// the byte array. The error, offset and allocation budget usage are cleared,
+// and if the running hash is enabled it restarts. MaxSize and the other
+// settings are kept.
+func (p *Packer) Reset() {
+	p.Err = nil
+	p.Bytes = p.Bytes[:0]
+	p.Offset = 0
+	p.allocated = 0
+	if p.hasher != nil {
+		p.hasher.Reset()
+		p.hashed = 0
+	}
+}
+
+// EnableRunningHash causes the packer to hash the byte array as it's packed,
+// so that RunningHash can return the hash without reading the whole byte array
+// again. Bytes that have been hashed can't be overwritten by WriteAt. Bytes are
+// hashed when the next value is packed, so a value packed before a call to
+// WriteAt can be overwritten if nothing has been packed after it.
+func (p *Packer) EnableRunningHash() {
+	p.hasher = hashing.NewHash256()
+	p.hashed = 0
+}
+
+// RunningHash returns the hash of the bytes packed so far, which is the same
+// as hashing.ComputeHash256(p.Bytes[:p.Offset]). EnableRunningHash must have
+// been called.
+func (p *Packer) RunningHash() []byte {
+	if p.hasher == nil {
+		p.Add(errNoRunningHash)
+		return nil
+	}
+	p.hash()
+	return p.hasher.Sum(nil)
+}
+
+// hash writes the bytes packed since the last call to the running hash
+func (p *Packer) hash() {
+	if p.hasher != nil && p.hashed < p.Offset {
+		// Writing to a hash never returns an error
+		p.hasher.Write(p.Bytes[p.hashed:p.Offset])
+		p.hashed = p.Offset
+	}
+}
+
+// Remaining returns the number of bytes of the byte array after the offset,
+// which are the bytes left to unpack or the space left to pack into without
+// expanding. Returns 0 if the offset is negative or past the end of the byte
+// array. Safe to call after the packer has errored.
+func (p *Packer) Remaining() int {
+	if p.Offset < 0 || p.Offset > len(p.Bytes) {
+		return 0
+	}
+	return len(p.Bytes) - p.Offset
+}
+
+// Processed returns the number of bytes that have been packed or unpacked,
+// which is the offset, or 0 if the offset is negative. Safe to call after the
+// packer has errored.
+func (p *Packer) Processed() int {
+	if p.Offset < 0 {
+		return 0
+	}
+	return p.Offset
 }
 
 // CheckSpace requires that there is at least [bytes] of write space left in the
@@ -56,7 +146,7 @@ func (p *Packer) CheckSpace(bytes int) {
 		p.Add(errNegativeOffset)
 	case bytes < 0:
 		p.Add(errInvalidInput)
-	case len(p.Bytes)-p.Offset < bytes:
+	case p.Offset > len(p.Bytes), p.Remaining() < bytes:
 		p.Add(errBadLength)
 	}
 }
@@ -69,6 +159,8 @@ func (p *Packer) Expand(bytes int) {
 	if p.Errored() {
 		return
 	}
+	// Hash the bytes already packed before anything is packed after them
+	p.hash()
 
 	neededSize := bytes + p.Offset
 	if neededSize <= len(p.Bytes) {
@@ -84,6 +176,58 @@ func (p *Packer) Expand(bytes int) {
 	}
 }
 
+// Reserve appends [bytes] zeroed bytes to the byte array, to be written later
+// using WriteAt. Returns the offset of the reserved bytes.
+func (p *Packer) Reserve(bytes int) int {
+	p.Expand(bytes)
+	if p.Errored() {
+		return 0
+	}
+
+	offset := p.Offset
+	for i := offset; i < offset+bytes; i++ {
+		p.Bytes[i] = 0
+	}
+	p.Offset += bytes
+	return offset
+}
+
+// WriteAt overwrites the bytes at [offset] with [bytes], without modifying the
+// packer's offset. The bytes being overwritten must have already been packed.
+func (p *Packer) WriteAt(offset int, bytes []byte) {
+	switch {
+	case p.Errored():
+		return
+	case offset < 0:
+		p.Add(errNegativeOffset)
+	case offset+len(bytes) > p.Offset:
+		p.Add(errBadLength)
+	case p.hasher != nil && offset < p.hashed && len(bytes) > 0:
+		p.Add(errHashedWrite)
+	default:
+		copy(p.Bytes[offset:], bytes)
+	}
+}
+
+// PeekAt returns the [size] bytes at [offset], without modifying the packer's
+// offset.
+func (p *Packer) PeekAt(offset, size int) []byte {
+	switch {
+	case p.Errored():
+		return nil
+	case offset < 0:
+		p.Add(errNegativeOffset)
+		return nil
+	case size < 0:
+		p.Add(errInvalidInput)
+		return nil
+	case len(p.Bytes)-offset < size:
+		p.Add(errBadLength)
+		return nil
+	}
+	return p.Bytes[offset : offset+size]
+}
+
 // PackByte append a byte to the byte array
 func (p *Packer) PackByte(val byte) {
 	p.Expand(ByteLen)
@@ -176,6 +320,71 @@ func (p *Packer) UnpackLong() uint64 {
 	return val
 }
 
+// PackSignedInt append a signed int to the byte array. The two's complement
+// bits are packed, so the wire format is the same as PackInt.
+func (p *Packer) PackSignedInt(val int32) { p.PackInt(uint32(val)) }
+
+// UnpackSignedInt unpack a signed int from the byte array
+func (p *Packer) UnpackSignedInt() int32 { return int32(p.UnpackInt()) }
+
+// PackSignedLong append a signed long to the byte array. The two's complement
+// bits are packed, so the wire format is the same as PackLong.
+func (p *Packer) PackSignedLong(val int64) { p.PackLong(uint64(val)) }
+
+// UnpackSignedLong unpack a signed long from the byte array
+func (p *Packer) UnpackSignedLong() int64 { return int64(p.UnpackLong()) }
+
+// PackFloat32 append a float32 to the byte array. The IEEE 754 bits are packed
+// as an int, so NaN payloads and infinities are preserved.
+func (p *Packer) PackFloat32(val float32) { p.PackInt(math.Float32bits(val)) }
+
+// UnpackFloat32 unpack a float32 from the byte array
+func (p *Packer) UnpackFloat32() float32 { return math.Float32frombits(p.UnpackInt()) }
+
+// PackFloat64 append a float64 to the byte array. The IEEE 754 bits are packed
+// as a long, so NaN payloads and infinities are preserved.
+func (p *Packer) PackFloat64(val float64) { p.PackLong(math.Float64bits(val)) }
+
+// UnpackFloat64 unpack a float64 from the byte array
+func (p *Packer) UnpackFloat64() float64 { return math.Float64frombits(p.UnpackLong()) }
+
+// PackVarInt append a variable length encoding of [val] to the byte array.
+// Each byte holds 7 bits of [val], least significant group first, with the
+// high bit set on every byte but the last.
+func (p *Packer) PackVarInt(val uint64) {
+	bytes := [MaxVarIntLen]byte{}
+	n := binary.PutUvarint(bytes[:], val)
+	p.PackFixedBytes(bytes[:n])
+}
+
+// UnpackVarInt unpack a variable length encoded integer from the byte array.
+// Unless [p.AllowNonCanonicalVarInts] is set, encodings that aren't minimal,
+// such as 0x80 0x00 for 0, are rejected so that every value has exactly one
+// encoding. Encodings longer than MaxVarIntLen bytes, or that overflow 64 bits,
+// are always rejected.
+func (p *Packer) UnpackVarInt() uint64 {
+	p.CheckSpace(0)
+	if p.Errored() {
+		return 0
+	}
+
+	val, n := binary.Uvarint(p.Bytes[p.Offset:])
+	switch {
+	case n == 0:
+		p.Add(errBadLength)
+		return 0
+	case n < 0:
+		p.Add(errInvalidInput)
+		return 0
+	case n > 1 && p.Bytes[p.Offset+n-1] == 0 && !p.AllowNonCanonicalVarInts:
+		// A trailing zero group adds nothing to the value
+		p.Add(errInvalidInput)
+		return 0
+	}
+	p.Offset += n
+	return val
+}
+
 // PackBool packs a bool into the byte array
 func (p *Packer) PackBool(b bool) {
 	if b {
@@ -199,6 +408,10 @@ func (p *Packer) UnpackBool() bool {
 	}
 }
 
+// UnpackBoolLenient unpacks a bool from the byte array. Unlike UnpackBool, any
+// non-zero byte is unpacked as true.
+func (p *Packer) UnpackBoolLenient() bool { return p.UnpackByte() != 0 }
+
 // PackFixedBytes append a byte slice, with no length descriptor to the byte
 // array
 func (p *Packer) PackFixedBytes(bytes []byte) {
@@ -233,9 +446,23 @@ func (p *Packer) PackBytes(bytes []byte) {
 // UnpackBytes unpack a byte slice from the byte array
 func (p *Packer) UnpackBytes() []byte {
 	size := p.UnpackInt()
+	p.spend(int(size))
 	return p.UnpackFixedBytes(int(size))
 }
 
+// spend deducts [size] bytes from the allocation budget. If the budget is
+// exceeded, an error is added to the packer.
+func (p *Packer) spend(size int) {
+	if p.Errored() || p.AllocBudget == 0 {
+		return
+	}
+	if size < 0 || size > p.AllocBudget-p.allocated {
+		p.Add(errAllocBudget)
+		return
+	}
+	p.allocated += size
+}
+
 // PackFixedByteSlices append a byte slice slice to the byte array
 func (p *Packer) PackFixedByteSlices(byteSlices [][]byte) {
 	p.PackInt(uint32(len(byteSlices)))
@@ -251,11 +478,46 @@ func (p *Packer) UnpackFixedByteSlices(size int) [][]byte {
 	sliceSize := p.UnpackInt()
 	bytes := [][]byte(nil)
 	for i := uint32(0); i < sliceSize && !p.Errored(); i++ {
+		p.spend(size)
 		bytes = append(bytes, p.UnpackFixedBytes(size))
 	}
 	return bytes
 }
 
+// PackUUID append a 128-bit UUID to the byte array
+func (p *Packer) PackUUID(uuid [UUIDLen]byte) { p.PackFixedBytes(uuid[:]) }
+
+// UnpackUUID unpack a 128-bit UUID from the byte array
+func (p *Packer) UnpackUUID() [UUIDLen]byte {
+	uuid := [UUIDLen]byte{}
+	copy(uuid[:], p.UnpackFixedBytes(UUIDLen))
+	return uuid
+}
+
+// PackOptionalHash appends a presence byte to the byte array, followed by
+// [hash] if it's non-nil
+func (p *Packer) PackOptionalHash(hash *[hashing.HashLen]byte) {
+	p.PackBool(hash != nil)
+	if hash != nil {
+		p.PackFixedBytes(hash[:])
+	}
+}
+
+// UnpackOptionalHash unpacks a hash packed by PackOptionalHash from the byte
+// array. Returns nil if the hash is absent.
+func (p *Packer) UnpackOptionalHash() *[hashing.HashLen]byte {
+	if !p.UnpackBool() {
+		return nil
+	}
+	bytes := p.UnpackFixedBytes(hashing.HashLen)
+	if p.Errored() {
+		return nil
+	}
+	hash := [hashing.HashLen]byte{}
+	copy(hash[:], bytes)
+	return &hash
+}
+
 // PackStr append a string to the byte array
 func (p *Packer) PackStr(str string) {
 	strSize := len(str)
@@ -269,9 +531,55 @@ func (p *Packer) PackStr(str string) {
 // UnpackStr unpacks a string from the byte array
 func (p *Packer) UnpackStr() string {
 	strSize := p.UnpackShort()
+	p.spend(int(strSize))
 	return string(p.UnpackFixedBytes(int(strSize)))
 }
 
+// UnpackLimitedStr unpacks a string of at most [maxSize] bytes from the byte
+// array. If the length prefix is larger than [maxSize], an error is added
+// without reading the string.
+func (p *Packer) UnpackLimitedStr(maxSize int) string {
+	strSize := p.UnpackShort()
+	if p.Errored() {
+		return ""
+	}
+	if int(strSize) > maxSize {
+		p.Add(errInvalidInput)
+		return ""
+	}
+	p.spend(int(strSize))
+	return string(p.UnpackFixedBytes(int(strSize)))
+}
+
+// PackTimeResolution appends [t] to the byte array as the number of [res]
+// units since the Unix epoch. [t] is truncated to a multiple of [res].
+// [t] can't be before the Unix epoch.
+func (p *Packer) PackTimeResolution(t time.Time, res time.Duration) {
+	if res <= 0 || t.Before(time.Unix(0, 0)) {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackVarInt(uint64(t.UnixNano() / int64(res)))
+}
+
+// UnpackTimeResolution unpacks a time packed by PackTimeResolution with the
+// same [res] from the byte array
+func (p *Packer) UnpackTimeResolution(res time.Duration) time.Time {
+	if res <= 0 {
+		p.Add(errInvalidInput)
+		return time.Time{}
+	}
+	units := p.UnpackVarInt()
+	if p.Errored() {
+		return time.Time{}
+	}
+	if units > math.MaxInt64/uint64(res) {
+		p.Add(errInvalidInput)
+		return time.Time{}
+	}
+	return time.Unix(0, int64(units)*int64(res))
+}
+
 // PackIP unpacks an ip port pair from the byte array
 func (p *Packer) PackIP(ip utils.IPDesc) {
 	p.PackFixedBytes(ip.IP.To16())
@@ -288,6 +596,49 @@ func (p *Packer) UnpackIP() utils.IPDesc {
 	}
 }
 
+// PackCompactIP appends an ip port pair to the byte array as its address family
+// (4 or 6), its 4 or 16 byte address and its port. IPv4 and IPv4-mapped IPv6
+// addresses are packed as IPv4 addresses. Unlike PackIP, IPv4 addresses aren't
+// expanded to 16 bytes.
+func (p *Packer) PackCompactIP(ip utils.IPDesc) {
+	if ip4 := ip.IP.To4(); ip4 != nil {
+		p.PackByte(4)
+		p.PackFixedBytes(ip4)
+	} else if ip16 := ip.IP.To16(); ip16 != nil {
+		p.PackByte(6)
+		p.PackFixedBytes(ip16)
+	} else {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackShort(ip.Port)
+}
+
+// UnpackCompactIP unpacks an ip port pair packed by PackCompactIP from the byte
+// array
+func (p *Packer) UnpackCompactIP() utils.IPDesc {
+	var ip []byte
+	switch family := p.UnpackByte(); {
+	case p.Errored():
+		return utils.IPDesc{}
+	case family == 4:
+		ip = p.UnpackFixedBytes(net.IPv4len)
+	case family == 6:
+		ip = p.UnpackFixedBytes(net.IPv6len)
+	default:
+		p.Add(errInvalidInput)
+		return utils.IPDesc{}
+	}
+	port := p.UnpackShort()
+	if p.Errored() {
+		return utils.IPDesc{}
+	}
+	return utils.IPDesc{
+		IP:   ip,
+		Port: port,
+	}
+}
+
 // PackIPs unpacks an ip port pair slice from the byte array
 func (p *Packer) PackIPs(ips []utils.IPDesc) {
 	p.PackInt(uint32(len(ips)))
@@ -306,6 +657,147 @@ func (p *Packer) UnpackIPs() []utils.IPDesc {
 	return ips
 }
 
+// PackCIDR appends [ipNet] to the byte array as its 16 byte network address
+// followed by its prefix length in bits. IPv4 networks are packed as IPv4-mapped
+// IPv6 networks.
+func (p *Packer) PackCIDR(ipNet *net.IPNet) {
+	ones, bits := ipNet.Mask.Size()
+	switch {
+	case bits == 8*net.IPv4len && ipNet.IP.To4() != nil:
+		ones += 8 * (net.IPv6len - net.IPv4len)
+	case bits != 8*net.IPv6len || ipNet.IP.To16() == nil:
+		// The mask is non-canonical or doesn't match the address
+		p.Add(errInvalidInput)
+		return
+	}
+	network := ipNet.IP.Mask(ipNet.Mask)
+	if network == nil {
+		// An IPv4 address with an IPv6 mask that doesn't cover the IPv4-mapped
+		// prefix
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackFixedBytes(network.To16())
+	p.PackByte(byte(ones))
+}
+
+// UnpackCIDR unpacks a network packed by PackCIDR from the byte array. The
+// prefix length must be at most 128 bits and the address must have no bits set
+// after the prefix. IPv4-mapped networks are returned as IPv4 networks.
+func (p *Packer) UnpackCIDR() (*net.IPNet, error) {
+	ip := net.IP(p.UnpackFixedBytes(net.IPv6len))
+	ones := int(p.UnpackByte())
+	if p.Errored() {
+		return nil, p.Err
+	}
+	if ones > 8*net.IPv6len {
+		p.Add(errInvalidInput)
+		return nil, p.Err
+	}
+
+	ipNet := &net.IPNet{IP: ip, Mask: net.CIDRMask(ones, 8*net.IPv6len)}
+	if ip4 := ip.To4(); ip4 != nil && ones >= 8*(net.IPv6len-net.IPv4len) {
+		ipNet = &net.IPNet{IP: ip4, Mask: net.CIDRMask(ones-8*(net.IPv6len-net.IPv4len), 8*net.IPv4len)}
+	}
+	if !ipNet.IP.Equal(ipNet.IP.Mask(ipNet.Mask)) {
+		p.Add(errInvalidInput)
+		return nil, p.Err
+	}
+	return ipNet, nil
+}
+
+// PackRange appends the inclusive range [[start], [end]] to the byte array as
+// [start] followed by the range's length, both as varints. [start] must be at
+// most [end].
+func (p *Packer) PackRange(start, end uint64) {
+	if start > end {
+		p.Add(errInvertedRange)
+		return
+	}
+	p.PackVarInt(start)
+	p.PackVarInt(end - start)
+}
+
+// UnpackRange unpacks a range packed by PackRange from the byte array and
+// returns its start and end
+func (p *Packer) UnpackRange() (uint64, uint64) {
+	start := p.UnpackVarInt()
+	length := p.UnpackVarInt()
+	if p.Errored() {
+		return 0, 0
+	}
+	if length > math.MaxUint64-start {
+		p.Add(errInvalidInput)
+		return 0, 0
+	}
+	return start, start + length
+}
+
+// PackCappedSlice packs the length of a slice, [n], followed by each of its
+// elements using [packElem]. If [n] is larger than [max], errInvalidInput is
+// added to the packer and nothing is packed.
+func (p *Packer) PackCappedSlice(n, max int, packElem func(i int)) {
+	if n < 0 || n > max {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackInt(uint32(n))
+	for i := 0; i < n && !p.Errored(); i++ {
+		packElem(i)
+	}
+}
+
+// UnpackCappedSlice unpacks the length of a slice, followed by each of its
+// elements using [unpackElem]. If the length is larger than [max],
+// errInvalidInput is added to the packer and no elements are unpacked.
+// Returns the number of elements in the slice.
+func (p *Packer) UnpackCappedSlice(max int, unpackElem func(i int)) int {
+	sliceSize := p.UnpackInt()
+	if p.Errored() {
+		return 0
+	}
+	if max < 0 || uint64(sliceSize) > uint64(max) {
+		p.Add(errInvalidInput)
+		return 0
+	}
+	n := int(sliceSize)
+	for i := 0; i < n && !p.Errored(); i++ {
+		unpackElem(i)
+	}
+	return n
+}
+
+// PackOffsetRef reserves space for a reference to a field that is packed later
+// in the byte array. Returns the offset of the reference, which should be
+// passed to ResolveOffsetRef immediately before the referenced field is packed.
+func (p *Packer) PackOffsetRef() int { return p.Reserve(IntLen) }
+
+// ResolveOffsetRef backfills the reference at [ref] with the current offset,
+// which should be the offset of the referenced field.
+func (p *Packer) ResolveOffsetRef(ref int) {
+	if p.Offset < 0 || uint64(p.Offset) > math.MaxUint32 {
+		p.Add(errInvalidInput)
+		return
+	}
+	offset := [IntLen]byte{}
+	binary.BigEndian.PutUint32(offset[:], uint32(p.Offset))
+	p.WriteAt(ref, offset[:])
+}
+
+// UnpackOffsetRef unpacks a reference packed by PackOffsetRef. Returns the
+// offset of the referenced field, which can be read using PeekAt.
+func (p *Packer) UnpackOffsetRef() int {
+	offset := p.UnpackInt()
+	if p.Errored() {
+		return 0
+	}
+	if uint64(offset) > uint64(len(p.Bytes)) {
+		p.Add(errInvalidInput)
+		return 0
+	}
+	return int(offset)
+}
+
 // TryPackByte attempts to pack the value as a byte
 func TryPackByte(packer *Packer, valIntf interface{}) {
 	if val, ok := valIntf.(uint8); ok {
@@ -362,6 +854,48 @@ func TryUnpackLong(packer *Packer) interface{} {
 	return packer.UnpackLong()
 }
 
+// TryPackFloat32 attempts to pack the value as a float32
+func TryPackFloat32(packer *Packer, valIntf interface{}) {
+	if val, ok := valIntf.(float32); ok {
+		packer.PackFloat32(val)
+	} else {
+		packer.Add(errBadType)
+	}
+}
+
+// TryUnpackFloat32 attempts to unpack a value as a float32
+func TryUnpackFloat32(packer *Packer) interface{} {
+	return packer.UnpackFloat32()
+}
+
+// TryPackFloat64 attempts to pack the value as a float64
+func TryPackFloat64(packer *Packer, valIntf interface{}) {
+	if val, ok := valIntf.(float64); ok {
+		packer.PackFloat64(val)
+	} else {
+		packer.Add(errBadType)
+	}
+}
+
+// TryUnpackFloat64 attempts to unpack a value as a float64
+func TryUnpackFloat64(packer *Packer) interface{} {
+	return packer.UnpackFloat64()
+}
+
+// TryPackUUID attempts to pack the value as a UUID
+func TryPackUUID(packer *Packer, valIntf interface{}) {
+	if val, ok := valIntf.([UUIDLen]byte); ok {
+		packer.PackUUID(val)
+	} else {
+		packer.Add(errBadType)
+	}
+}
+
+// TryUnpackUUID attempts to unpack a value as a UUID
+func TryUnpackUUID(packer *Packer) interface{} {
+	return packer.UnpackUUID()
+}
+
 // TryPackHash attempts to pack the value as a 32-byte sequence
 func TryPackHash(packer *Packer, valIntf interface{}) {
 	if val, ok := valIntf.([]byte); ok {
@@ -5,8 +5,15 @@ package wrappers
 
 import (
 	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/hashing"
 )
 
 const (
@@ -59,6 +66,101 @@ func TestPackerExpand(t *testing.T) {
 	}
 }
 
+func TestPackerRemainingProcessed(t *testing.T) {
+	p := Packer{Bytes: []byte{0, 0, 0, 1, 2}}
+	if remaining, processed := p.Remaining(), p.Processed(); remaining != 5 || processed != 0 {
+		t.Fatalf("Packer has %d remaining and %d processed bytes, expected 5 and 0", remaining, processed)
+	}
+	p.UnpackInt()
+	if remaining, processed := p.Remaining(), p.Processed(); remaining != 1 || processed != 4 {
+		t.Fatalf("Packer has %d remaining and %d processed bytes, expected 1 and 4", remaining, processed)
+	}
+
+	// Still safe to call once the packer has errored
+	p.UnpackInt()
+	if !p.Errored() {
+		t.Fatal("Packer.UnpackInt should have failed past the end of the byte array")
+	}
+	if remaining, processed := p.Remaining(), p.Processed(); remaining != 1 || processed != 4 {
+		t.Fatalf("Packer has %d remaining and %d processed bytes, expected 1 and 4", remaining, processed)
+	}
+}
+
+func TestPackerRemainingProcessedInvalidOffset(t *testing.T) {
+	p := Packer{Bytes: make([]byte, 4), Offset: -2}
+	if remaining, processed := p.Remaining(), p.Processed(); remaining != 0 || processed != 0 {
+		t.Fatalf("Packer with a negative offset has %d remaining and %d processed bytes", remaining, processed)
+	}
+	if p.CheckSpace(0); p.Err != errNegativeOffset {
+		t.Fatalf("Packer.CheckSpace should have failed with %s but failed with %v", errNegativeOffset, p.Err)
+	}
+
+	p = Packer{Bytes: make([]byte, 4), Offset: 6}
+	if remaining, processed := p.Remaining(), p.Processed(); remaining != 0 || processed != 6 {
+		t.Fatalf("Packer with an offset past the end has %d remaining and %d processed bytes", remaining, processed)
+	}
+	if p.CheckSpace(0); p.Err != errBadLength {
+		t.Fatalf("Packer.CheckSpace should have failed with %s but failed with %v", errBadLength, p.Err)
+	}
+}
+
+func TestPackerReset(t *testing.T) {
+	p := Packer{MaxSize: 4}
+	p.EnableRunningHash()
+	p.PackInt(1)
+	p.PackInt(2)
+	if !p.Errored() {
+		t.Fatal("Packer.PackInt should have failed beyond p.MaxSize")
+	}
+	capacity := cap(p.Bytes)
+
+	p.Reset()
+	if p.Errored() {
+		t.Fatalf("Packer.Reset should have cleared the error %s", p.Err)
+	}
+	if p.Offset != 0 || len(p.Bytes) != 0 || cap(p.Bytes) != capacity {
+		t.Fatalf("Packer.Reset left offset %d, len %d and cap %d", p.Offset, len(p.Bytes), cap(p.Bytes))
+	}
+	if p.MaxSize != 4 {
+		t.Fatalf("Packer.Reset changed MaxSize to %d", p.MaxSize)
+	}
+
+	p.PackInt(3)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if !bytes.Equal(p.Bytes, []byte{0, 0, 0, 3}) {
+		t.Fatalf("Packer wrote %v after Reset", p.Bytes)
+	}
+	if !bytes.Equal(p.RunningHash(), hashing.ComputeHash256(p.Bytes)) {
+		t.Fatal("the running hash should have restarted")
+	}
+}
+
+func TestPackerResetAllocs(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Reset()
+		p.PackLong(1)
+		p.PackStr("reused")
+		p.PackBool(true)
+	})
+	if allocs != 0 {
+		t.Fatalf("repacking after Packer.Reset allocated %v times", allocs)
+	}
+}
+
+func BenchmarkPackerReset(b *testing.B) {
+	p := Packer{MaxSize: 1024}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.Reset()
+		p.PackLong(uint64(i))
+		p.PackStr("reused")
+		p.PackBool(true)
+	}
+}
+
 func TestPackerPackByte(t *testing.T) {
 	p := Packer{MaxSize: 1}
 
@@ -242,6 +344,213 @@ func TestPackerUnpackLong(t *testing.T) {
 	}
 }
 
+func TestPackerFloat32(t *testing.T) {
+	values := []float32{
+		0,
+		float32(math.Copysign(0, -1)),
+		1.5,
+		-math.MaxFloat32,
+		math.SmallestNonzeroFloat32,
+		float32(math.Inf(1)),
+		float32(math.Inf(-1)),
+		math.Float32frombits(0x7fc00001), // NaN with a payload
+	}
+	for _, val := range values {
+		p := Packer{MaxSize: IntLen}
+		p.PackFloat32(val)
+		if p.Errored() {
+			t.Fatal(p.Err)
+		}
+		expected := []byte{0, 0, 0, 0}
+		binary.BigEndian.PutUint32(expected, math.Float32bits(val))
+		if !bytes.Equal(p.Bytes, expected) {
+			t.Fatalf("Packer.PackFloat32(%v) wrote:\n%v\nExpected:\n%v", val, p.Bytes, expected)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		if unpacked := p2.UnpackFloat32(); p2.Errored() {
+			t.Fatal(p2.Err)
+		} else if math.Float32bits(unpacked) != math.Float32bits(val) {
+			t.Fatalf("Packer.UnpackFloat32 returned %v, expected %v", unpacked, val)
+		}
+	}
+
+	p := Packer{Bytes: []byte{0, 0, 0}}
+	if p.UnpackFloat32(); !p.Errored() {
+		t.Fatal("Packer.UnpackFloat32 should have failed on a truncated float")
+	}
+}
+
+func TestPackerFloat64(t *testing.T) {
+	values := []float64{
+		0,
+		math.Copysign(0, -1),
+		1.5,
+		-math.MaxFloat64,
+		math.SmallestNonzeroFloat64,
+		math.Inf(1),
+		math.Inf(-1),
+		math.Float64frombits(0x7ff8000000000001), // NaN with a payload
+	}
+	for _, val := range values {
+		p := Packer{MaxSize: LongLen}
+		p.PackFloat64(val)
+		if p.Errored() {
+			t.Fatal(p.Err)
+		}
+		expected := make([]byte, LongLen)
+		binary.BigEndian.PutUint64(expected, math.Float64bits(val))
+		if !bytes.Equal(p.Bytes, expected) {
+			t.Fatalf("Packer.PackFloat64(%v) wrote:\n%v\nExpected:\n%v", val, p.Bytes, expected)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		if unpacked := p2.UnpackFloat64(); p2.Errored() {
+			t.Fatal(p2.Err)
+		} else if math.Float64bits(unpacked) != math.Float64bits(val) {
+			t.Fatalf("Packer.UnpackFloat64 returned %v, expected %v", unpacked, val)
+		}
+	}
+}
+
+func TestPackerSignedInt(t *testing.T) {
+	vals := []int32{math.MinInt32, -1, 0, math.MaxInt32}
+	p := Packer{MaxSize: len(vals) * IntLen}
+	for _, val := range vals {
+		p.PackSignedInt(val)
+	}
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// The wire format is the same as the unsigned int
+	p2 := Packer{Bytes: p.Bytes}
+	for _, val := range vals {
+		if unpacked := p2.UnpackInt(); unpacked != uint32(val) {
+			t.Fatalf("Packer.PackSignedInt wrote %d, expected %d", unpacked, uint32(val))
+		}
+	}
+
+	p3 := Packer{Bytes: p.Bytes}
+	for _, val := range vals {
+		if unpacked := p3.UnpackSignedInt(); unpacked != val {
+			t.Fatalf("Packer.UnpackSignedInt returned %d, expected %d", unpacked, val)
+		}
+	}
+	if p3.Errored() {
+		t.Fatal(p3.Err)
+	}
+	if p3.UnpackSignedInt(); !p3.Errored() {
+		t.Fatal("Packer.UnpackSignedInt should have failed on an empty byte array")
+	}
+}
+
+func TestPackerSignedLong(t *testing.T) {
+	vals := []int64{math.MinInt64, math.MinInt32, -1, 0, math.MaxInt64}
+	p := Packer{MaxSize: len(vals) * LongLen}
+	for _, val := range vals {
+		p.PackSignedLong(val)
+	}
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// The wire format is the same as the unsigned long
+	p2 := Packer{Bytes: p.Bytes}
+	for _, val := range vals {
+		if unpacked := p2.UnpackLong(); unpacked != uint64(val) {
+			t.Fatalf("Packer.PackSignedLong wrote %d, expected %d", unpacked, uint64(val))
+		}
+	}
+
+	p3 := Packer{Bytes: p.Bytes}
+	for _, val := range vals {
+		if unpacked := p3.UnpackSignedLong(); unpacked != val {
+			t.Fatalf("Packer.UnpackSignedLong returned %d, expected %d", unpacked, val)
+		}
+	}
+	if p3.Errored() {
+		t.Fatal(p3.Err)
+	}
+	if p3.UnpackSignedLong(); !p3.Errored() {
+		t.Fatal("Packer.UnpackSignedLong should have failed on an empty byte array")
+	}
+}
+
+func TestPackerUUID(t *testing.T) {
+	uuids := [][UUIDLen]byte{
+		{},
+		{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+	}
+	p := Packer{MaxSize: len(uuids) * UUIDLen}
+	for _, uuid := range uuids {
+		p.PackUUID(uuid)
+	}
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if !bytes.Equal(p.Bytes, append(uuids[0][:], uuids[1][:]...)) {
+		t.Fatalf("Packer.PackUUID wrote %v", p.Bytes)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	for _, uuid := range uuids {
+		if unpacked := p2.UnpackUUID(); p2.Errored() {
+			t.Fatal(p2.Err)
+		} else if unpacked != uuid {
+			t.Fatalf("Packer.UnpackUUID returned %x, expected %x", unpacked, uuid)
+		}
+	}
+
+	// Not enough bytes left for a UUID
+	p3 := Packer{Bytes: p.Bytes[:UUIDLen-1]}
+	if unpacked := p3.UnpackUUID(); !p3.Errored() || unpacked != [UUIDLen]byte{} {
+		t.Fatal("Packer.UnpackUUID should have failed on a truncated UUID")
+	}
+}
+
+func TestTryPackUUID(t *testing.T) {
+	uuid := [UUIDLen]byte{1, 2, 3}
+	p := Packer{MaxSize: UUIDLen}
+	TryPackUUID(&p, uuid)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	if val := TryUnpackUUID(&p2); val != uuid {
+		t.Fatalf("TryUnpackUUID returned %v, expected %v", val, uuid)
+	}
+
+	p3 := Packer{MaxSize: UUIDLen}
+	if TryPackUUID(&p3, uuid[:]); p3.Err != errBadType {
+		t.Fatalf("TryPackUUID should have failed with %s but failed with %v", errBadType, p3.Err)
+	}
+}
+
+func TestTryPackFloat(t *testing.T) {
+	p := Packer{MaxSize: IntLen + LongLen}
+	TryPackFloat32(&p, float32(1.5))
+	TryPackFloat64(&p, 2.5)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	if val := TryUnpackFloat32(&p2); val != float32(1.5) {
+		t.Fatalf("TryUnpackFloat32 returned %v, expected %v", val, float32(1.5))
+	}
+	if val := TryUnpackFloat64(&p2); val != 2.5 {
+		t.Fatalf("TryUnpackFloat64 returned %v, expected %v", val, 2.5)
+	}
+
+	p3 := Packer{MaxSize: LongLen}
+	TryPackFloat32(&p3, 1.5) // An untyped constant is a float64
+	if p3.Err != errBadType {
+		t.Fatalf("TryPackFloat32 should have failed with %s but got %v", errBadType, p3.Err)
+	}
+}
+
 func TestPackerPackFixedBytes(t *testing.T) {
 	p := Packer{MaxSize: 3}
 
@@ -402,6 +711,30 @@ func TestPackerString(t *testing.T) {
 	}
 }
 
+func TestPackerUnpackLimitedStr(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackStr("hello")
+	p.PackStr("hello")
+
+	p2 := Packer{Bytes: p.Bytes}
+	if str := p2.UnpackLimitedStr(5); p2.Errored() || str != "hello" {
+		t.Fatalf("Packer.UnpackLimitedStr returned (%q, %v), expected %q", str, p2.Err, "hello")
+	}
+	if str := p2.UnpackLimitedStr(4); p2.Err != errInvalidInput || str != "" {
+		t.Fatalf("Packer.UnpackLimitedStr should have failed with %s but returned (%q, %v)", errInvalidInput, str, p2.Err)
+	}
+
+	// A length prefix claiming far more than the 10 byte buffer holds
+	crafted := make([]byte, 10)
+	binary.BigEndian.PutUint16(crafted, 65000)
+	for _, maxSize := range []int{100, MaxStringLen} {
+		p3 := Packer{Bytes: crafted}
+		if str := p3.UnpackLimitedStr(maxSize); !p3.Errored() || str != "" {
+			t.Fatalf("Packer.UnpackLimitedStr(%d) should have failed but returned %q", maxSize, str)
+		}
+	}
+}
+
 func TestPacker(t *testing.T) {
 	packer := Packer{
 		MaxSize: 3,
@@ -506,3 +839,618 @@ func TestPackerUnpackBool(t *testing.T) {
 		t.Fatalf("Packer.UnpackBool returned %t, expected sentinal value %t", actual, BoolSentinal)
 	}
 }
+
+func TestPackerUnpackBoolLenient(t *testing.T) {
+	p := Packer{Bytes: []byte{0x00, 0x01, 0x02, 0xff}}
+	expected := []bool{false, true, true, true}
+	for i, expectedBool := range expected {
+		if actual := p.UnpackBoolLenient(); actual != expectedBool {
+			t.Fatalf("Packer.UnpackBoolLenient returned %t at index %d, expected %t", actual, i, expectedBool)
+		}
+	}
+	if p.Errored() {
+		t.Fatalf("Packer.UnpackBoolLenient unexpectedly raised %s", p.Err)
+	}
+
+	p2 := Packer{Bytes: []byte{0x02}}
+	if actual := p2.UnpackBool(); !p2.Errored() {
+		t.Fatalf("Packer.UnpackBool should have errored on 2")
+	} else if actual != BoolSentinal {
+		t.Fatalf("Packer.UnpackBool returned %t, expected sentinal value %t", actual, BoolSentinal)
+	}
+
+	p3 := Packer{}
+	if actual := p3.UnpackBoolLenient(); !p3.Errored() {
+		t.Fatalf("Packer.UnpackBoolLenient should have set error, due to attempted out of bounds read")
+	} else if actual != BoolSentinal {
+		t.Fatalf("Packer.UnpackBoolLenient returned %t, expected sentinal value %t", actual, BoolSentinal)
+	}
+}
+
+func TestPackerCappedSlice(t *testing.T) {
+	vals := []uint16{1, 2, 3}
+
+	p := Packer{MaxSize: 1024}
+	p.PackCappedSlice(len(vals), 3, func(i int) { p.PackShort(vals[i]) })
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	expected := []byte{0x00, 0x00, 0x00, 0x03, 0x00, 0x01, 0x00, 0x02, 0x00, 0x03}
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackCappedSlice wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+
+	unpacked := []uint16(nil)
+	p2 := Packer{Bytes: p.Bytes}
+	n := p2.UnpackCappedSlice(3, func(int) { unpacked = append(unpacked, p2.UnpackShort()) })
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	} else if n != len(vals) {
+		t.Fatalf("Packer.UnpackCappedSlice returned %d, expected %d", n, len(vals))
+	} else if !reflect.DeepEqual(unpacked, vals) {
+		t.Fatalf("Packer.UnpackCappedSlice unpacked %v, expected %v", unpacked, vals)
+	}
+}
+
+func TestPackerPackCappedSliceOverCap(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackCappedSlice(3, 2, func(i int) { t.Fatal("shouldn't have packed an element beyond the cap") })
+	if p.Err != errInvalidInput {
+		t.Fatalf("Packer.PackCappedSlice should have errored with %s, but got %v", errInvalidInput, p.Err)
+	} else if len(p.Bytes) != 0 {
+		t.Fatalf("Packer.PackCappedSlice wrote %d byte(s) but expected none", len(p.Bytes))
+	}
+}
+
+func TestPackerUnpackCappedSliceOverCap(t *testing.T) {
+	p := Packer{Bytes: []byte{0x00, 0x00, 0x00, 0x03, 0x00, 0x01, 0x00, 0x02, 0x00, 0x03}}
+	n := p.UnpackCappedSlice(2, func(int) { t.Fatal("shouldn't have unpacked an element beyond the cap") })
+	if p.Err != errInvalidInput {
+		t.Fatalf("Packer.UnpackCappedSlice should have errored with %s, but got %v", errInvalidInput, p.Err)
+	} else if n != 0 {
+		t.Fatalf("Packer.UnpackCappedSlice returned %d, expected 0", n)
+	}
+}
+
+func TestPackerReserveWriteAt(t *testing.T) {
+	p := Packer{MaxSize: 4}
+	p.PackByte(0x01)
+	offset := p.Reserve(2)
+	p.PackByte(0x04)
+	p.WriteAt(offset, []byte{0x02, 0x03})
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	expected := []byte{0x01, 0x02, 0x03, 0x04}
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.WriteAt wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	} else if p.Offset != 4 {
+		t.Fatalf("Packer.WriteAt left Offset %d, expected %d", p.Offset, 4)
+	}
+
+	p.WriteAt(3, []byte{0x05, 0x06})
+	if !p.Errored() {
+		t.Fatal("Packer.WriteAt should have errored when writing past the packed bytes")
+	}
+}
+
+func TestPackerPeekAt(t *testing.T) {
+	p := Packer{Bytes: []byte{0x01, 0x02, 0x03}}
+	if peeked := p.PeekAt(1, 2); !bytes.Equal(peeked, []byte{0x02, 0x03}) {
+		t.Fatalf("Packer.PeekAt returned %v, expected %v", peeked, []byte{0x02, 0x03})
+	} else if p.Offset != 0 {
+		t.Fatalf("Packer.PeekAt modified Offset to %d", p.Offset)
+	}
+
+	if p.PeekAt(2, 2); !p.Errored() {
+		t.Fatal("Packer.PeekAt should have errored when reading past the end of the bytes")
+	}
+}
+
+func TestPackerOffsetRef(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	ref := p.PackOffsetRef()
+	p.PackStr("unrelated")
+	p.ResolveOffsetRef(ref)
+	p.PackFixedBytes([]byte("referenced"))
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	offset := p2.UnpackOffsetRef()
+	if str := p2.UnpackStr(); str != "unrelated" {
+		t.Fatalf("Packer.UnpackStr returned %s, expected %s", str, "unrelated")
+	}
+	if referenced := p2.PeekAt(offset, len("referenced")); !bytes.Equal(referenced, []byte("referenced")) {
+		t.Fatalf("resolved reference to %s, expected %s", referenced, "referenced")
+	}
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+
+	p3 := Packer{Bytes: []byte{0x00, 0x00, 0x00, 0xff}}
+	if p3.UnpackOffsetRef(); !p3.Errored() {
+		t.Fatal("Packer.UnpackOffsetRef should have errored on an out of bounds reference")
+	}
+}
+
+func TestPackerVarInt(t *testing.T) {
+	p := Packer{MaxSize: 3}
+	p.PackVarInt(300)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	expected := []byte{0xac, 0x02}
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackVarInt wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	if val := p2.UnpackVarInt(); p2.Errored() {
+		t.Fatal(p2.Err)
+	} else if val != 300 {
+		t.Fatalf("Packer.UnpackVarInt returned %d, expected %d", val, 300)
+	}
+
+	p.PackVarInt(300)
+	if !p.Errored() {
+		t.Fatal("Packer.PackVarInt did not fail when attempt was beyond p.MaxSize")
+	}
+}
+
+func TestPackerVarIntBoundaries(t *testing.T) {
+	tests := []struct {
+		val uint64
+		len int
+	}{
+		{val: 0, len: 1},
+		{val: 127, len: 1},
+		{val: 128, len: 2},
+		{val: math.MaxUint32, len: 5},
+		{val: math.MaxUint64, len: MaxVarIntLen},
+	}
+	for _, test := range tests {
+		p := Packer{MaxSize: MaxVarIntLen}
+		p.PackVarInt(test.val)
+		if p.Errored() {
+			t.Fatal(p.Err)
+		}
+		if len(p.Bytes) != test.len {
+			t.Fatalf("Packer.PackVarInt(%d) wrote %d bytes, expected %d", test.val, len(p.Bytes), test.len)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		if val := p2.UnpackVarInt(); p2.Errored() {
+			t.Fatal(p2.Err)
+		} else if val != test.val {
+			t.Fatalf("Packer.UnpackVarInt returned %d, expected %d", val, test.val)
+		}
+	}
+}
+
+func TestPackerUnpackVarIntInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+		err   error
+	}{
+		{name: "empty", bytes: nil, err: errBadLength},
+		{name: "truncated", bytes: []byte{0x80, 0x80}, err: errBadLength},
+		{name: "too many continuation bytes", bytes: bytes.Repeat([]byte{0x80}, MaxVarIntLen+1), err: errInvalidInput},
+		{name: "overflows 64 bits", bytes: append(bytes.Repeat([]byte{0xff}, MaxVarIntLen-1), 0x02), err: errInvalidInput},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		if val := p.UnpackVarInt(); p.Err != test.err {
+			t.Fatalf("%s: Packer.UnpackVarInt should have failed with %s but returned (%d, %v)", test.name, test.err, val, p.Err)
+		}
+	}
+}
+
+func TestPackerTimeResolution(t *testing.T) {
+	tm := time.Unix(1577836800, 123456789)
+
+	p := Packer{MaxSize: 1024}
+	p.PackTimeResolution(tm, time.Millisecond)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpacked := p2.UnpackTimeResolution(time.Millisecond)
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+
+	expected := time.Unix(1577836800, 123000000)
+	if !unpacked.Equal(expected) {
+		t.Fatalf("Packer.UnpackTimeResolution returned %s, expected %s", unpacked, expected)
+	} else if !unpacked.Equal(tm.Truncate(time.Millisecond)) {
+		t.Fatalf("Packer.UnpackTimeResolution returned %s, expected the time truncated to a millisecond", unpacked)
+	}
+
+	// A coarser resolution should take less space
+	p3 := Packer{MaxSize: 1024}
+	p3.PackTimeResolution(tm, time.Second)
+	if len(p3.Bytes) >= len(p.Bytes) {
+		t.Fatalf("second resolution took %d bytes, expected less than millisecond resolution's %d bytes", len(p3.Bytes), len(p.Bytes))
+	}
+
+	p4 := Packer{MaxSize: 1024}
+	p4.PackTimeResolution(time.Unix(-1, 0), time.Second)
+	if !p4.Errored() {
+		t.Fatal("Packer.PackTimeResolution should have errored on a time before the epoch")
+	}
+}
+
+func TestPackerUnpackVarIntCanonical(t *testing.T) {
+	tests := []struct {
+		bytes     []byte
+		val       uint64
+		canonical bool
+	}{
+		{bytes: []byte{0x00}, val: 0, canonical: true},
+		{bytes: []byte{0x80, 0x00}, val: 0, canonical: false},
+		{bytes: []byte{0x01}, val: 1, canonical: true},
+		{bytes: []byte{0x81, 0x80, 0x00}, val: 1, canonical: false},
+		{bytes: []byte{0xac, 0x02}, val: 300, canonical: true},
+		{bytes: []byte{0xac, 0x82, 0x00}, val: 300, canonical: false},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		val := p.UnpackVarInt()
+		switch {
+		case test.canonical && p.Errored():
+			t.Fatalf("Packer.UnpackVarInt(%v) failed with: %s", test.bytes, p.Err)
+		case test.canonical && val != test.val:
+			t.Fatalf("Packer.UnpackVarInt(%v) returned %d, expected %d", test.bytes, val, test.val)
+		case !test.canonical && p.Err != errInvalidInput:
+			t.Fatalf("Packer.UnpackVarInt(%v) should have failed with errInvalidInput but returned %d", test.bytes, val)
+		}
+
+		lenient := Packer{Bytes: test.bytes, AllowNonCanonicalVarInts: true}
+		if val := lenient.UnpackVarInt(); lenient.Errored() {
+			t.Fatalf("lenient Packer.UnpackVarInt(%v) failed with: %s", test.bytes, lenient.Err)
+		} else if val != test.val {
+			t.Fatalf("lenient Packer.UnpackVarInt(%v) returned %d, expected %d", test.bytes, val, test.val)
+		} else if lenient.Offset != len(test.bytes) {
+			t.Fatalf("lenient Packer.UnpackVarInt(%v) read %d bytes, expected %d", test.bytes, lenient.Offset, len(test.bytes))
+		}
+	}
+}
+
+func TestPackerAllocBudget(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	for i := 0; i < 10; i++ {
+		p.PackBytes([]byte("0123456789"))
+		p.PackStr("0123456789")
+	}
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// Each field fits in the buffer, but together they exceed the budget
+	p2 := Packer{Bytes: p.Bytes, AllocBudget: 150}
+	for i := 0; i < 10 && !p2.Errored(); i++ {
+		p2.UnpackBytes()
+		p2.UnpackStr()
+	}
+	if p2.Err != errAllocBudget {
+		t.Fatalf("Packer should have failed with %s but failed with %v", errAllocBudget, p2.Err)
+	}
+
+	// The fields exactly fit in the budget
+	p3 := Packer{Bytes: p.Bytes, AllocBudget: 200}
+	for i := 0; i < 10; i++ {
+		if bytes := p3.UnpackBytes(); string(bytes) != "0123456789" {
+			t.Fatalf("Packer.UnpackBytes returned %q", bytes)
+		}
+		if str := p3.UnpackStr(); str != "0123456789" {
+			t.Fatalf("Packer.UnpackStr returned %q", str)
+		}
+	}
+	if p3.Errored() {
+		t.Fatal(p3.Err)
+	}
+}
+
+func TestPackerUnpackFixedByteSlicesAllocBudget(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackFixedByteSlices([][]byte{{1, 2}, {3, 4}, {5, 6}})
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes, AllocBudget: 5}
+	if p2.UnpackFixedByteSlices(2); p2.Err != errAllocBudget {
+		t.Fatalf("Packer should have failed with %s but failed with %v", errAllocBudget, p2.Err)
+	}
+}
+
+func TestPackerCompactIP(t *testing.T) {
+	tests := []struct {
+		ip     utils.IPDesc
+		length int
+	}{
+		{ip: utils.IPDesc{IP: net.IPv4(192, 168, 1, 2), Port: 9651}, length: ByteLen + net.IPv4len + ShortLen},
+		{ip: utils.IPDesc{IP: net.IP{10, 0, 0, 1}, Port: 0}, length: ByteLen + net.IPv4len + ShortLen},
+		{ip: utils.IPDesc{IP: net.ParseIP("2001:db8::68"), Port: 65535}, length: ByteLen + net.IPv6len + ShortLen},
+		{ip: utils.IPDesc{IP: net.IPv6loopback, Port: 9651}, length: ByteLen + net.IPv6len + ShortLen},
+	}
+	for _, test := range tests {
+		p := Packer{MaxSize: test.length}
+		p.PackCompactIP(test.ip)
+		if p.Errored() {
+			t.Fatalf("%s: %s", test.ip, p.Err)
+		}
+		if len(p.Bytes) != test.length {
+			t.Fatalf("%s: Packer.PackCompactIP wrote %d bytes, expected %d", test.ip, len(p.Bytes), test.length)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		unpacked := p2.UnpackCompactIP()
+		if p2.Errored() {
+			t.Fatalf("%s: %s", test.ip, p2.Err)
+		}
+		if !unpacked.Equal(test.ip) {
+			t.Fatalf("Packer.UnpackCompactIP returned %s, expected %s", unpacked, test.ip)
+		}
+		if p2.Offset != len(p2.Bytes) {
+			t.Fatalf("%s: Packer.UnpackCompactIP left %d unread bytes", test.ip, len(p2.Bytes)-p2.Offset)
+		}
+	}
+
+	// The 16 byte format is unchanged
+	p := Packer{MaxSize: 18}
+	p.PackIP(tests[0].ip)
+	if len(p.Bytes) != net.IPv6len+ShortLen {
+		t.Fatalf("Packer.PackIP wrote %d bytes, expected %d", len(p.Bytes), net.IPv6len+ShortLen)
+	}
+}
+
+func TestPackerCompactIPInvalid(t *testing.T) {
+	p := Packer{MaxSize: 32}
+	p.PackCompactIP(utils.IPDesc{IP: net.IP{1, 2, 3}, Port: 9651})
+	if p.Err != errInvalidInput {
+		t.Fatalf("Packer.PackCompactIP should have failed with %s but returned %v", errInvalidInput, p.Err)
+	}
+
+	p2 := Packer{Bytes: []byte{5, 1, 2, 3, 4, 0x25, 0xb3}}
+	if ip := p2.UnpackCompactIP(); p2.Err != errInvalidInput || ip.IP != nil {
+		t.Fatalf("Packer.UnpackCompactIP should have failed on an unknown family but returned %s, %v", ip, p2.Err)
+	}
+
+	p3 := Packer{Bytes: []byte{6, 1, 2, 3, 4, 0x25, 0xb3}}
+	if p3.UnpackCompactIP(); !p3.Errored() {
+		t.Fatal("Packer.UnpackCompactIP should have failed on a truncated IPv6 address")
+	}
+}
+
+func TestPackerCIDR(t *testing.T) {
+	for _, cidr := range []string{"192.168.1.0/24", "2001:db8:abcd:12::/64", "0.0.0.0/0", "::/0", "10.1.2.3/32"} {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p := Packer{MaxSize: net.IPv6len + 1}
+		p.PackCIDR(ipNet)
+		if p.Errored() {
+			t.Fatalf("%s: %s", cidr, p.Err)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		unpacked, err := p2.UnpackCIDR()
+		if err != nil {
+			t.Fatalf("%s: %s", cidr, err)
+		}
+		if unpacked.String() != cidr {
+			t.Fatalf("Packer.UnpackCIDR returned %s, expected %s", unpacked, cidr)
+		}
+		if p2.Offset != len(p2.Bytes) {
+			t.Fatalf("%s: Packer.UnpackCIDR left %d unread bytes", cidr, len(p2.Bytes)-p2.Offset)
+		}
+	}
+}
+
+func TestPackerPackCIDRNormalizes(t *testing.T) {
+	p := Packer{MaxSize: net.IPv6len + 1}
+	p.PackCIDR(&net.IPNet{IP: net.ParseIP("192.168.1.77"), Mask: net.CIDRMask(24, 32)})
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	expected := append([]byte(net.ParseIP("192.168.1.0")), 120)
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackCIDR wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+
+	p = Packer{MaxSize: net.IPv6len + 1}
+	p.PackCIDR(&net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.IPMask{0xff, 0x00, 0xff, 0x00}})
+	if !p.Errored() {
+		t.Fatal("Packer.PackCIDR should have errored on a non-canonical mask")
+	}
+
+	p = Packer{MaxSize: net.IPv6len + 1}
+	p.PackCIDR(&net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(64, 128)})
+	if !p.Errored() {
+		t.Fatal("Packer.PackCIDR should have errored on an IPv4 address with a mask that doesn't cover the IPv4-mapped prefix")
+	}
+	if len(p.Bytes) != 0 {
+		t.Fatalf("Packer.PackCIDR shouldn't have written anything but wrote %v", p.Bytes)
+	}
+
+	// An IPv4 address with an IPv6 mask that covers the IPv4-mapped prefix is
+	// packed as the IPv4-mapped network
+	p = Packer{MaxSize: net.IPv6len + 1}
+	p.PackCIDR(&net.IPNet{IP: net.IPv4(10, 1, 2, 3).To4(), Mask: net.CIDRMask(112, 128)})
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	expected = append([]byte(net.ParseIP("10.1.0.0")), 112)
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackCIDR wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+}
+
+func TestPackerUnpackCIDRInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+	}{
+		{
+			name:  "prefix too long",
+			bytes: append(make([]byte, net.IPv6len), 129),
+		},
+		{
+			name:  "host bits set",
+			bytes: append([]byte(net.ParseIP("192.168.1.1")), 120),
+		},
+		{
+			name:  "truncated",
+			bytes: make([]byte, net.IPv6len),
+		},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		if ipNet, err := p.UnpackCIDR(); err == nil || ipNet != nil {
+			t.Fatalf("%s: Packer.UnpackCIDR should have errored", test.name)
+		}
+	}
+}
+
+func TestPackerRunningHash(t *testing.T) {
+	p := Packer{MaxSize: 1 << 16}
+	p.EnableRunningHash()
+
+	p.PackByte(1)
+	p.PackStr("hello")
+	ref := p.PackOffsetRef()
+	p.ResolveOffsetRef(ref)
+	p.PackBytes(bytes.Repeat([]byte{0xab}, 1000))
+	if hash := p.RunningHash(); !bytes.Equal(hash, hashing.ComputeHash256(p.Bytes)) {
+		t.Fatalf("Packer.RunningHash returned %x, expected %x", hash, hashing.ComputeHash256(p.Bytes))
+	}
+
+	// Packing continues to be hashed after RunningHash is called
+	p.PackLong(42)
+	p.PackVarInt(300)
+	if hash := p.RunningHash(); !bytes.Equal(hash, hashing.ComputeHash256(p.Bytes)) {
+		t.Fatalf("Packer.RunningHash returned %x, expected %x", hash, hashing.ComputeHash256(p.Bytes))
+	}
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// Bytes that have been hashed can't be overwritten
+	p.WriteAt(0, []byte{2})
+	if !p.Errored() {
+		t.Fatal("Packer.WriteAt should have errored when overwriting hashed bytes")
+	}
+}
+
+func TestPackerRunningHashDisabled(t *testing.T) {
+	p := Packer{MaxSize: 1}
+	p.PackByte(1)
+	if hash := p.RunningHash(); !p.Errored() || hash != nil {
+		t.Fatal("Packer.RunningHash should have errored when it wasn't enabled")
+	}
+}
+
+func TestPackerOptionalHash(t *testing.T) {
+	hash := [hashing.HashLen]byte{1, 2, 3}
+	zeroHash := [hashing.HashLen]byte{}
+	tests := []struct {
+		name     string
+		hash     *[hashing.HashLen]byte
+		expected []byte
+	}{
+		{name: "absent", hash: nil, expected: []byte{0x00}},
+		{name: "present", hash: &hash, expected: append([]byte{0x01}, hash[:]...)},
+		{name: "present zero", hash: &zeroHash, expected: append([]byte{0x01}, zeroHash[:]...)},
+	}
+	for _, test := range tests {
+		p := Packer{MaxSize: 1 + hashing.HashLen}
+		p.PackOptionalHash(test.hash)
+		if p.Errored() {
+			t.Fatalf("%s: %s", test.name, p.Err)
+		}
+		if !bytes.Equal(p.Bytes, test.expected) {
+			t.Fatalf("%s: Packer.PackOptionalHash wrote:\n%v\nExpected:\n%v", test.name, p.Bytes, test.expected)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		unpacked := p2.UnpackOptionalHash()
+		if p2.Errored() {
+			t.Fatalf("%s: %s", test.name, p2.Err)
+		}
+		switch {
+		case test.hash == nil && unpacked != nil:
+			t.Fatalf("%s: Packer.UnpackOptionalHash returned %x, expected nil", test.name, *unpacked)
+		case test.hash != nil && (unpacked == nil || *unpacked != *test.hash):
+			t.Fatalf("%s: Packer.UnpackOptionalHash returned %v, expected %x", test.name, unpacked, *test.hash)
+		}
+	}
+}
+
+func TestPackerUnpackOptionalHashInvalid(t *testing.T) {
+	for _, b := range [][]byte{{}, {0x02}, {0x01, 0x00}} {
+		p := Packer{Bytes: b}
+		if hash := p.UnpackOptionalHash(); !p.Errored() || hash != nil {
+			t.Fatalf("Packer.UnpackOptionalHash should have errored on %v", b)
+		}
+	}
+}
+
+func TestPackerRange(t *testing.T) {
+	tests := []struct {
+		start, end uint64
+		expected   []byte
+	}{
+		{start: 5, end: 5, expected: []byte{0x05, 0x00}},
+		{start: 100, end: 300, expected: []byte{0x64, 0xc8, 0x01}},
+		{start: 0, end: math.MaxUint64, expected: []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+	for _, test := range tests {
+		p := Packer{MaxSize: 32}
+		p.PackRange(test.start, test.end)
+		if p.Errored() {
+			t.Fatal(p.Err)
+		}
+		if !bytes.Equal(p.Bytes, test.expected) {
+			t.Fatalf("Packer.PackRange wrote:\n%v\nExpected:\n%v", p.Bytes, test.expected)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		start, end := p2.UnpackRange()
+		if p2.Errored() {
+			t.Fatal(p2.Err)
+		}
+		if start != test.start || end != test.end {
+			t.Fatalf("Packer.UnpackRange returned [%d, %d], expected [%d, %d]", start, end, test.start, test.end)
+		}
+	}
+}
+
+func TestPackerRangeInverted(t *testing.T) {
+	p := Packer{MaxSize: 32}
+	p.PackRange(10, 9)
+	if p.Err != errInvertedRange {
+		t.Fatalf("Packer.PackRange should have failed with %s but got %v", errInvertedRange, p.Err)
+	}
+	if len(p.Bytes) != 0 {
+		t.Fatalf("Packer.PackRange shouldn't have packed an inverted range")
+	}
+
+	// A range that ends past the maximum value can't be unpacked
+	p2 := Packer{MaxSize: 32}
+	p2.PackVarInt(1)
+	p2.PackVarInt(math.MaxUint64)
+	p3 := Packer{Bytes: p2.Bytes}
+	if start, end := p3.UnpackRange(); !p3.Errored() {
+		t.Fatalf("Packer.UnpackRange should have errored but returned [%d, %d]", start, end)
+	}
+}
@@ -0,0 +1,67 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"sort"
+)
+
+// minPriorityItemLen is the minimum number of bytes of a packed item: its
+// priority and empty data
+const minPriorityItemLen = IntLen + IntLen
+
+var errPriorityOrder = errors.New("items must be in priority order")
+
+// PriorityItem is an item of a priority queue
+type PriorityItem struct {
+	Priority uint32
+	Data     []byte
+}
+
+// PackPriorityItems appends [items] to the byte array in priority order,
+// highest priority first. Items with the same priority keep their order in
+// [items], so a queue that is first in, first out within a priority stays
+// that way. [items] isn't modified.
+func (p *Packer) PackPriorityItems(items []PriorityItem) {
+	sorted := append([]PriorityItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	p.PackVarInt(uint64(len(sorted)))
+	for _, item := range sorted {
+		p.PackInt(item.Priority)
+		p.PackBytes(item.Data)
+	}
+}
+
+// UnpackPriorityItems unpacks the items packed by PackPriorityItems from the
+// byte array, highest priority first
+func (p *Packer) UnpackPriorityItems() []PriorityItem {
+	numItems := p.UnpackVarInt()
+	if p.Errored() {
+		return nil
+	}
+	if numItems > uint64(p.Remaining())/minPriorityItemLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	items := make([]PriorityItem, numItems)
+	for i := range items {
+		items[i] = PriorityItem{
+			Priority: p.UnpackInt(),
+			Data:     p.UnpackBytes(),
+		}
+		if p.Errored() {
+			return nil
+		}
+		if i > 0 && items[i].Priority > items[i-1].Priority {
+			p.Add(errPriorityOrder)
+			return nil
+		}
+	}
+	return items
+}
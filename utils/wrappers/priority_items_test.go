@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPackerPriorityItems(t *testing.T) {
+	items := []PriorityItem{
+		{Priority: 1, Data: []byte("low")},
+		{Priority: 10, Data: []byte("high")},
+		{Priority: 5, Data: []byte("first medium")},
+		{Priority: 5, Data: []byte("second medium")},
+		{Priority: 0, Data: nil},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackPriorityItems(items)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if string(items[0].Data) != "low" {
+		t.Fatal("Packer.PackPriorityItems shouldn't have modified the items")
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpacked := p2.UnpackPriorityItems()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+
+	expected := []PriorityItem{items[1], items[2], items[3], items[0], items[4]}
+	if len(unpacked) != len(expected) {
+		t.Fatalf("Packer.UnpackPriorityItems returned %d items, expected %d", len(unpacked), len(expected))
+	}
+	for i, item := range unpacked {
+		if item.Priority != expected[i].Priority || !bytes.Equal(item.Data, expected[i].Data) {
+			t.Fatalf("item %d was (%d, %q), expected (%d, %q)", i, item.Priority, item.Data, expected[i].Priority, expected[i].Data)
+		}
+	}
+}
+
+func TestPackerUnpackPriorityItemsOutOfOrder(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackVarInt(2)
+	p.PackInt(1)
+	p.PackBytes(nil)
+	p.PackInt(2)
+	p.PackBytes(nil)
+
+	p2 := Packer{Bytes: p.Bytes}
+	if items := p2.UnpackPriorityItems(); p2.Err != errPriorityOrder || items != nil {
+		t.Fatalf("Packer.UnpackPriorityItems should have failed with %s but returned (%v, %v)", errPriorityOrder, items, p2.Err)
+	}
+
+	p3 := Packer{Bytes: []byte{0x7f}}
+	if items := p3.UnpackPriorityItems(); !p3.Errored() || items != nil {
+		t.Fatal("Packer.UnpackPriorityItems should have failed on a count larger than the input")
+	}
+}
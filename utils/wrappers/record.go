@@ -0,0 +1,133 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	// MaxRecordLen is the maximum length of a record's payload
+	MaxRecordLen = 1 << 20
+
+	// recordHeaderLen is the length of the magic and payload length that
+	// precede a record's payload
+	recordHeaderLen = IntLen + IntLen
+	// recordOverhead is the number of bytes a record adds to its payload
+	recordOverhead = recordHeaderLen + IntLen
+)
+
+var (
+	// recordMagic marks the start of every record, so that a reader can find
+	// the next record after a corruption
+	recordMagic = []byte{0x8d, 0x7f, 0x52, 0x1a}
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	// ErrCorruptRecord is returned when a record fails validation
+	ErrCorruptRecord = errors.New("record is corrupt")
+)
+
+// PackRecord appends [payload] to the byte array as a record that can be read
+// by ReadRecord. A record is:
+// * The record magic (4 bytes)
+// * The length of [payload] (4 bytes)
+// * [payload]
+// * The CRC-32C of the length and [payload] (4 bytes)
+func (p *Packer) PackRecord(payload []byte) {
+	if len(payload) > MaxRecordLen {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackFixedBytes(recordMagic)
+	start := p.Offset
+	p.PackInt(uint32(len(payload)))
+	p.PackFixedBytes(payload)
+	if p.Errored() {
+		return
+	}
+	p.PackInt(crc32.Checksum(p.Bytes[start:p.Offset], crcTable))
+}
+
+// ReadRecord reads a record packed by PackRecord from [r] and returns its
+// payload. If the record is invalid, ErrCorruptRecord is returned and [r] is
+// left partway through the record; Resync can be used to find the next one.
+func ReadRecord(r io.Reader) ([]byte, error) {
+	header := [recordHeaderLen]byte{}
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:IntLen], recordMagic) {
+		return nil, ErrCorruptRecord
+	}
+	payloadLen := binary.BigEndian.Uint32(header[IntLen:])
+	if payloadLen > MaxRecordLen {
+		return nil, ErrCorruptRecord
+	}
+
+	rest := make([]byte, int(payloadLen)+IntLen)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+	payload := rest[:payloadLen]
+	checksum := crc32.Update(crc32.Checksum(header[IntLen:], crcTable), crcTable, payload)
+	if checksum != binary.BigEndian.Uint32(rest[payloadLen:]) {
+		return nil, ErrCorruptRecord
+	}
+	return payload, nil
+}
+
+// Resync discards bytes from [r] until it is positioned at the start of a
+// plausible record. A record is plausible if it starts with the record magic
+// and has a valid length. If the whole record fits in [r]'s buffer, its CRC
+// must also be valid. Returns io.EOF if no plausible record is found.
+func Resync(r *bufio.Reader) error {
+	for {
+		header, err := r.Peek(recordHeaderLen)
+		if err != nil {
+			if err == io.EOF && len(header) > 0 {
+				_, err = r.Discard(len(header))
+				if err == nil {
+					err = io.EOF
+				}
+			}
+			return err
+		}
+		if plausibleRecord(r, header) {
+			return nil
+		}
+		if _, err := r.Discard(1); err != nil {
+			return err
+		}
+	}
+}
+
+// plausibleRecord returns true if [header] is the start of a plausible record
+// in [r]
+func plausibleRecord(r *bufio.Reader, header []byte) bool {
+	if !bytes.Equal(header[:IntLen], recordMagic) {
+		return false
+	}
+	payloadLen := binary.BigEndian.Uint32(header[IntLen:])
+	if payloadLen > MaxRecordLen {
+		return false
+	}
+
+	recordLen := int(payloadLen) + recordOverhead
+	if recordLen > r.Size() {
+		// The record can't be validated without consuming it
+		return true
+	}
+	record, err := r.Peek(recordLen)
+	if err != nil {
+		return false
+	}
+	checksum := crc32.Checksum(record[IntLen:recordHeaderLen+payloadLen], crcTable)
+	return checksum == binary.BigEndian.Uint32(record[recordHeaderLen+payloadLen:])
+}
@@ -0,0 +1,94 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func packRecords(t *testing.T, payloads ...[]byte) []byte {
+	p := Packer{MaxSize: 1 << 16}
+	for _, payload := range payloads {
+		p.PackRecord(payload)
+	}
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	return p.Bytes
+}
+
+func TestRecord(t *testing.T) {
+	payloads := [][]byte{[]byte("first"), {}, []byte("third")}
+	r := bytes.NewReader(packRecords(t, payloads...))
+	for _, expected := range payloads {
+		if payload, err := ReadRecord(r); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(payload, expected) {
+			t.Fatalf("ReadRecord returned %q, expected %q", payload, expected)
+		}
+	}
+	if _, err := ReadRecord(r); err != io.EOF {
+		t.Fatalf("ReadRecord should have returned EOF but returned %v", err)
+	}
+}
+
+func TestPackRecordTooLarge(t *testing.T) {
+	p := Packer{MaxSize: 2 * MaxRecordLen}
+	p.PackRecord(make([]byte, MaxRecordLen+1))
+	if !p.Errored() {
+		t.Fatal("PackRecord should have failed when the payload was too large")
+	}
+}
+
+func TestRecordResyncAfterCorruptPayload(t *testing.T) {
+	first := packRecords(t, []byte("first"))
+	second := packRecords(t, []byte("second"))
+	third := packRecords(t, []byte("third"))
+	second[recordHeaderLen] ^= 0xff // Corrupt the second payload
+
+	r := bufio.NewReader(bytes.NewReader(append(append(first, second...), third...)))
+	if payload, err := ReadRecord(r); err != nil {
+		t.Fatal(err)
+	} else if string(payload) != "first" {
+		t.Fatalf("ReadRecord returned %q, expected %q", payload, "first")
+	}
+	if _, err := ReadRecord(r); err != ErrCorruptRecord {
+		t.Fatalf("ReadRecord should have returned %s but returned %v", ErrCorruptRecord, err)
+	}
+	if err := Resync(r); err != nil {
+		t.Fatal(err)
+	}
+	if payload, err := ReadRecord(r); err != nil {
+		t.Fatal(err)
+	} else if string(payload) != "third" {
+		t.Fatalf("ReadRecord returned %q, expected %q", payload, "third")
+	}
+}
+
+func TestRecordResyncAfterGarbage(t *testing.T) {
+	// The garbage includes the record magic followed by an invalid record
+	garbage := append([]byte{1, 2, 3, 4, 5, 6, 7, 8}, recordMagic...)
+	garbage = append(garbage, 0, 0, 0, 1, 'x', 0, 0, 0, 0)
+	records := packRecords(t, []byte("recovered"))
+
+	r := bufio.NewReader(bytes.NewReader(append(garbage, records...)))
+	if _, err := ReadRecord(r); err != ErrCorruptRecord {
+		t.Fatalf("ReadRecord should have returned %s but returned %v", ErrCorruptRecord, err)
+	}
+	if err := Resync(r); err != nil {
+		t.Fatal(err)
+	}
+	if payload, err := ReadRecord(r); err != nil {
+		t.Fatal(err)
+	} else if string(payload) != "recovered" {
+		t.Fatalf("ReadRecord returned %q, expected %q", payload, "recovered")
+	}
+
+	if err := Resync(r); err != io.EOF {
+		t.Fatalf("Resync should have returned EOF but returned %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"math"
+)
+
+// Sample is a value recorded [DeltaMillis] milliseconds after the previous
+// sample of its batch, or after the batch's base time for the first sample
+type Sample struct {
+	DeltaMillis uint32
+	Value       uint64
+}
+
+// TimedSample is a value recorded at [Time] milliseconds since the Unix epoch
+type TimedSample struct {
+	Time  int64
+	Value uint64
+}
+
+// PackSampleBatch appends [baseTime], in milliseconds since the Unix epoch,
+// followed by the delta encoded [samples] to the byte array. Since the deltas
+// and values are packed as varints, samples recorded close together with small
+// values take only a few bytes each.
+func (p *Packer) PackSampleBatch(baseTime int64, samples []Sample) {
+	p.PackLong(uint64(baseTime))
+	p.PackVarInt(uint64(len(samples)))
+	for _, sample := range samples {
+		p.PackVarInt(uint64(sample.DeltaMillis))
+		p.PackVarInt(sample.Value)
+	}
+}
+
+// UnpackSampleBatch unpacks a batch packed by PackSampleBatch from the byte
+// array. Returns the base time of the batch and its samples with their
+// absolute times reconstructed.
+func (p *Packer) UnpackSampleBatch() (int64, []TimedSample) {
+	baseTime := int64(p.UnpackLong())
+	numSamples := p.UnpackVarInt()
+	if p.Errored() {
+		return 0, nil
+	}
+	// Every sample takes at least 2 bytes
+	if numSamples > uint64(p.Remaining())/2 {
+		p.Add(errInvalidInput)
+		return 0, nil
+	}
+
+	samples := make([]TimedSample, numSamples)
+	sampleTime := baseTime
+	for i := range samples {
+		delta := p.UnpackVarInt()
+		value := p.UnpackVarInt()
+		if p.Errored() {
+			return 0, nil
+		}
+		if delta > math.MaxUint32 || sampleTime > math.MaxInt64-int64(delta) {
+			p.Add(errInvalidInput)
+			return 0, nil
+		}
+		sampleTime += int64(delta)
+		samples[i] = TimedSample{Time: sampleTime, Value: value}
+	}
+	return baseTime, samples
+}
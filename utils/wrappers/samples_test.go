@@ -0,0 +1,85 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPackerSampleBatch(t *testing.T) {
+	baseTime := int64(1588000000000)
+	samples := []Sample{
+		{DeltaMillis: 0, Value: 12},
+		{DeltaMillis: 250, Value: 0},
+		{DeltaMillis: 1000, Value: math.MaxUint64},
+		{DeltaMillis: math.MaxUint32, Value: 7},
+	}
+	expected := []TimedSample{
+		{Time: baseTime, Value: 12},
+		{Time: baseTime + 250, Value: 0},
+		{Time: baseTime + 1250, Value: math.MaxUint64},
+		{Time: baseTime + 1250 + math.MaxUint32, Value: 7},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackSampleBatch(baseTime, samples)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	// Packing each pair as longs would take 16 bytes per sample
+	if len(p.Bytes) >= LongLen+2*LongLen*len(samples) {
+		t.Fatalf("Packer.PackSampleBatch wrote %d bytes, which isn't compact", len(p.Bytes))
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpackedBaseTime, unpacked := p2.UnpackSampleBatch()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if unpackedBaseTime != baseTime {
+		t.Fatalf("Packer.UnpackSampleBatch returned base time %d, expected %d", unpackedBaseTime, baseTime)
+	}
+	if len(unpacked) != len(expected) {
+		t.Fatalf("Packer.UnpackSampleBatch returned %d samples, expected %d", len(unpacked), len(expected))
+	}
+	for i, sample := range unpacked {
+		if sample != expected[i] {
+			t.Fatalf("Packer.UnpackSampleBatch returned %+v at index %d, expected %+v", sample, i, expected[i])
+		}
+	}
+	if p2.Offset != len(p2.Bytes) {
+		t.Fatalf("Packer.UnpackSampleBatch left %d unread bytes", len(p2.Bytes)-p2.Offset)
+	}
+}
+
+func TestPackerUnpackSampleBatchInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+	}{
+		{
+			name:  "truncated base time",
+			bytes: []byte{0x00, 0x00},
+		},
+		{
+			name:  "too many samples",
+			bytes: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x05, 0x01, 0x01},
+		},
+		{
+			name:  "delta too large",
+			bytes: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x80, 0x80, 0x80, 0x80, 0x10, 0x01},
+		},
+		{
+			name:  "time overflow",
+			bytes: []byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01},
+		},
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test.bytes}
+		if _, samples := p.UnpackSampleBatch(); !p.Errored() || samples != nil {
+			t.Fatalf("%s: Packer.UnpackSampleBatch should have errored", test.name)
+		}
+	}
+}
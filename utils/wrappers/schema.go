@@ -0,0 +1,213 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"math"
+)
+
+// schemaDescriptorVersion is the version of the schema descriptor encoding
+const schemaDescriptorVersion byte = 0
+
+// Type tags of the fields of a schema
+const (
+	SchemaByte  byte = 0 // byte, packed with PackByte
+	SchemaShort byte = 1 // uint16, packed with PackShort
+	SchemaInt   byte = 2 // uint32, packed with PackInt
+	SchemaLong  byte = 3 // uint64, packed with PackLong
+	SchemaBool  byte = 4 // bool, packed with PackBool
+	SchemaStr   byte = 5 // string, packed with PackStr
+	SchemaBytes byte = 6 // []byte, packed with PackBytes
+)
+
+// minSchemaFieldLen is the minimum number of bytes of a packed schema field: an
+// empty name and a type tag
+const minSchemaFieldLen = ShortLen + 1
+
+var (
+	errUnknownSchemaVersion = errors.New("unknown schema descriptor version")
+	errUnknownTypeTag       = errors.New("unknown type tag")
+	errDuplicateSchemaField = errors.New("schema field names must be unique")
+	errSchemaMismatch       = errors.New("values don't match the schema")
+)
+
+// SchemaField is a named, typed field of a schema
+type SchemaField struct {
+	Name    string
+	TypeTag byte
+}
+
+// PackSchemaDescriptor appends a descriptor of [fields] to the byte array, so
+// that a message packed in their order can be read without knowing them in
+// advance. A descriptor is:
+// * The descriptor version (1 byte)
+// * The number of fields (2 bytes)
+// * The name and type tag of each field
+func (p *Packer) PackSchemaDescriptor(fields []SchemaField) {
+	if !validSchema(fields) {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackByte(schemaDescriptorVersion)
+	p.PackShort(uint16(len(fields)))
+	for _, field := range fields {
+		p.PackStr(field.Name)
+		p.PackByte(field.TypeTag)
+	}
+}
+
+// UnpackSchemaDescriptor unpacks a descriptor packed by PackSchemaDescriptor
+// from the byte array
+func (p *Packer) UnpackSchemaDescriptor() []SchemaField {
+	version := p.UnpackByte()
+	numFields := p.UnpackShort()
+	if p.Errored() {
+		return nil
+	}
+	if version != schemaDescriptorVersion {
+		p.Add(errUnknownSchemaVersion)
+		return nil
+	}
+	if int(numFields) > p.Remaining()/minSchemaFieldLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	fields := make([]SchemaField, numFields)
+	for i := range fields {
+		fields[i].Name = p.UnpackStr()
+		fields[i].TypeTag = p.UnpackByte()
+	}
+	if p.Errored() {
+		return nil
+	}
+	if !validSchema(fields) {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	return fields
+}
+
+// validSchema returns true if [fields] have unique names and known type tags
+func validSchema(fields []SchemaField) bool {
+	if len(fields) > math.MaxUint16 {
+		return false
+	}
+	names := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		if field.TypeTag > SchemaBytes {
+			return false
+		}
+		if _, exists := names[field.Name]; exists {
+			return false
+		}
+		names[field.Name] = struct{}{}
+	}
+	return true
+}
+
+// PackAny appends [value] to the byte array with the packer of [typeTag].
+// [value] must have the type of [typeTag].
+func (p *Packer) PackAny(typeTag byte, value interface{}) {
+	ok := false
+	switch typeTag {
+	case SchemaByte:
+		var val byte
+		if val, ok = value.(byte); ok {
+			p.PackByte(val)
+		}
+	case SchemaShort:
+		var val uint16
+		if val, ok = value.(uint16); ok {
+			p.PackShort(val)
+		}
+	case SchemaInt:
+		var val uint32
+		if val, ok = value.(uint32); ok {
+			p.PackInt(val)
+		}
+	case SchemaLong:
+		var val uint64
+		if val, ok = value.(uint64); ok {
+			p.PackLong(val)
+		}
+	case SchemaBool:
+		var val bool
+		if val, ok = value.(bool); ok {
+			p.PackBool(val)
+		}
+	case SchemaStr:
+		var val string
+		if val, ok = value.(string); ok {
+			p.PackStr(val)
+		}
+	case SchemaBytes:
+		var val []byte
+		if val, ok = value.([]byte); ok {
+			p.PackBytes(val)
+		}
+	default:
+		p.Add(errUnknownTypeTag)
+		return
+	}
+	if !ok {
+		p.Add(errSchemaMismatch)
+	}
+}
+
+// UnpackAny unpacks a value packed by PackAny with [typeTag] from the byte
+// array
+func (p *Packer) UnpackAny(typeTag byte) interface{} {
+	switch typeTag {
+	case SchemaByte:
+		return p.UnpackByte()
+	case SchemaShort:
+		return p.UnpackShort()
+	case SchemaInt:
+		return p.UnpackInt()
+	case SchemaLong:
+		return p.UnpackLong()
+	case SchemaBool:
+		return p.UnpackBool()
+	case SchemaStr:
+		return p.UnpackStr()
+	case SchemaBytes:
+		return p.UnpackBytes()
+	default:
+		p.Add(errUnknownTypeTag)
+		return nil
+	}
+}
+
+// PackSchemaMessage appends a self-describing message to the byte array: the
+// descriptor of [fields] followed by [values], the value of each field in
+// order
+func (p *Packer) PackSchemaMessage(fields []SchemaField, values []interface{}) {
+	if len(fields) != len(values) {
+		p.Add(errSchemaMismatch)
+		return
+	}
+	p.PackSchemaDescriptor(fields)
+	for i, field := range fields {
+		p.PackAny(field.TypeTag, values[i])
+	}
+}
+
+// UnpackSchemaMessage unpacks a message packed by PackSchemaMessage from the
+// byte array. Returns the message's fields and the value of each field.
+func (p *Packer) UnpackSchemaMessage() ([]SchemaField, []interface{}) {
+	fields := p.UnpackSchemaDescriptor()
+	if p.Errored() {
+		return nil, nil
+	}
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i] = p.UnpackAny(field.TypeTag)
+	}
+	if p.Errored() {
+		return nil, nil
+	}
+	return fields, values
+}
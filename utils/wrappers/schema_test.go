@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPackerSchemaDescriptor(t *testing.T) {
+	fields := []SchemaField{
+		{Name: "id", TypeTag: SchemaLong},
+		{Name: "name", TypeTag: SchemaStr},
+		{Name: "", TypeTag: SchemaBool},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackSchemaDescriptor(fields)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpacked := p2.UnpackSchemaDescriptor()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if !reflect.DeepEqual(unpacked, fields) {
+		t.Fatalf("Packer.UnpackSchemaDescriptor returned %v, expected %v", unpacked, fields)
+	}
+	if p2.Remaining() != 0 {
+		t.Fatalf("descriptor should have been fully consumed but %d bytes remain", p2.Remaining())
+	}
+}
+
+func TestPackerSchemaDescriptorInvalid(t *testing.T) {
+	for _, fields := range [][]SchemaField{
+		{{Name: "a", TypeTag: SchemaBytes + 1}},
+		{{Name: "a", TypeTag: SchemaInt}, {Name: "a", TypeTag: SchemaStr}},
+	} {
+		p := Packer{MaxSize: 1024}
+		p.PackSchemaDescriptor(fields)
+		if p.Err != errInvalidInput {
+			t.Fatalf("packing %v should have failed with %s but returned %v", fields, errInvalidInput, p.Err)
+		}
+	}
+
+	// Unknown version
+	p := Packer{Bytes: []byte{schemaDescriptorVersion + 1, 0, 0}}
+	if p.UnpackSchemaDescriptor(); p.Err != errUnknownSchemaVersion {
+		t.Fatalf("Packer.UnpackSchemaDescriptor should have failed with %s but returned %v", errUnknownSchemaVersion, p.Err)
+	}
+
+	// More fields than could fit in the remaining bytes
+	p = Packer{Bytes: []byte{schemaDescriptorVersion, 0xff, 0xff, 0, 0, SchemaByte}}
+	if p.UnpackSchemaDescriptor(); p.Err != errInvalidInput {
+		t.Fatalf("Packer.UnpackSchemaDescriptor should have failed with %s but returned %v", errInvalidInput, p.Err)
+	}
+}
+
+func TestPackerSchemaMessage(t *testing.T) {
+	fields := []SchemaField{
+		{Name: "kind", TypeTag: SchemaByte},
+		{Name: "port", TypeTag: SchemaShort},
+		{Name: "height", TypeTag: SchemaInt},
+		{Name: "timestamp", TypeTag: SchemaLong},
+		{Name: "final", TypeTag: SchemaBool},
+		{Name: "memo", TypeTag: SchemaStr},
+		{Name: "payload", TypeTag: SchemaBytes},
+	}
+	values := []interface{}{
+		byte(7),
+		uint16(9651),
+		uint32(100),
+		uint64(1588000000),
+		true,
+		"hello",
+		[]byte{1, 2, 3},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackSchemaMessage(fields, values)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// The message is decoded without knowing its schema in advance
+	p2 := Packer{Bytes: p.Bytes}
+	unpackedFields, unpackedValues := p2.UnpackSchemaMessage()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if !reflect.DeepEqual(unpackedFields, fields) {
+		t.Fatalf("Packer.UnpackSchemaMessage returned fields %v, expected %v", unpackedFields, fields)
+	}
+	if !reflect.DeepEqual(unpackedValues, values) {
+		t.Fatalf("Packer.UnpackSchemaMessage returned values %v, expected %v", unpackedValues, values)
+	}
+
+	p3 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if fields, values := p3.UnpackSchemaMessage(); !p3.Errored() || fields != nil || values != nil {
+		t.Fatal("Packer.UnpackSchemaMessage should have failed on a truncated message")
+	}
+}
+
+func TestPackerSchemaMessageMismatch(t *testing.T) {
+	fields := []SchemaField{{Name: "height", TypeTag: SchemaInt}}
+
+	p := Packer{MaxSize: 1024}
+	p.PackSchemaMessage(fields, []interface{}{"not an int"})
+	if p.Err != errSchemaMismatch {
+		t.Fatalf("packing a value of the wrong type should have failed with %s but returned %v", errSchemaMismatch, p.Err)
+	}
+
+	p = Packer{MaxSize: 1024}
+	p.PackSchemaMessage(fields, nil)
+	if p.Err != errSchemaMismatch {
+		t.Fatalf("packing the wrong number of values should have failed with %s but returned %v", errSchemaMismatch, p.Err)
+	}
+
+	p = Packer{MaxSize: 1024}
+	p.PackAny(SchemaBytes+1, nil)
+	if p.Err != errUnknownTypeTag {
+		t.Fatalf("packing an unknown type should have failed with %s but returned %v", errUnknownTypeTag, p.Err)
+	}
+}
@@ -0,0 +1,56 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+// minStateDiffEntryLen is the minimum number of bytes of a packed entry: an
+// empty key and its presence byte
+const minStateDiffEntryLen = IntLen + BoolLen
+
+// StateDiffEntry is a change to a key of a key/value state. If [Present], the
+// key is set to [Value]. Otherwise, the key is deleted.
+type StateDiffEntry struct {
+	Key     []byte
+	Present bool
+	Value   []byte
+}
+
+// PackStateDiff appends [diff] to the byte array. Each entry is packed as its
+// key and a presence byte, followed by its value if it's present. The values
+// of deletions aren't packed.
+func (p *Packer) PackStateDiff(diff []StateDiffEntry) {
+	p.PackVarInt(uint64(len(diff)))
+	for _, entry := range diff {
+		p.PackBytes(entry.Key)
+		p.PackBool(entry.Present)
+		if entry.Present {
+			p.PackBytes(entry.Value)
+		}
+	}
+}
+
+// UnpackStateDiff unpacks the diff packed by PackStateDiff from the byte
+// array. Deletions have a nil Value.
+func (p *Packer) UnpackStateDiff() []StateDiffEntry {
+	numEntries := p.UnpackVarInt()
+	if p.Errored() {
+		return nil
+	}
+	if numEntries > uint64(p.Remaining())/minStateDiffEntryLen {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	diff := make([]StateDiffEntry, numEntries)
+	for i := range diff {
+		diff[i].Key = p.UnpackBytes()
+		diff[i].Present = p.UnpackBool()
+		if diff[i].Present {
+			diff[i].Value = p.UnpackBytes()
+		}
+		if p.Errored() {
+			return nil
+		}
+	}
+	return diff
+}
@@ -0,0 +1,78 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPackerStateDiff(t *testing.T) {
+	diff := []StateDiffEntry{
+		{Key: []byte("balance"), Present: true, Value: []byte{0, 0, 0, 42}},
+		{Key: []byte("nonce"), Present: false, Value: []byte("ignored")},
+		{Key: []byte("empty"), Present: true, Value: []byte{}},
+		{Key: []byte("owner"), Present: false},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackStateDiff(diff)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if bytes.Contains(p.Bytes, []byte("ignored")) {
+		t.Fatal("Packer.PackStateDiff shouldn't have packed the value of a deletion")
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpacked := p2.UnpackStateDiff()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if p2.Remaining() != 0 {
+		t.Fatalf("Packer.UnpackStateDiff left %d unread bytes", p2.Remaining())
+	}
+	if len(unpacked) != len(diff) {
+		t.Fatalf("Packer.UnpackStateDiff returned %d entries, expected %d", len(unpacked), len(diff))
+	}
+	for i, entry := range unpacked {
+		expected := diff[i]
+		if !expected.Present {
+			expected.Value = nil
+		}
+		if !bytes.Equal(entry.Key, expected.Key) || entry.Present != expected.Present || !bytes.Equal(entry.Value, expected.Value) {
+			t.Fatalf("entry %d was %+v, expected %+v", i, entry, expected)
+		}
+		if !entry.Present && entry.Value != nil {
+			t.Fatalf("deletion %d should have had a nil value", i)
+		}
+	}
+}
+
+func TestPackerUnpackStateDiffInvalid(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackStateDiff([]StateDiffEntry{{Key: []byte("key"), Present: true, Value: []byte("value")}})
+
+	// Truncated value
+	p2 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if diff := p2.UnpackStateDiff(); !p2.Errored() || diff != nil {
+		t.Fatal("Packer.UnpackStateDiff should have failed on a truncated diff")
+	}
+
+	// Invalid presence byte
+	p3 := Packer{MaxSize: 1024}
+	p3.PackVarInt(1)
+	p3.PackBytes([]byte("key"))
+	p3.PackByte(2)
+	p4 := Packer{Bytes: p3.Bytes}
+	if diff := p4.UnpackStateDiff(); p4.Err != errBadBool || diff != nil {
+		t.Fatalf("Packer.UnpackStateDiff should have failed with %s but failed with %v", errBadBool, p4.Err)
+	}
+
+	// More entries than could fit
+	p5 := Packer{Bytes: []byte{100, 0, 0, 0, 0, 0}}
+	if diff := p5.UnpackStateDiff(); p5.Err != errInvalidInput || diff != nil {
+		t.Fatalf("Packer.UnpackStateDiff should have failed with %s but failed with %v", errInvalidInput, p5.Err)
+	}
+}
@@ -0,0 +1,62 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"errors"
+	"sort"
+)
+
+// minStrBytesEntryLen is the minimum number of bytes of a packed map entry: an
+// empty key and an empty value
+const minStrBytesEntryLen = ShortLen + IntLen
+
+var errMapKeysOutOfOrder = errors.New("map keys must be sorted and unique")
+
+// PackStrBytesMap appends [m] to the byte array as the number of entries
+// followed by each entry's key and value, sorted by key. Maps with the same
+// entries always pack to the same bytes, so the bytes can be hashed.
+func (p *Packer) PackStrBytesMap(m map[string][]byte) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	p.PackInt(uint32(len(keys)))
+	for _, key := range keys {
+		p.PackStr(key)
+		p.PackBytes(m[key])
+	}
+}
+
+// UnpackStrBytesMap unpacks a map packed by PackStrBytesMap from the byte
+// array. Entries must be sorted by key, so every map has one encoding.
+func (p *Packer) UnpackStrBytesMap() map[string][]byte {
+	numEntries := p.UnpackInt()
+	if p.Errored() {
+		return nil
+	}
+	if numEntries > uint32(p.Remaining()/minStrBytesEntryLen) {
+		p.Add(errInvalidInput)
+		return nil
+	}
+
+	m := make(map[string][]byte, numEntries)
+	prevKey := ""
+	for i := uint32(0); i < numEntries; i++ {
+		key := p.UnpackStr()
+		value := p.UnpackBytes()
+		if p.Errored() {
+			return nil
+		}
+		if i > 0 && key <= prevKey {
+			p.Add(errMapKeysOutOfOrder)
+			return nil
+		}
+		prevKey = key
+		m[key] = value
+	}
+	return m
+}
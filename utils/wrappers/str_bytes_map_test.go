@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestPackerStrBytesMap(t *testing.T) {
+	m := map[string][]byte{
+		"owner": []byte("alice"),
+		"":      []byte("empty key"),
+		"memo":  {},
+	}
+
+	p := Packer{MaxSize: 1024}
+	p.PackStrBytesMap(m)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpacked := p2.UnpackStrBytesMap()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if !reflect.DeepEqual(unpacked, m) {
+		t.Fatalf("Packer.UnpackStrBytesMap returned %v, expected %v", unpacked, m)
+	}
+
+	p3 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if unpacked := p3.UnpackStrBytesMap(); !p3.Errored() || unpacked != nil {
+		t.Fatal("Packer.UnpackStrBytesMap should have failed on a truncated map")
+	}
+}
+
+func TestPackerStrBytesMapDeterministic(t *testing.T) {
+	keys := []string{"delta", "alpha", "charlie", "bravo", "echo"}
+
+	m1 := map[string][]byte{}
+	for _, key := range keys {
+		m1[key] = []byte(key + " value")
+	}
+	m2 := map[string][]byte{}
+	for i := len(keys) - 1; i >= 0; i-- {
+		m2[keys[i]] = []byte(keys[i] + " value")
+	}
+
+	for i := 0; i < 10; i++ {
+		p1 := Packer{MaxSize: 1024}
+		p1.PackStrBytesMap(m1)
+		p2 := Packer{MaxSize: 1024}
+		p2.PackStrBytesMap(m2)
+		if p1.Errored() || p2.Errored() {
+			t.Fatalf("packing failed: %v, %v", p1.Err, p2.Err)
+		}
+		if !bytes.Equal(p1.Bytes, p2.Bytes) {
+			t.Fatalf("maps with the same entries packed to different bytes:\n%v\n%v", p1.Bytes, p2.Bytes)
+		}
+	}
+}
+
+func TestPackerStrBytesMapOutOfOrder(t *testing.T) {
+	for _, keys := range [][]string{{"b", "a"}, {"a", "a"}} {
+		p := Packer{MaxSize: 1024}
+		p.PackInt(uint32(len(keys)))
+		for _, key := range keys {
+			p.PackStr(key)
+			p.PackBytes([]byte("value"))
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		if p2.UnpackStrBytesMap(); p2.Err != errMapKeysOutOfOrder {
+			t.Fatalf("unpacking keys %v should have failed with %s but returned %v", keys, errMapKeysOutOfOrder, p2.Err)
+		}
+	}
+
+	// More entries than could fit in the remaining bytes
+	p := Packer{Bytes: []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0}}
+	if p.UnpackStrBytesMap(); p.Err != errInvalidInput {
+		t.Fatalf("Packer.UnpackStrBytesMap should have failed with %s but returned %v", errInvalidInput, p.Err)
+	}
+}
@@ -0,0 +1,104 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+// StringTablePacker packs strings that are likely to be repeated. Each unique
+// string is written once, in a table, and every occurrence is written as a
+// varint index into that table.
+//
+// When packing, strings are collected by PackStr and written to the
+// underlying packer by Flush. When unpacking, the table and indices are read
+// from the underlying packer by Load and returned, in order, by UnpackStr.
+type StringTablePacker struct {
+	Packer *Packer
+
+	table   []string
+	indices map[string]uint64
+	refs    []uint64
+	next    int
+}
+
+// PackStr interns [str] in the table and records an occurrence of it
+func (s *StringTablePacker) PackStr(str string) {
+	if len(str) > MaxStringLen {
+		s.Packer.Add(errInvalidInput)
+		return
+	}
+	if s.indices == nil {
+		s.indices = make(map[string]uint64)
+	}
+	index, exists := s.indices[str]
+	if !exists {
+		index = uint64(len(s.table))
+		s.indices[str] = index
+		s.table = append(s.table, str)
+	}
+	s.refs = append(s.refs, index)
+}
+
+// Flush appends the table, followed by the index of every string packed with
+// PackStr, to the byte array of the underlying packer.
+func (s *StringTablePacker) Flush() {
+	s.Packer.PackVarInt(uint64(len(s.table)))
+	for _, str := range s.table {
+		s.Packer.PackStr(str)
+	}
+	s.Packer.PackVarInt(uint64(len(s.refs)))
+	for _, ref := range s.refs {
+		s.Packer.PackVarInt(ref)
+	}
+}
+
+// Load unpacks the table and indices written by Flush from the byte array of
+// the underlying packer.
+func (s *StringTablePacker) Load() {
+	p := s.Packer
+
+	// Every string takes at least ShortLen bytes and every index takes at least
+	// one byte, so larger counts are rejected before allocating.
+	numStrs := p.UnpackVarInt()
+	if p.Errored() {
+		return
+	}
+	if numStrs > uint64(p.Remaining())/ShortLen {
+		p.Add(errInvalidInput)
+		return
+	}
+	s.table = make([]string, 0, numStrs)
+	for i := uint64(0); i < numStrs && !p.Errored(); i++ {
+		s.table = append(s.table, p.UnpackStr())
+	}
+
+	numRefs := p.UnpackVarInt()
+	if p.Errored() {
+		return
+	}
+	if numRefs > uint64(p.Remaining()) {
+		p.Add(errInvalidInput)
+		return
+	}
+	s.refs = make([]uint64, 0, numRefs)
+	for i := uint64(0); i < numRefs && !p.Errored(); i++ {
+		ref := p.UnpackVarInt()
+		if ref >= numStrs {
+			p.Add(errInvalidInput)
+		}
+		s.refs = append(s.refs, ref)
+	}
+	s.next = 0
+}
+
+// UnpackStr returns the next string that was loaded by Load
+func (s *StringTablePacker) UnpackStr() string {
+	if s.Packer.Errored() {
+		return ""
+	}
+	if s.next >= len(s.refs) {
+		s.Packer.Add(errBadLength)
+		return ""
+	}
+	str := s.table[s.refs[s.next]]
+	s.next++
+	return str
+}
@@ -0,0 +1,77 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"testing"
+)
+
+func TestStringTablePacker(t *testing.T) {
+	labels := []string{}
+	for i := 0; i < 100; i++ {
+		labels = append(labels, "chainID", "subnetID", "method", "status")
+	}
+
+	p := Packer{MaxSize: 1 << 16}
+	s := StringTablePacker{Packer: &p}
+	for _, label := range labels {
+		s.PackStr(label)
+	}
+	s.Flush()
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	naive := Packer{MaxSize: 1 << 16}
+	for _, label := range labels {
+		naive.PackStr(label)
+	}
+	if naive.Errored() {
+		t.Fatal(naive.Err)
+	}
+
+	if len(p.Bytes) >= len(naive.Bytes)/2 {
+		t.Fatalf("StringTablePacker wrote %d bytes, expected less than half of the %d bytes written by PackStr",
+			len(p.Bytes), len(naive.Bytes))
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	s2 := StringTablePacker{Packer: &p2}
+	s2.Load()
+	for i, expected := range labels {
+		if str := s2.UnpackStr(); p2.Errored() {
+			t.Fatal(p2.Err)
+		} else if str != expected {
+			t.Fatalf("StringTablePacker.UnpackStr returned %q at %d, expected %q", str, i, expected)
+		}
+	}
+	if p2.Offset != len(p2.Bytes) {
+		t.Fatalf("StringTablePacker.Load left %d unread bytes", len(p2.Bytes)-p2.Offset)
+	}
+
+	s2.UnpackStr()
+	if !p2.Errored() {
+		t.Fatal("StringTablePacker.UnpackStr did not fail when all strings were already unpacked")
+	}
+}
+
+func TestStringTablePackerBadIndex(t *testing.T) {
+	// A table with one string followed by a reference to a second string
+	p := Packer{Bytes: []byte{0x01, 0x00, 0x01, 'a', 0x01, 0x01}}
+	s := StringTablePacker{Packer: &p}
+	s.Load()
+	if !p.Errored() {
+		t.Fatal("StringTablePacker.Load did not fail when an index was out of range")
+	}
+}
+
+func TestStringTablePackerLargeTable(t *testing.T) {
+	// Claims to have 100 strings but only has 2 bytes remaining
+	p := Packer{Bytes: []byte{0x64, 0x00, 0x00}}
+	s := StringTablePacker{Packer: &p}
+	s.Load()
+	if !p.Errored() {
+		t.Fatal("StringTablePacker.Load did not fail when the table was larger than the remaining bytes")
+	}
+}
@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// MaxTimeLockSigLen is the maximum length of a time-lock record's signature
+const MaxTimeLockSigLen = 1024
+
+// TimeLock is a commitment to data that may only be revealed once its unlock
+// time has passed
+type TimeLock struct {
+	Commitment [hashing.HashLen]byte
+	// Unix time, in seconds, at which the data may be revealed
+	UnlockTime int64
+	Sig        []byte
+}
+
+// CanReveal returns true if the data committed to may be revealed at [now],
+// allowing for local clock skew of up to [maxDrift]. Callers should pass the
+// same drift they tolerate on block timestamps (e.g. the VM's MaxFutureDrift).
+// Only the wall clock reading of [now] is used, as the monotonic reading can't
+// be compared to an absolute unlock time.
+func (tl TimeLock) CanReveal(now time.Time, maxDrift time.Duration) bool {
+	return now.Add(maxDrift).Unix() >= tl.UnlockTime
+}
+
+// PackTimeLock appends a time-lock record to the byte array. A record is:
+// * [commitment] (32 bytes)
+// * [unlockTime] (8 bytes)
+// * The length of [sig] (4 bytes)
+// * [sig]
+func (p *Packer) PackTimeLock(commitment [hashing.HashLen]byte, unlockTime int64, sig []byte) {
+	if len(sig) > MaxTimeLockSigLen {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackFixedBytes(commitment[:])
+	p.PackSignedLong(unlockTime)
+	p.PackBytes(sig)
+}
+
+// UnpackTimeLock unpacks a time-lock record packed by PackTimeLock from the
+// byte array
+func (p *Packer) UnpackTimeLock() TimeLock {
+	tl := TimeLock{}
+	copy(tl.Commitment[:], p.UnpackFixedBytes(hashing.HashLen))
+	tl.UnlockTime = p.UnpackSignedLong()
+	sigLen := p.UnpackInt()
+	if sigLen > MaxTimeLockSigLen {
+		p.Add(errInvalidInput)
+	}
+	tl.Sig = p.UnpackFixedBytes(int(sigLen))
+	if p.Errored() {
+		return TimeLock{}
+	}
+	return tl
+}
@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPackerTimeLock(t *testing.T) {
+	commitment := [32]byte{1, 2, 3}
+	sig := []byte("signature")
+
+	p := Packer{MaxSize: 1024}
+	p.PackTimeLock(commitment, -5, sig)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if len(p.Bytes) != 32+LongLen+IntLen+len(sig) {
+		t.Fatalf("time-lock record should have been %d bytes but was %d", 32+LongLen+IntLen+len(sig), len(p.Bytes))
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	tl := p2.UnpackTimeLock()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	expected := TimeLock{Commitment: commitment, UnlockTime: -5, Sig: sig}
+	if !reflect.DeepEqual(tl, expected) {
+		t.Fatalf("Packer.UnpackTimeLock returned %v, expected %v", tl, expected)
+	}
+
+	p3 := Packer{Bytes: p.Bytes[:len(p.Bytes)-1]}
+	if tl := p3.UnpackTimeLock(); !p3.Errored() || !reflect.DeepEqual(tl, TimeLock{}) {
+		t.Fatal("Packer.UnpackTimeLock should have failed on a truncated record")
+	}
+}
+
+func TestPackerTimeLockSigTooLong(t *testing.T) {
+	p := Packer{MaxSize: 4096}
+	p.PackTimeLock([32]byte{}, 0, make([]byte, MaxTimeLockSigLen+1))
+	if p.Err != errInvalidInput {
+		t.Fatalf("packing a signature that's too long should have failed with %s but returned %v", errInvalidInput, p.Err)
+	}
+
+	p = Packer{MaxSize: 1024}
+	p.PackFixedBytes(make([]byte, 32))
+	p.PackSignedLong(0)
+	p.PackInt(MaxTimeLockSigLen + 1)
+	p2 := Packer{Bytes: p.Bytes}
+	if p2.UnpackTimeLock(); p2.Err != errInvalidInput {
+		t.Fatalf("Packer.UnpackTimeLock should have failed with %s but returned %v", errInvalidInput, p2.Err)
+	}
+}
+
+func TestTimeLockCanReveal(t *testing.T) {
+	unlock := time.Unix(1000, 0)
+	tl := TimeLock{UnlockTime: unlock.Unix()}
+	if tl.CanReveal(unlock.Add(-time.Second), 0) {
+		t.Fatal("shouldn't be able to reveal before the unlock time")
+	}
+	if tl.CanReveal(unlock.Add(-time.Nanosecond), 0) {
+		t.Fatal("shouldn't be able to reveal just before the unlock time")
+	}
+	if !tl.CanReveal(unlock, 0) {
+		t.Fatal("should be able to reveal at the unlock time")
+	}
+	if !tl.CanReveal(unlock.Add(time.Hour), 0) {
+		t.Fatal("should be able to reveal after the unlock time")
+	}
+
+	// Only the wall clock is compared, so a time with a monotonic reading
+	// behaves like the same time without one
+	now := time.Now()
+	tl = TimeLock{UnlockTime: now.Unix()}
+	if tl.CanReveal(now, 0) != tl.CanReveal(now.Round(0), 0) {
+		t.Fatal("the monotonic clock reading shouldn't affect revealing")
+	}
+}
+
+func TestTimeLockCanRevealDrift(t *testing.T) {
+	unlock := time.Unix(1000, 0)
+	tl := TimeLock{UnlockTime: unlock.Unix()}
+	if !tl.CanReveal(unlock.Add(-10*time.Second), 10*time.Second) {
+		t.Fatal("should be able to reveal within the allowed drift")
+	}
+	if tl.CanReveal(unlock.Add(-11*time.Second), 10*time.Second) {
+		t.Fatal("shouldn't be able to reveal beyond the allowed drift")
+	}
+}
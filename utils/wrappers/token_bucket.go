@@ -0,0 +1,40 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"math"
+	"time"
+)
+
+// PackTokenBucket appends the state of a token bucket to the byte array: the
+// number of [tokens] in the bucket and the time of its [lastRefill], with
+// nanosecond precision. [tokens] must be finite and non-negative.
+func (p *Packer) PackTokenBucket(tokens float64, lastRefill time.Time) {
+	if !validTokens(tokens) {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackLong(math.Float64bits(tokens))
+	p.PackTimeResolution(lastRefill, time.Nanosecond)
+}
+
+// UnpackTokenBucket unpacks the state of a token bucket packed by
+// PackTokenBucket from the byte array
+func (p *Packer) UnpackTokenBucket() (float64, time.Time) {
+	tokens := math.Float64frombits(p.UnpackLong())
+	lastRefill := p.UnpackTimeResolution(time.Nanosecond)
+	if p.Errored() {
+		return 0, time.Time{}
+	}
+	if !validTokens(tokens) {
+		p.Add(errInvalidInput)
+		return 0, time.Time{}
+	}
+	return tokens, lastRefill
+}
+
+func validTokens(tokens float64) bool {
+	return tokens >= 0 && !math.IsInf(tokens, 1)
+}
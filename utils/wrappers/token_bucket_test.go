@@ -0,0 +1,51 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPackerTokenBucket(t *testing.T) {
+	lastRefill := time.Unix(1588000000, 123456789)
+
+	p := Packer{MaxSize: 1024}
+	p.PackTokenBucket(2.75, lastRefill)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	tokens, refill := p2.UnpackTokenBucket()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if tokens != 2.75 || !refill.Equal(lastRefill) {
+		t.Fatalf("Packer.UnpackTokenBucket returned (%v, %s), expected (%v, %s)", tokens, refill, 2.75, lastRefill)
+	}
+	if p2.Offset != len(p2.Bytes) {
+		t.Fatalf("Packer.UnpackTokenBucket left %d unread bytes", len(p2.Bytes)-p2.Offset)
+	}
+}
+
+func TestPackerTokenBucketInvalidTokens(t *testing.T) {
+	for _, tokens := range []float64{-1, math.NaN(), math.Inf(1)} {
+		p := Packer{MaxSize: 1024}
+		p.PackTokenBucket(tokens, time.Unix(0, 0))
+		if !p.Errored() {
+			t.Fatalf("Packer.PackTokenBucket should have rejected %v tokens", tokens)
+		}
+
+		// Bypass the packer's validation
+		p = Packer{MaxSize: 1024}
+		p.PackLong(math.Float64bits(tokens))
+		p.PackVarInt(0)
+		p2 := Packer{Bytes: p.Bytes}
+		if p2.UnpackTokenBucket(); !p2.Errored() {
+			t.Fatalf("Packer.UnpackTokenBucket should have rejected %v tokens", tokens)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// TrieNodeType is the type of a node in a Merkle Patricia trie
+type TrieNodeType byte
+
+// Types of trie nodes
+const (
+	// A branch has a child for each nibble, and optionally a value
+	BranchNode TrieNodeType = iota
+	// An extension has a shared key and a single child
+	ExtensionNode
+	// A leaf has the remainder of a key and a value
+	LeafNode
+)
+
+// TrieBranchFactor is the number of children of a branch node
+const TrieBranchFactor = 16
+
+// TrieNode is a node in a Merkle Patricia trie. Children are referenced by
+// hash.
+type TrieNode struct {
+	Type TrieNodeType
+	// Key nibbles of an extension or leaf node. Each nibble is less than
+	// TrieBranchFactor.
+	Key []byte
+	// Children of a branch node. Absent children are nil.
+	Children [TrieBranchFactor][]byte
+	// Child of an extension node
+	Child []byte
+	// Value of a branch or leaf node
+	Value []byte
+}
+
+// PackTrieNode appends [node] to the byte array. A node is packed as its type
+// followed by:
+// * Branch: a bitmap of its present children, their hashes, and its value
+// * Extension: its key nibbles and its child's hash
+// * Leaf: its key nibbles and its value
+func (p *Packer) PackTrieNode(node *TrieNode) {
+	p.PackByte(byte(node.Type))
+	switch node.Type {
+	case BranchNode:
+		bitmap := uint16(0)
+		for i, child := range node.Children {
+			if child != nil {
+				bitmap |= 1 << uint(i)
+			}
+		}
+		p.PackShort(bitmap)
+		for _, child := range node.Children {
+			if child != nil {
+				p.packTrieHash(child)
+			}
+		}
+		p.PackBytes(node.Value)
+	case ExtensionNode:
+		p.packNibbles(node.Key)
+		p.packTrieHash(node.Child)
+	case LeafNode:
+		p.packNibbles(node.Key)
+		p.PackBytes(node.Value)
+	default:
+		p.Add(errInvalidInput)
+	}
+}
+
+// UnpackTrieNode unpacks a trie node from the byte array
+func (p *Packer) UnpackTrieNode() *TrieNode {
+	node := &TrieNode{Type: TrieNodeType(p.UnpackByte())}
+	switch node.Type {
+	case BranchNode:
+		bitmap := p.UnpackShort()
+		for i := range node.Children {
+			if bitmap&(1<<uint(i)) != 0 {
+				node.Children[i] = p.UnpackFixedBytes(hashing.HashLen)
+			}
+		}
+		node.Value = p.UnpackBytes()
+	case ExtensionNode:
+		node.Key = p.unpackNibbles()
+		node.Child = p.UnpackFixedBytes(hashing.HashLen)
+	case LeafNode:
+		node.Key = p.unpackNibbles()
+		node.Value = p.UnpackBytes()
+	default:
+		p.Add(errInvalidInput)
+	}
+	if p.Errored() {
+		return nil
+	}
+	return node
+}
+
+func (p *Packer) packTrieHash(hash []byte) {
+	if len(hash) != hashing.HashLen {
+		p.Add(errInvalidInput)
+		return
+	}
+	p.PackFixedBytes(hash)
+}
+
+// packNibbles appends the number of [nibbles] followed by the nibbles, two per
+// byte. If there are an odd number of nibbles, the last byte is zero padded.
+func (p *Packer) packNibbles(nibbles []byte) {
+	if len(nibbles) > MaxStringLen {
+		p.Add(errInvalidInput)
+		return
+	}
+	packed := make([]byte, (len(nibbles)+1)/2)
+	for i, nibble := range nibbles {
+		if nibble >= TrieBranchFactor {
+			p.Add(errInvalidInput)
+			return
+		}
+		packed[i/2] |= nibble << (4 * uint(1-i%2))
+	}
+	p.PackShort(uint16(len(nibbles)))
+	p.PackFixedBytes(packed)
+}
+
+func (p *Packer) unpackNibbles() []byte {
+	numNibbles := int(p.UnpackShort())
+	packed := p.UnpackFixedBytes((numNibbles + 1) / 2)
+	if p.Errored() {
+		return nil
+	}
+	// Reject non-zero padding so that every key has one encoding
+	if numNibbles%2 == 1 && packed[len(packed)-1]&0x0f != 0 {
+		p.Add(errInvalidInput)
+		return nil
+	}
+	nibbles := make([]byte, numNibbles)
+	for i := range nibbles {
+		nibbles[i] = (packed[i/2] >> (4 * uint(1-i%2))) & 0x0f
+	}
+	return nibbles
+}
@@ -0,0 +1,110 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wrappers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func assertTrieNodesEqual(t *testing.T, expected, actual *TrieNode) {
+	if actual == nil {
+		t.Fatal("unpacked trie node is nil")
+	}
+	if expected.Type != actual.Type {
+		t.Fatalf("expected type %d but got %d", expected.Type, actual.Type)
+	}
+	if !bytes.Equal(expected.Key, actual.Key) {
+		t.Fatalf("expected key %v but got %v", expected.Key, actual.Key)
+	}
+	for i := range expected.Children {
+		if !bytes.Equal(expected.Children[i], actual.Children[i]) {
+			t.Fatalf("expected child %d to be %v but got %v", i, expected.Children[i], actual.Children[i])
+		}
+	}
+	if !bytes.Equal(expected.Child, actual.Child) {
+		t.Fatalf("expected child %v but got %v", expected.Child, actual.Child)
+	}
+	if !bytes.Equal(expected.Value, actual.Value) {
+		t.Fatalf("expected value %v but got %v", expected.Value, actual.Value)
+	}
+}
+
+func TestPackerTrieNode(t *testing.T) {
+	hash1 := hashing.ComputeHash256([]byte{1})
+	hash2 := hashing.ComputeHash256([]byte{2})
+
+	branch := &TrieNode{Type: BranchNode, Value: []byte("branch value")}
+	branch.Children[0] = hash1
+	branch.Children[15] = hash2
+
+	nodes := []*TrieNode{
+		branch,
+		{Type: BranchNode},
+		{Type: ExtensionNode, Key: []byte{1, 2, 3}, Child: hash1},
+		{Type: ExtensionNode, Key: []byte{0xf, 0}, Child: hash2},
+		{Type: LeafNode, Key: []byte{4, 5, 6, 7}, Value: []byte("leaf value")},
+		{Type: LeafNode, Key: []byte{}, Value: []byte{}},
+	}
+	for _, node := range nodes {
+		p := Packer{MaxSize: 1024}
+		p.PackTrieNode(node)
+		if p.Errored() {
+			t.Fatal(p.Err)
+		}
+
+		p2 := Packer{Bytes: p.Bytes}
+		unpacked := p2.UnpackTrieNode()
+		if p2.Errored() {
+			t.Fatal(p2.Err)
+		}
+		if p2.Offset != len(p2.Bytes) {
+			t.Fatalf("Packer.UnpackTrieNode left %d unread bytes", len(p2.Bytes)-p2.Offset)
+		}
+		assertTrieNodesEqual(t, node, unpacked)
+	}
+}
+
+func TestPackerTrieNodeNibbles(t *testing.T) {
+	p := Packer{MaxSize: 1024}
+	p.PackTrieNode(&TrieNode{Type: LeafNode, Key: []byte{1, 2, 3}})
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	// type, nibble count, 2 bytes of nibbles, and an empty value
+	expected := []byte{byte(LeafNode), 0x00, 0x03, 0x12, 0x30, 0x00, 0x00, 0x00, 0x00}
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackTrieNode wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+}
+
+func TestPackerTrieNodeInvalid(t *testing.T) {
+	nodes := []*TrieNode{
+		{Type: LeafNode + 1},
+		{Type: LeafNode, Key: []byte{TrieBranchFactor}},
+		{Type: ExtensionNode, Key: []byte{1}, Child: []byte{1, 2, 3}},
+	}
+	for _, node := range nodes {
+		p := Packer{MaxSize: 1024}
+		if p.PackTrieNode(node); !p.Errored() {
+			t.Fatalf("Packer.PackTrieNode should have failed on %+v", node)
+		}
+	}
+
+	tests := [][]byte{
+		{byte(LeafNode + 1)},
+		{byte(LeafNode), 0x00, 0x01, 0x11, 0x00, 0x00, 0x00, 0x00}, // Non-zero padding
+		{byte(ExtensionNode), 0x00, 0x01, 0x10, 0x01},              // Truncated hash
+		{byte(BranchNode), 0x00, 0x01, 0x00, 0x00, 0x00, 0x00},     // Missing child
+	}
+	for _, test := range tests {
+		p := Packer{Bytes: test}
+		if p.UnpackTrieNode(); !p.Errored() {
+			t.Fatalf("Packer.UnpackTrieNode(%v) should have failed", test)
+		}
+	}
+}
@@ -91,6 +91,11 @@ func (cr *codecRegistry) RegisterType(val interface{}) error {
 	cr.typeToFxIndex[valType] = cr.index
 	return cr.codec.RegisterType(val)
 }
+func (cr *codecRegistry) RegisterTypeWithID(typeID uint32, val interface{}) error {
+	valType := reflect.TypeOf(val)
+	cr.typeToFxIndex[valType] = cr.index
+	return cr.codec.RegisterTypeWithID(typeID, val)
+}
 func (cr *codecRegistry) Marshal(val interface{}) ([]byte, error)   { return cr.codec.Marshal(val) }
 func (cr *codecRegistry) Unmarshal(b []byte, val interface{}) error { return cr.codec.Unmarshal(b, val) }
 
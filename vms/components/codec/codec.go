@@ -6,7 +6,9 @@ package codec
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"time"
 	"unicode"
 
 	"github.com/ava-labs/gecko/utils/wrappers"
@@ -31,6 +33,18 @@ var (
 	errUnmarshalUnexportedField  = errors.New("can't deserialize into an unexported field")
 	errOutOfMemory               = errors.New("out of memory")
 	errSliceTooLarge             = errors.New("slice too large")
+	errTimeOutOfRange            = errors.New("time can't be represented as int64 nanoseconds since the Unix epoch")
+)
+
+var (
+	// time.Time values are serialized as an int64 of nanoseconds since the
+	// Unix epoch, so they don't depend on the location or monotonic clock.
+	// The zero time is serialized as [zeroTimeNanos], so [minTime] itself
+	// can't be serialized.
+	timeType      = reflect.TypeOf(time.Time{})
+	minTime       = time.Unix(0, math.MinInt64)
+	maxTime       = time.Unix(0, math.MaxInt64)
+	zeroTimeNanos = int64(math.MinInt64)
 )
 
 // Codec handles marshaling and unmarshaling of structs
@@ -45,6 +59,7 @@ type codec struct {
 // Codec marshals and unmarshals
 type Codec interface {
 	RegisterType(interface{}) error
+	RegisterTypeWithID(uint32, interface{}) error
 	Marshal(interface{}) ([]byte, error)
 	Unmarshal([]byte, interface{}) error
 }
@@ -64,13 +79,23 @@ func NewDefault() Codec { return New(defaultMaxSize, defaultMaxSliceLength) }
 
 // RegisterType is used to register types that may be unmarshaled into an interface typed value
 // [val] is a value of the type being registered
+// The type's ID is the number of types registered before it
 func (c codec) RegisterType(val interface{}) error {
+	return c.RegisterTypeWithID(uint32(len(c.typeIDToType)), val)
+}
+
+// RegisterTypeWithID registers the type of [val] with the ID [typeID], which
+// is written before each value of the type that is marshaled as an interface
+func (c codec) RegisterTypeWithID(typeID uint32, val interface{}) error {
 	valType := reflect.TypeOf(val)
 	if _, exists := c.typeToTypeID[valType]; exists {
 		return fmt.Errorf("type %v has already been registered", valType)
 	}
-	c.typeIDToType[uint32(len(c.typeIDToType))] = reflect.TypeOf(val)
-	c.typeToTypeID[valType] = uint32(len(c.typeIDToType) - 1)
+	if typ, exists := c.typeIDToType[typeID]; exists {
+		return fmt.Errorf("type ID %d has already been registered to type %v", typeID, typ)
+	}
+	c.typeIDToType[typeID] = valType
+	c.typeToTypeID[valType] = typeID
 	return nil
 }
 
@@ -112,6 +137,19 @@ func (c codec) marshal(value reflect.Value) ([]byte, error) {
 		}
 	}
 
+	if t == timeType {
+		timestamp := value.Interface().(time.Time)
+		switch {
+		case timestamp.IsZero():
+			p.PackLong(uint64(zeroTimeNanos))
+		case !timestamp.After(minTime) || timestamp.After(maxTime):
+			return nil, errTimeOutOfRange
+		default:
+			p.PackLong(uint64(timestamp.UnixNano()))
+		}
+		return p.Bytes, p.Err
+	}
+
 	switch valueKind {
 	case reflect.Uint8:
 		p.PackByte(uint8(value.Uint()))
@@ -137,6 +175,12 @@ func (c codec) marshal(value reflect.Value) ([]byte, error) {
 	case reflect.Int64:
 		p.PackLong(uint64(value.Int()))
 		return p.Bytes, p.Err
+	case reflect.Float32:
+		p.PackFloat32(float32(value.Float()))
+		return p.Bytes, p.Err
+	case reflect.Float64:
+		p.PackFloat64(value.Float())
+		return p.Bytes, p.Err
 	case reflect.Uintptr, reflect.Ptr:
 		return c.marshal(value.Elem())
 	case reflect.String:
@@ -148,7 +192,7 @@ func (c codec) marshal(value reflect.Value) ([]byte, error) {
 	case reflect.Interface:
 		typeID, ok := c.typeToTypeID[reflect.TypeOf(value.Interface())] // Get the type ID of the value being marshaled
 		if !ok {
-			return nil, fmt.Errorf("can't marshal unregistered type '%v'", reflect.TypeOf(value.Interface()).String())
+			return nil, fmt.Errorf("%w '%v'", errMarshalUnregisteredType, reflect.TypeOf(value.Interface()).String())
 		}
 		p.PackInt(typeID)
 		bytes, err := c.Marshal(value.Interface())
@@ -237,6 +281,15 @@ func (c codec) Unmarshal(bytes []byte, dest interface{}) error {
 // Unmarshal bytes from [p] into [field]
 // [field] must be addressable
 func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
+	if field.Type() == timeType {
+		if nanos := int64(p.UnpackLong()); nanos == zeroTimeNanos {
+			field.Set(reflect.ValueOf(time.Time{}))
+		} else {
+			field.Set(reflect.ValueOf(time.Unix(0, nanos).UTC()))
+		}
+		return p.Err
+	}
+
 	kind := field.Kind()
 	switch kind {
 	case reflect.Uint8:
@@ -255,6 +308,10 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 		field.SetUint(p.UnpackLong())
 	case reflect.Int64:
 		field.SetInt(int64(p.UnpackLong()))
+	case reflect.Float32:
+		field.SetFloat(float64(p.UnpackFloat32()))
+	case reflect.Float64:
+		field.SetFloat(p.UnpackFloat64())
 	case reflect.Bool:
 		field.SetBool(p.UnpackBool())
 	case reflect.Slice:
@@ -286,7 +343,7 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 		// Get a struct that implements the interface
 		typ, ok := c.typeIDToType[typeID]
 		if !ok {
-			return errUnmarshalUnregisteredType
+			return fmt.Errorf("%w with ID %d", errUnmarshalUnregisteredType, typeID)
 		}
 		// Ensure struct actually does implement the interface
 		fieldType := field.Type()
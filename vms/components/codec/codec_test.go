@@ -5,8 +5,11 @@ package codec
 
 import (
 	"bytes"
+	"errors"
+	"math"
 	"reflect"
 	"testing"
+	"time"
 )
 
 // The below structs and interfaces exist
@@ -551,6 +554,128 @@ func TestNegativeNumbers(t *testing.T) {
 	}
 }
 
+// Ensure floats, including NaN and infinities, round trip bit for bit
+func TestFloats(t *testing.T) {
+	type s struct {
+		MyFloat32 float32 `serialize:"true"`
+		MyFloat64 float64 `serialize:"true"`
+		NaN       float64 `serialize:"true"`
+	}
+
+	myS := s{-1.5, math.Inf(1), math.Float64frombits(0x7ff8000000000001)}
+
+	codec := NewDefault()
+
+	bytes, err := codec.Marshal(myS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bytes) != 4+8+8 {
+		t.Fatalf("marshaled %d bytes, expected %d", len(bytes), 4+8+8)
+	}
+
+	mySUnmarshaled := s{}
+	if err := codec.Unmarshal(bytes, &mySUnmarshaled); err != nil {
+		t.Fatal(err)
+	}
+
+	if mySUnmarshaled.MyFloat32 != myS.MyFloat32 ||
+		mySUnmarshaled.MyFloat64 != myS.MyFloat64 ||
+		math.Float64bits(mySUnmarshaled.NaN) != math.Float64bits(myS.NaN) {
+		t.Fatalf("unmarshaled %+v, expected %+v", mySUnmarshaled, myS)
+	}
+}
+
+// Ensure times round trip to the nanosecond and are serialized the same way
+// regardless of their location
+func TestTime(t *testing.T) {
+	type s struct {
+		Time  time.Time   `serialize:"true"`
+		Times []time.Time `serialize:"true"`
+	}
+
+	now := time.Now()
+	zone := time.FixedZone("UTC+9", 9*60*60)
+	myS := s{
+		Time:  now,
+		Times: []time.Time{time.Unix(-1, 1), time.Unix(1591000000, 123456789).In(zone)},
+	}
+
+	codec := NewDefault()
+
+	timeBytes, err := codec.Marshal(myS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeBytes) != 8+4+2*8 {
+		t.Fatalf("marshaled %d bytes, expected %d", len(timeBytes), 8+4+2*8)
+	}
+
+	mySUnmarshaled := s{}
+	if err := codec.Unmarshal(timeBytes, &mySUnmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if !mySUnmarshaled.Time.Equal(now) || mySUnmarshaled.Time.Location() != time.UTC {
+		t.Fatalf("unmarshaled %s, expected %s in UTC", mySUnmarshaled.Time, now)
+	}
+	if len(mySUnmarshaled.Times) != len(myS.Times) {
+		t.Fatalf("unmarshaled %d times, expected %d", len(mySUnmarshaled.Times), len(myS.Times))
+	}
+	for i, timestamp := range mySUnmarshaled.Times {
+		if timestamp != myS.Times[i].UTC() {
+			t.Fatalf("unmarshaled %s, expected %s", timestamp, myS.Times[i].UTC())
+		}
+	}
+
+	// The same instant in a different location is serialized the same way
+	myS.Time = now.In(zone)
+	myS.Times[1] = myS.Times[1].UTC()
+	zoneBytes, err := codec.Marshal(myS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(zoneBytes, timeBytes) {
+		t.Fatal("the serialization of a time shouldn't depend on its location")
+	}
+}
+
+// Ensure times that can't be represented in nanoseconds don't marshal
+func TestTimeOutOfRange(t *testing.T) {
+	type s struct {
+		Time time.Time `serialize:"true"`
+	}
+
+	codec := NewDefault()
+	for _, timestamp := range []time.Time{minTime, time.Unix(math.MinInt64, 0), time.Unix(math.MaxInt64/2, 0)} {
+		if _, err := codec.Marshal(s{Time: timestamp}); err != errTimeOutOfRange {
+			t.Fatalf("marshaling %s should have failed with %s but returned %v", timestamp, errTimeOutOfRange, err)
+		}
+	}
+}
+
+// Ensure an unset time round trips
+func TestZeroTime(t *testing.T) {
+	type s struct {
+		Time  time.Time `serialize:"true"`
+		Other time.Time `serialize:"true"`
+	}
+
+	codec := NewDefault()
+	myS := s{Other: time.Unix(1, 0).UTC()}
+	timeBytes, err := codec.Marshal(myS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mySUnmarshaled := s{}
+	if err := codec.Unmarshal(timeBytes, &mySUnmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if !mySUnmarshaled.Time.IsZero() || mySUnmarshaled != myS {
+		t.Fatalf("unmarshaled %+v, expected %+v", mySUnmarshaled, myS)
+	}
+}
+
 // Ensure deserializing structs with too many bytes errors correctly
 func TestTooLargeUnmarshal(t *testing.T) {
 	type inner struct {
@@ -606,3 +731,77 @@ func TestUnmarshalInvalidInterface(t *testing.T) {
 		}
 	}
 }
+
+type fooSlice struct {
+	Foos []Foo `serialize:"true"`
+}
+
+func TestRegisterTypeWithID(t *testing.T) {
+	codec := NewDefault()
+	if err := codec.RegisterTypeWithID(7, &MyInnerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := codec.RegisterTypeWithID(3, &MyInnerStruct2{}); err != nil {
+		t.Fatal(err)
+	}
+
+	original := fooSlice{Foos: []Foo{
+		&MyInnerStruct{Str: "one"},
+		&MyInnerStruct2{Bool: true},
+		&MyInnerStruct{Str: "two"},
+	}}
+	bytes, err := codec.Marshal(&original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The slice length is followed by the type ID of the first element
+	if typeID := bytes[4:8]; typeID[3] != 7 {
+		t.Fatalf("first element should have had type ID 7 but had %x", typeID)
+	}
+
+	parsed := fooSlice{}
+	if err := codec.Unmarshal(bytes, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(original, parsed) {
+		t.Fatalf("unmarshaled %#v, expected %#v", parsed, original)
+	}
+}
+
+func TestRegisterTypeWithIDConflicts(t *testing.T) {
+	codec := NewDefault()
+	if err := codec.RegisterTypeWithID(0, &MyInnerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := codec.RegisterTypeWithID(0, &MyInnerStruct2{}); err == nil {
+		t.Fatal("should have failed to register two types with the same ID")
+	}
+	if err := codec.RegisterTypeWithID(1, &MyInnerStruct{}); err == nil {
+		t.Fatal("should have failed to register a type twice")
+	}
+	// With 2 types registered, the next sequential ID is 2
+	if err := codec.RegisterTypeWithID(2, &MyInnerStruct2{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := codec.RegisterType(&innerInterface{}); err == nil {
+		t.Fatal("should have failed to register a type with an ID that is taken")
+	}
+}
+
+func TestMarshalUnregisteredInterface(t *testing.T) {
+	codec := NewDefault()
+	if err := codec.RegisterTypeWithID(1, &MyInnerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+
+	original := fooSlice{Foos: []Foo{&MyInnerStruct{}, &MyInnerStruct2{}}}
+	if _, err := codec.Marshal(&original); !errors.Is(err, errMarshalUnregisteredType) {
+		t.Fatalf("Marshal should have failed with %s but returned %v", errMarshalUnregisteredType, err)
+	}
+
+	bytes := []byte{0, 0, 0, 1, 0, 0, 0, 2, 1}
+	if err := codec.Unmarshal(bytes, &original); !errors.Is(err, errUnmarshalUnregisteredType) {
+		t.Fatalf("Unmarshal should have failed with %s but returned %v", errUnmarshalUnregisteredType, err)
+	}
+}
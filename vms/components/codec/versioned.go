@@ -0,0 +1,50 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errMissingVersion     = errors.New("missing codec version")
+	errUnsupportedVersion = errors.New("unsupported codec version")
+)
+
+// versioned prepends a version byte to the bytes marshaled by a codec
+type versioned struct {
+	Codec
+	version byte
+}
+
+// NewVersioned returns a codec that marshals values with [c], prepending
+// [version] to the bytes, and only unmarshals bytes that start with [version]
+func NewVersioned(version byte, c Codec) Codec {
+	return versioned{
+		Codec:   c,
+		version: version,
+	}
+}
+
+// Marshal returns [version] followed by the byte representation of [value]
+func (v versioned) Marshal(value interface{}) ([]byte, error) {
+	valueBytes, err := v.Codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{v.version}, valueBytes...), nil
+}
+
+// Unmarshal [bytes] into [dest], after checking that [bytes] start with
+// [version]
+func (v versioned) Unmarshal(bytes []byte, dest interface{}) error {
+	if len(bytes) == 0 {
+		return errMissingVersion
+	}
+	if bytes[0] != v.version {
+		return fmt.Errorf("%w: %d", errUnsupportedVersion, bytes[0])
+	}
+	return v.Codec.Unmarshal(bytes[1:], dest)
+}
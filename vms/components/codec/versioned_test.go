@@ -0,0 +1,59 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersionedRoundTrip(t *testing.T) {
+	c := NewVersioned(3, NewDefault())
+	if err := c.RegisterType(&MyInnerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+
+	myFoo := Foo(&MyInnerStruct{Str: "yay"})
+	bytes, err := c.Marshal(&myFoo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bytes) == 0 || bytes[0] != 3 {
+		t.Fatalf("marshaled bytes %x should have started with version 3", bytes)
+	}
+
+	var parsed Foo
+	if err := c.Unmarshal(bytes, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if inner, ok := parsed.(*MyInnerStruct); !ok || inner.Str != "yay" {
+		t.Fatalf("unmarshaled %#v, expected %#v", parsed, myFoo)
+	}
+
+	// Re-marshaling keeps the version
+	reBytes, err := c.Marshal(&parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(reBytes) != string(bytes) {
+		t.Fatalf("re-marshaled to %x, expected %x", reBytes, bytes)
+	}
+}
+
+func TestVersionedBadVersion(t *testing.T) {
+	c := NewVersioned(0, NewDefault())
+	bytes, err := c.Marshal(uint32(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes[0] = 1
+	var parsed uint32
+	if err := c.Unmarshal(bytes, &parsed); !errors.Is(err, errUnsupportedVersion) {
+		t.Fatalf("Unmarshal should have failed with %s but returned %v", errUnsupportedVersion, err)
+	}
+	if err := c.Unmarshal(nil, &parsed); err != errMissingVersion {
+		t.Fatalf("Unmarshal should have failed with %s but returned %v", errMissingVersion, err)
+	}
+}
@@ -99,14 +99,15 @@ func (m *manager) RegisterVMFactory(vmID ids.ID, factory VMFactory) error {
 	m.vmFactories[key] = factory
 
 	// add the static API endpoints
-	m.addStaticAPIEndpoints(vmID)
-	return nil
+	return m.addStaticAPIEndpoints(vmID)
 }
 
 // VMs can expose a static API (one that does not depend on the state of a particular chain.)
 // This method adds to the node's API server the static API of the VM with ID [vmID].
 // This allows clients to call the VM's static API methods.
-func (m *manager) addStaticAPIEndpoints(vmID ids.ID) {
+// Returns an error if one of the VM's endpoints conflicts with an existing
+// route.
+func (m *manager) addStaticAPIEndpoints(vmID ids.ID) error {
 	vmFactory, err := m.GetVMFactory(vmID)
 	m.log.AssertNoError(err)
 	m.log.Debug("adding static API for VM with ID %s", vmID)
@@ -114,7 +115,7 @@ func (m *manager) addStaticAPIEndpoints(vmID ids.ID) {
 
 	staticVM, ok := vm.(common.StaticVM)
 	if !ok {
-		return
+		return nil
 	}
 
 	// all static endpoints go to the vm endpoint, defaulting to the vm id
@@ -124,6 +125,9 @@ func (m *manager) addStaticAPIEndpoints(vmID ids.ID) {
 	// register the static endpoints
 	for extension, service := range staticVM.CreateStaticHandlers() {
 		m.log.Verbo("adding static API endpoint: %s", defaultEndpoint+extension)
-		m.apiServer.AddRoute(service, lock, defaultEndpoint, extension, m.log)
+		if err := m.apiServer.AddRoute(service, lock, defaultEndpoint, extension, m.log); err != nil {
+			return fmt.Errorf("couldn't add static API endpoint %q of VM %s: %w", extension, vmID, err)
+		}
 	}
+	return nil
 }
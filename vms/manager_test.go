@@ -0,0 +1,89 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vms
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type staticVM struct {
+	handlers map[string]*common.HTTPHandler
+}
+
+func (vm *staticVM) CreateStaticHandlers() map[string]*common.HTTPHandler { return vm.handlers }
+
+type staticVMFactory struct{ vm *staticVM }
+
+func (f *staticVMFactory) New() interface{} { return f.vm }
+
+func newStaticVMFactory(extensions ...string) *staticVMFactory {
+	handlers := make(map[string]*common.HTTPHandler)
+	for _, extension := range extensions {
+		handlers[extension] = &common.HTTPHandler{Handler: http.NotFoundHandler()}
+	}
+	return &staticVMFactory{vm: &staticVM{handlers: handlers}}
+}
+
+func TestConflictingStaticHandlers(t *testing.T) {
+	server := &api.Server{}
+	server.Initialize(logging.NoLog{}, logging.NoFactory{}, 9650)
+	m := NewManager(server, logging.NoLog{})
+
+	vmID1 := ids.NewID([32]byte{1})
+	vmID2 := ids.NewID([32]byte{2})
+	if err := m.RegisterVMFactory(vmID1, newStaticVMFactory("/rpc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterVMFactory(vmID2, newStaticVMFactory("")); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.AddAliases("vm/"+vmID1.String(), "vm/shared"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both VMs now claim /ext/vm/shared/rpc
+	err := server.AddAliases("vm/"+vmID2.String(), "vm/shared/rpc")
+	if err == nil {
+		t.Fatal("Should have failed to alias a VM to a path claimed by another VM")
+	}
+	for _, expected := range []string{"/ext/vm/shared/rpc", vmID1.String(), vmID2.String()} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("Error %q should have contained %q", err, expected)
+		}
+	}
+}
+
+func TestConflictingStaticHandlersOnRegistration(t *testing.T) {
+	server := &api.Server{}
+	server.Initialize(logging.NoLog{}, logging.NoFactory{}, 9650)
+	m := NewManager(server, logging.NoLog{})
+
+	vmID1 := ids.NewID([32]byte{1})
+	vmID2 := ids.NewID([32]byte{2})
+	if err := m.RegisterVMFactory(vmID1, newStaticVMFactory("")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Route VM 1 to the path VM 2's static API will be registered at
+	if err := server.AddAliases("vm/"+vmID1.String(), "vm/"+vmID2.String()+"/rpc"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.RegisterVMFactory(vmID2, newStaticVMFactory("/rpc"))
+	if err == nil {
+		t.Fatal("Should have failed to register a VM with a path claimed by another VM")
+	}
+	for _, expected := range []string{"/ext/vm/" + vmID2.String() + "/rpc", vmID1.String(), vmID2.String()} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("Error %q should have contained %q", err, expected)
+		}
+	}
+}
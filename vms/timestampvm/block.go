@@ -5,50 +5,101 @@ package timestampvm
 
 import (
 	"errors"
-	"time"
 
 	"github.com/ava-labs/gecko/vms/components/core"
 )
 
 var (
-	errTimestampTooEarly = errors.New("block's timestamp is later than its parent's timestamp")
+	errTimestampTooEarly = errors.New("block's timestamp is earlier than its parent's timestamp")
 	errDatabase          = errors.New("error while retrieving data from database")
-	errTimestampTooLate  = errors.New("block's timestamp is more than 1 hour ahead of local time")
+	errTimestampTooLate  = errors.New("block's timestamp is too far ahead of local time")
+	errTimestampNotAfter = errors.New("block's timestamp isn't later than its parent's timestamp")
+	errBlockDataTooLong  = errors.New("block's data is longer than the max data length")
 )
 
+// strictTimestamps is the feature flag that, when enabled, requires a block's
+// timestamp to be strictly later than its parent's timestamp
+const strictTimestamps = "strict-timestamps"
+
 // Block is a block on the chain.
 // Each block contains:
-// 1) A piece of data (a string)
+// 1) A piece of data, no longer than the chain's max data length
 // 2) A timestamp
 type Block struct {
 	*core.Block `serialize:"true"`
-	Data        [dataLen]byte `serialize:"true"`
-	Timestamp   int64         `serialize:"true"`
+	Data        []byte `serialize:"true"`
+	Timestamp   int64  `serialize:"true"`
+
+	vm *VM
 }
 
 // Verify returns nil iff this block is valid.
 // To be valid, it must be that:
-// b.parent.Timestamp < b.Timestamp <= [local time] + 1 hour
+// b.parent.Timestamp <= b.Timestamp <= [local time] + [vm.MaxFutureDrift]
+// len(b.Data) <= the chain's max data length, which is set by the genesis
+// If the strict-timestamps feature is enabled, b.parent.Timestamp must be
+// strictly less than b.Timestamp.
+// If [vm.MaxReorgDepth] > 0, b's parent must be the last accepted block or one
+// of its [vm.MaxReorgDepth] closest ancestors.
 func (b *Block) Verify() error {
 	if accepted, err := b.Block.Verify(); err != nil || accepted {
 		return err
 	}
 
+	if len(b.Data) > b.vm.maxDataLen {
+		return errBlockDataTooLong
+	}
+
 	// Get [b]'s parent
 	parent, ok := b.Parent().(*Block)
 	if !ok {
 		return errDatabase
 	}
 
-	if b.Timestamp < time.Unix(parent.Timestamp, 0).Unix() {
+	if b.Timestamp < parent.Timestamp {
 		return errTimestampTooEarly
 	}
 
-	if b.Timestamp >= time.Now().Add(time.Hour).Unix() {
+	if b.VM.Ctx.Feature(strictTimestamps) && b.Timestamp == parent.Timestamp {
+		return errTimestampNotAfter
+	}
+
+	if b.Timestamp > b.vm.clock.Time().Add(b.vm.MaxFutureDrift).Unix() {
 		return errTimestampTooLate
 	}
 
+	if err := b.vm.checkReorgDepth(b); err != nil {
+		return err
+	}
+
 	// Persist the block
 	b.VM.SaveBlock(b.VM.DB, b)
 	return b.VM.DB.Commit()
 }
+
+// Accept sets this block's status to Accepted, adds it to the height and search
+// indices, commits the database and notifies any synchronous proposal waiting
+// for it. A synchronous proposal only returns once the block is readable.
+func (b *Block) Accept() {
+	b.Block.Accept()
+	if err := b.vm.indexHeight(b); err != nil {
+		b.vm.Ctx.Log.Error("error while indexing height of block %s: %v", b.ID(), err)
+	}
+	if err := b.vm.indexBlock(b); err != nil {
+		b.vm.Ctx.Log.Error("error while indexing block %s: %v", b.ID(), err)
+	}
+	if err := b.VM.DB.Commit(); err != nil {
+		b.vm.Ctx.Log.Error("error while committing block %s: %v", b.ID(), err)
+	}
+	b.vm.notifyAccepted(b)
+}
+
+// Reject sets this block's status to Rejected, prunes it from the database and
+// re-adds its data to the mempool or drops it. See [vm.RequeueRejected].
+func (b *Block) Reject() {
+	b.Block.Reject()
+	if err := b.vm.pruneBlock(b.ID()); err != nil {
+		b.vm.Ctx.Log.Error("error while pruning block %s: %v", b.ID(), err)
+	}
+	b.vm.releaseRejected(b)
+}
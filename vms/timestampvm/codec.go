@@ -0,0 +1,65 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+const (
+	// DefaultCodec is the name of the codec used if [VM.CodecName] isn't set
+	DefaultCodec = "default"
+
+	// codecVersion is the version byte that blocks serialized by the default
+	// codec start with
+	codecVersion = 0
+)
+
+var errNoCodecName = errors.New("codec name can't be empty")
+
+var (
+	codecsLock sync.RWMutex
+	// Maps the name of each registered codec to a function that creates it
+	codecs = map[string]func() codec.Codec{
+		DefaultCodec: newDefaultCodec,
+	}
+)
+
+// newDefaultCodec returns a codec that prepends [codecVersion] to blocks, so
+// that a future block format can be told apart from this one
+func newDefaultCodec() codec.Codec { return codec.NewVersioned(codecVersion, codec.NewDefault()) }
+
+// RegisterCodec makes the codec created by [newCodec] available to VMs whose
+// [CodecName] is [name]. The codec must be deterministic, as every node must
+// serialize a block to the same bytes.
+func RegisterCodec(name string, newCodec func() codec.Codec) error {
+	if name == "" {
+		return errNoCodecName
+	}
+
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+
+	if _, exists := codecs[name]; exists {
+		return fmt.Errorf("codec %q has already been registered", name)
+	}
+	codecs[name] = newCodec
+	return nil
+}
+
+// newCodec returns a new instance of the codec registered as [name]
+func newCodec(name string) (codec.Codec, error) {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	newCodec, exists := codecs[name]
+	if !exists {
+		return nil, fmt.Errorf("codec %q isn't registered", name)
+	}
+	return newCodec(), nil
+}
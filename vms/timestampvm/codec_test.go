@@ -0,0 +1,83 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+const testCodec = "test"
+
+func init() {
+	if err := RegisterCodec(testCodec, func() codec.Codec { return codec.New(1024, defaultMaxDataLen) }); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	if err := RegisterCodec(DefaultCodec, codec.NewDefault); err == nil {
+		t.Fatalf("Should have failed to register the default codec twice")
+	}
+	if err := RegisterCodec("", codec.NewDefault); err == nil {
+		t.Fatalf("Should have failed to register a codec without a name")
+	}
+}
+
+func TestCodecName(t *testing.T) {
+	vm := &VM{CodecName: testCodec}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	blocks := acceptBlocks(t, vm, "a", "b")
+
+	for _, blk := range blocks {
+		parsed, err := vm.ParseBlock(blk.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsedBlk := parsed.(*Block)
+		if !parsedBlk.ID().Equals(blk.ID()) || !bytes.Equal(parsedBlk.Data, blk.Data) || parsedBlk.Timestamp != blk.Timestamp {
+			t.Fatalf("block %s didn't round trip through the codec", blk.ID())
+		}
+	}
+}
+
+func TestDefaultCodecVersion(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	blk := acceptBlocks(t, vm, "a")[0]
+
+	blkBytes := blk.Bytes()
+	if len(blkBytes) == 0 || blkBytes[0] != codecVersion {
+		t.Fatalf("block bytes %x should have started with version %d", blkBytes, codecVersion)
+	}
+	parsed, err := vm.ParseBlock(blkBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed.Bytes(), blkBytes) {
+		t.Fatalf("block %s didn't round trip through the codec", blk.ID())
+	}
+
+	badVersion := append([]byte{codecVersion + 1}, blkBytes[1:]...)
+	if _, err := vm.ParseBlock(badVersion); err == nil {
+		t.Fatal("parsing a block with an unsupported codec version should have failed")
+	}
+}
+
+func TestCodecNameUnregistered(t *testing.T) {
+	vm := &VM{CodecName: "unregistered"}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err == nil {
+		t.Fatalf("Should have failed to initialize with an unregistered codec")
+	}
+}
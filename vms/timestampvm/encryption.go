@@ -0,0 +1,40 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/encdb"
+)
+
+var (
+	errWrongDBEncryptionKey = errors.New("database was encrypted with a different key")
+
+	// Key of a value that is encrypted when the database is first opened, so
+	// that a wrong key is detected before the VM reads or writes any state
+	encryptionCheckKey = []byte("encryptionCheck")
+)
+
+// openEncryptedDB returns [db] wrapped so that values are encrypted with a key
+// derived from [secret]. Returns an error if [db] was encrypted with a
+// different secret.
+func openEncryptedDB(secret []byte, db database.Database) (database.Database, error) {
+	encDB, err := encdb.New(secret, db)
+	if err != nil {
+		return nil, err
+	}
+	has, err := encDB.Has(encryptionCheckKey)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return encDB, encDB.Put(encryptionCheckKey, encryptionCheckKey)
+	}
+	if _, err := encDB.Get(encryptionCheckKey); err != nil {
+		return nil, errWrongDBEncryptionKey
+	}
+	return encDB, nil
+}
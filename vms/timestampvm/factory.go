@@ -3,7 +3,11 @@
 
 package timestampvm
 
-import "github.com/ava-labs/gecko/ids"
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
 
 // ID is a unique identifier for this VM
 var (
@@ -11,7 +15,58 @@ var (
 )
 
 // Factory ...
-type Factory struct{}
+type Factory struct {
+	// MinPeersForWrites is passed to the VMs this factory creates
+	MinPeersForWrites int
+	// DBEncryptionKey is passed to the VMs this factory creates
+	DBEncryptionKey []byte
+	// MaxMempoolBytes is passed to the VMs this factory creates
+	MaxMempoolBytes int
+	// SnapshotInterval and SnapshotDir are passed to the VMs this factory
+	// creates
+	SnapshotInterval time.Duration
+	SnapshotDir      string
+	// ProposeRate and ProposeBurst are passed to the VMs this factory creates
+	ProposeRate  float64
+	ProposeBurst float64
+	// MaxReorgDepth is passed to the VMs this factory creates
+	MaxReorgDepth uint64
+	// CodecName is passed to the VMs this factory creates
+	CodecName string
+	// MaxMempoolSize and RequeueRejected are passed to the VMs this factory
+	// creates
+	MaxMempoolSize  int
+	RequeueRejected bool
+	// CompactionThreshold is passed to the VMs this factory creates
+	CompactionThreshold int
+	// EnableSearch is passed to the VMs this factory creates
+	EnableSearch bool
+	// ProposeTimeout is passed to the VMs this factory creates
+	ProposeTimeout time.Duration
+	// MaxFutureDrift is passed to the VMs this factory creates
+	MaxFutureDrift time.Duration
+	// MaxPageSize is passed to the VMs this factory creates
+	MaxPageSize int
+}
 
 // New ...
-func (f *Factory) New() interface{} { return &VM{} }
+func (f *Factory) New() interface{} {
+	return &VM{
+		MinPeersForWrites:   f.MinPeersForWrites,
+		DBEncryptionKey:     f.DBEncryptionKey,
+		MaxMempoolBytes:     f.MaxMempoolBytes,
+		SnapshotInterval:    f.SnapshotInterval,
+		SnapshotDir:         f.SnapshotDir,
+		ProposeRate:         f.ProposeRate,
+		ProposeBurst:        f.ProposeBurst,
+		MaxReorgDepth:       f.MaxReorgDepth,
+		CodecName:           f.CodecName,
+		MaxMempoolSize:      f.MaxMempoolSize,
+		RequeueRejected:     f.RequeueRejected,
+		CompactionThreshold: f.CompactionThreshold,
+		EnableSearch:        f.EnableSearch,
+		ProposeTimeout:      f.ProposeTimeout,
+		MaxFutureDrift:      f.MaxFutureDrift,
+		MaxPageSize:         f.MaxPageSize,
+	}
+}
@@ -0,0 +1,40 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFactoryNew(t *testing.T) {
+	f := &Factory{
+		MaxReorgDepth:       1,
+		CodecName:           DefaultCodec,
+		MaxMempoolSize:      2,
+		RequeueRejected:     true,
+		CompactionThreshold: 3,
+		EnableSearch:        true,
+		ProposeTimeout:      time.Second,
+		MaxFutureDrift:      time.Minute,
+		MaxPageSize:         4,
+		ProposeRate:         5,
+		ProposeBurst:        6,
+	}
+	vm := f.New().(*VM)
+	switch {
+	case vm.MaxReorgDepth != f.MaxReorgDepth,
+		vm.CodecName != f.CodecName,
+		vm.MaxMempoolSize != f.MaxMempoolSize,
+		vm.RequeueRejected != f.RequeueRejected,
+		vm.CompactionThreshold != f.CompactionThreshold,
+		vm.EnableSearch != f.EnableSearch,
+		vm.ProposeTimeout != f.ProposeTimeout,
+		vm.MaxFutureDrift != f.MaxFutureDrift,
+		vm.MaxPageSize != f.MaxPageSize,
+		vm.ProposeRate != f.ProposeRate,
+		vm.ProposeBurst != f.ProposeBurst:
+		t.Fatal("VM wasn't configured by the factory")
+	}
+}
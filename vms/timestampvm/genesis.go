@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"math"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// The genesis bytes of a chain are either:
+// * The genesis block's data, at most [defaultMaxDataLen] bytes. Blocks hold at
+//   most [defaultMaxDataLen] bytes of data.
+// * The genesis block's data, zero-padded to [defaultMaxDataLen] bytes,
+//   followed by the maximum length of a block's data as a 4 byte int. It must
+//   be at least [defaultMaxDataLen].
+// The maximum data length is part of the genesis so that every node agrees on
+// which blocks are valid.
+
+// Genesis returns the genesis bytes of a chain whose genesis block has data
+// [data] and whose blocks hold at most [maxDataLen] bytes of data
+func Genesis(data []byte, maxDataLen int) ([]byte, error) {
+	if len(data) > defaultMaxDataLen || maxDataLen < defaultMaxDataLen || int64(maxDataLen) > math.MaxInt32 {
+		return nil, errBadGenesisBytes
+	}
+	if maxDataLen == defaultMaxDataLen {
+		return data, nil
+	}
+	p := wrappers.Packer{MaxSize: defaultMaxDataLen + wrappers.IntLen}
+	p.PackFixedBytes(padGenesisData(data))
+	p.PackInt(uint32(maxDataLen))
+	return p.Bytes, p.Err
+}
+
+// parseGenesis returns the genesis block's data and the maximum length of a
+// block's data from [genesisBytes]
+func parseGenesis(genesisBytes []byte) ([]byte, int, error) {
+	if len(genesisBytes) <= defaultMaxDataLen {
+		return genesisBytes, defaultMaxDataLen, nil
+	}
+	if len(genesisBytes) != defaultMaxDataLen+wrappers.IntLen {
+		return nil, 0, errBadGenesisBytes
+	}
+	p := wrappers.Packer{Bytes: genesisBytes, Offset: defaultMaxDataLen}
+	maxDataLen := p.UnpackInt()
+	if p.Errored() || maxDataLen < defaultMaxDataLen || maxDataLen > math.MaxInt32 {
+		return nil, 0, errBadGenesisBytes
+	}
+	return genesisBytes[:defaultMaxDataLen], int(maxDataLen), nil
+}
+
+// padGenesisData zero-pads [data] to [defaultMaxDataLen] bytes. Blocks used to
+// hold exactly that many bytes of data, so padding keeps the genesis block
+// the same as it was.
+func padGenesisData(data []byte) []byte {
+	if len(data) >= defaultMaxDataLen {
+		return data
+	}
+	padded := make([]byte, defaultMaxDataLen)
+	copy(padded, data)
+	return padded
+}
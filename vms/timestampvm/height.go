@@ -0,0 +1,261 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var errUnknownHeight = errors.New("unknown height: no block has been accepted at this height")
+
+// lastHeightKey maps to the height of the last accepted block. It can't collide
+// with a height, which are keyed by [wrappers.LongLen] bytes.
+var lastHeightKey = []byte("last")
+
+// heightIndex maps the height of each accepted block to its ID. The genesis
+// block has height 0.
+type heightIndex struct{ db database.Database }
+
+func (h *heightIndex) Initialize(db database.Database) {
+	h.db = prefixdb.New([]byte("height"), db)
+}
+
+func heightKey(height uint64) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.LongLen)}
+	p.PackLong(height)
+	return p.Bytes
+}
+
+// Put maps [height] to [blkID], whose timestamp is [timestamp], and marks
+// [height] as the last accepted height
+func (h *heightIndex) Put(height uint64, blkID ids.ID, timestamp int64) error {
+	p := wrappers.Packer{Bytes: make([]byte, hashing.HashLen+wrappers.LongLen)}
+	p.PackFixedBytes(blkID.Bytes())
+	p.PackLong(uint64(timestamp))
+	if err := h.db.Put(heightKey(height), p.Bytes); err != nil {
+		return err
+	}
+	return h.db.Put(lastHeightKey, heightKey(height))
+}
+
+// Get returns the ID of the accepted block at [height]
+func (h *heightIndex) Get(height uint64) (ids.ID, error) {
+	p, err := h.get(height)
+	if err != nil {
+		return ids.ID{}, err
+	}
+	return ids.ToID(p.UnpackFixedBytes(hashing.HashLen))
+}
+
+// Timestamp returns the timestamp of the accepted block at [height] without
+// fetching the block
+func (h *heightIndex) Timestamp(height uint64) (int64, error) {
+	p, err := h.get(height)
+	if err != nil {
+		return 0, err
+	}
+	p.Offset = hashing.HashLen
+	timestamp := int64(p.UnpackLong())
+	return timestamp, p.Err
+}
+
+func (h *heightIndex) get(height uint64) (*wrappers.Packer, error) {
+	value, err := h.db.Get(heightKey(height))
+	if err != nil {
+		return nil, err
+	}
+	return &wrappers.Packer{Bytes: value}, nil
+}
+
+// LastHeight returns the height of the last accepted block. Returns false if
+// no blocks have been indexed.
+func (h *heightIndex) LastHeight() (uint64, bool, error) {
+	heightBytes, err := h.db.Get(lastHeightKey)
+	if err == database.ErrNotFound {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	p := wrappers.Packer{Bytes: heightBytes}
+	height := p.UnpackLong()
+	return height, true, p.Err
+}
+
+// indexHeight adds [blk], which must have just been accepted, to the height
+// index
+func (vm *VM) indexHeight(blk *Block) error {
+	height := uint64(0)
+	if !blk.ParentID().Equals(ids.Empty) {
+		height = vm.lastHeight + 1
+	}
+	if err := vm.heights.Put(height, blk.ID(), blk.Timestamp); err != nil {
+		return err
+	}
+	vm.lastHeight = height
+	return nil
+}
+
+// loadHeightIndex loads the height of the last accepted block. If the height
+// index is missing, it is built by walking back from the last accepted block
+// to the genesis block.
+func (vm *VM) loadHeightIndex() error {
+	lastHeight, exists, err := vm.heights.LastHeight()
+	if err != nil {
+		return err
+	}
+	if exists {
+		vm.lastHeight = lastHeight
+		return nil
+	}
+
+	blocks := []*Block(nil)
+	for blkID := vm.LastAccepted(); !blkID.Equals(ids.Empty); {
+		blk, err := vm.getBlock(blkID)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, blk)
+		blkID = blk.ParentID()
+	}
+	for i := range blocks {
+		height := uint64(i)
+		blk := blocks[len(blocks)-1-i]
+		if err := vm.heights.Put(height, blk.ID(), blk.Timestamp); err != nil {
+			return err
+		}
+		vm.lastHeight = height
+	}
+	return vm.DB.Commit()
+}
+
+// getBlockByHeight returns the accepted block at [height]
+func (vm *VM) getBlockByHeight(height uint64) (*Block, error) {
+	if height > vm.lastHeight {
+		return nil, errUnknownHeight
+	}
+	blkID, err := vm.heights.Get(height)
+	if err != nil {
+		return nil, err
+	}
+	return vm.getBlock(blkID)
+}
+
+// acceptedHeight returns the height of [blk], which must be accepted. Since
+// the timestamps of accepted blocks never decrease with height, the blocks with
+// [blk]'s timestamp are found by binary search over the indexed timestamps.
+func (vm *VM) acceptedHeight(blk *Block) (uint64, error) {
+	var err error
+	numBlocks := int(vm.lastHeight + 1)
+	height := sort.Search(numBlocks, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		timestamp, getErr := vm.heights.Timestamp(uint64(i))
+		if getErr != nil {
+			err = getErr
+			return true
+		}
+		return timestamp >= blk.Timestamp
+	})
+	if err != nil {
+		return 0, err
+	}
+	for ; height < numBlocks; height++ {
+		blkID, err := vm.heights.Get(uint64(height))
+		if err != nil {
+			return 0, err
+		}
+		if blkID.Equals(blk.ID()) {
+			return uint64(height), nil
+		}
+	}
+	return 0, errUnknownHeight
+}
+
+// getBlocksByTimeRange returns up to [limit] accepted blocks whose timestamps
+// are in [start, end] and whose heights are at least [minHeight], ordered by
+// height. If more blocks are in the range, the height of the next one is also
+// returned. Otherwise, 0 is returned. If [start] > [end], no blocks are
+// returned. Since the timestamps of accepted blocks never decrease with height,
+// the first block in the range is found by binary search over the indexed
+// timestamps. Returns [ctx]'s error if it is done before the blocks are fetched.
+func (vm *VM) getBlocksByTimeRange(ctx context.Context, start, end int64, minHeight uint64, limit int) ([]*Block, uint64, error) {
+	if start > end || minHeight > vm.lastHeight {
+		return nil, 0, nil
+	}
+
+	var err error
+	numBlocks := int(vm.lastHeight + 1)
+	first := sort.Search(numBlocks, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		timestamp, getErr := vm.heights.Timestamp(uint64(i))
+		if getErr != nil {
+			err = getErr
+			return true
+		}
+		return timestamp >= start
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if first < int(minHeight) {
+		first = int(minHeight)
+	}
+
+	blocks := []*Block(nil)
+	for height := first; height < numBlocks; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		blk, err := vm.getBlockByHeight(uint64(height))
+		if err != nil {
+			return nil, 0, err
+		}
+		if blk.Timestamp > end {
+			break
+		}
+		if len(blocks) == limit {
+			return blocks, uint64(height), nil
+		}
+		blocks = append(blocks, blk)
+	}
+	return blocks, 0, nil
+}
+
+// heightTimestamp is the timestamp of the accepted block at a height
+type heightTimestamp struct {
+	Height    uint64
+	Timestamp int64
+}
+
+// getTimestamps returns the timestamps of up to [count] accepted blocks
+// starting at [startHeight], read from the height index
+func (vm *VM) getTimestamps(startHeight, count uint64) ([]heightTimestamp, error) {
+	if startHeight > vm.lastHeight {
+		return nil, nil
+	}
+	if remaining := vm.lastHeight - startHeight + 1; count > remaining {
+		count = remaining
+	}
+	timestamps := make([]heightTimestamp, count)
+	for i := range timestamps {
+		height := startHeight + uint64(i)
+		timestamp, err := vm.heights.Timestamp(height)
+		if err != nil {
+			return nil, err
+		}
+		timestamps[i] = heightTimestamp{Height: height, Timestamp: timestamp}
+	}
+	return timestamps, nil
+}
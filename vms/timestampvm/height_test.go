@@ -0,0 +1,255 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+func TestGetBlocksByTimeRange(t *testing.T) {
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Timestamps 1 through 5
+	blocks := acceptBlocks(t, vm, "a", "b", "c", "d", "e")
+	genesisBlock, err := vm.getBlockByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := Service{vm}
+	tests := []struct {
+		start, end json.Uint64
+		expected   []*Block
+	}{
+		{start: 2, end: 4, expected: blocks[1:4]},
+		{start: 0, end: 0, expected: []*Block{genesisBlock}},
+		{start: 3, end: 3, expected: blocks[2:3]},
+		{start: 4, end: 100, expected: blocks[3:]},
+		{start: 0, end: json.Uint64(^uint64(0)), expected: append([]*Block{genesisBlock}, blocks...)},
+		{start: 6, end: 10, expected: nil},
+		{start: 4, end: 2, expected: nil},
+		{start: json.Uint64(^uint64(0)), end: json.Uint64(^uint64(0)), expected: nil},
+	}
+	for _, test := range tests {
+		reply := GetBlocksByTimeRangeReply{}
+		if err := service.GetBlocksByTimeRange(nil, &GetBlocksByTimeRangeArgs{Start: test.start, End: test.end}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Blocks) != len(test.expected) {
+			t.Fatalf("range [%d, %d] returned %d blocks, expected %d", test.start, test.end, len(reply.Blocks), len(test.expected))
+		}
+		for i, blk := range test.expected {
+			if reply.Blocks[i].ID != blk.ID().String() {
+				t.Fatalf("range [%d, %d] returned block %s at %d, expected %s", test.start, test.end, reply.Blocks[i].ID, i, blk.ID())
+			}
+		}
+	}
+}
+
+func TestGetBlocksByTimeRangeCancelled(t *testing.T) {
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	acceptBlocks(t, vm, "a", "b", "c")
+
+	// The client gave up before the blocks were fetched
+	reqCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request := httptest.NewRequest("POST", "/", nil).WithContext(reqCtx)
+
+	service := Service{vm}
+	reply := GetBlocksByTimeRangeReply{}
+	args := &GetBlocksByTimeRangeArgs{Start: 0, End: json.Uint64(^uint64(0))}
+	if err := service.GetBlocksByTimeRange(request, args, &reply); err != context.Canceled {
+		t.Fatalf("Should have stopped with %s but returned %v", context.Canceled, err)
+	}
+	if len(reply.Blocks) != 0 {
+		t.Fatalf("Shouldn't have returned blocks from a cancelled request")
+	}
+}
+
+func TestLoadHeightIndex(t *testing.T) {
+	db := memdb.New()
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	vm := &VM{}
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	blocks := acceptBlocks(t, vm, "a", "b")
+	if err := vm.DB.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove the index to simulate a database from before it existed
+	if err := vm.heights.db.Delete(lastHeightKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.DB.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		vm = &VM{}
+		if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+			t.Fatal(err)
+		}
+		if vm.lastHeight != 2 {
+			t.Fatalf("last height should be 2 but is %d", vm.lastHeight)
+		}
+		for height, blk := range blocks {
+			if blkAtHeight, err := vm.getBlockByHeight(uint64(height + 1)); err != nil {
+				t.Fatal(err)
+			} else if !blkAtHeight.ID().Equals(blk.ID()) {
+				t.Fatalf("block at height %d should be %s but is %s", height+1, blk.ID(), blkAtHeight.ID())
+			}
+		}
+	}
+}
+
+func TestGetTimestamps(t *testing.T) {
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	acceptBlocks(t, vm, "a", "b", "c", "d", "e")
+
+	service := Service{vm}
+	tests := []struct {
+		startHeight, count json.Uint64
+		expectedHeights    []uint64
+	}{
+		{startHeight: 0, count: 6, expectedHeights: []uint64{0, 1, 2, 3, 4, 5}},
+		{startHeight: 2, count: 2, expectedHeights: []uint64{2, 3}},
+		{startHeight: 4, count: 10, expectedHeights: []uint64{4, 5}},
+		{startHeight: 3, count: 0, expectedHeights: nil},
+		{startHeight: 6, count: 1, expectedHeights: nil},
+	}
+	for _, test := range tests {
+		reply := GetTimestampsReply{}
+		if err := service.GetTimestamps(nil, &GetTimestampsArgs{StartHeight: test.startHeight, Count: test.count}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Timestamps) != len(test.expectedHeights) {
+			t.Fatalf("start %d, count %d returned %d timestamps, expected %d", test.startHeight, test.count, len(reply.Timestamps), len(test.expectedHeights))
+		}
+		for i, height := range test.expectedHeights {
+			blk, err := vm.getBlockByHeight(height)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if timestamp := reply.Timestamps[i]; uint64(timestamp.Height) != height || int64(timestamp.Timestamp) != blk.Timestamp {
+				t.Fatalf("returned (%d, %d), expected (%d, %d)", timestamp.Height, timestamp.Timestamp, height, blk.Timestamp)
+			}
+		}
+	}
+
+	// Counts above the max page size are truncated
+	vm.MaxPageSize = 4
+	reply := GetTimestampsReply{}
+	if err := service.GetTimestamps(nil, &GetTimestampsArgs{StartHeight: 1, Count: 100}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Timestamps) != 4 || reply.Timestamps[3].Height != 4 {
+		t.Fatalf("expected the timestamps at heights 1 to 4 but got %v", reply.Timestamps)
+	}
+	if reply.NextHeight != 5 {
+		t.Fatalf("next height should have been 5 but was %d", reply.NextHeight)
+	}
+
+	// The last page has no next height
+	reply = GetTimestampsReply{}
+	if err := service.GetTimestamps(nil, &GetTimestampsArgs{StartHeight: 5, Count: 100}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Timestamps) != 1 || reply.NextHeight != 0 {
+		t.Fatalf("expected the last timestamp and no next height but got %v, %d", reply.Timestamps, reply.NextHeight)
+	}
+}
+
+func TestGetBlocksByTimeRangeMaxPageSize(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	vm.MaxPageSize = 2
+	blocks := acceptBlocks(t, vm, "a", "b", "c", "d", "e")
+
+	// Blocks at timestamps [2, 5] are at heights [2, 5]
+	service := Service{vm}
+	pages := [][]*Block{blocks[1:3], blocks[3:5]}
+	args := &GetBlocksByTimeRangeArgs{Start: 2, End: 5, Limit: 100}
+	for i, page := range pages {
+		reply := GetBlocksByTimeRangeReply{}
+		if err := service.GetBlocksByTimeRange(nil, args, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Blocks) != len(page) {
+			t.Fatalf("page %d should have had %d blocks but had %d", i, len(page), len(reply.Blocks))
+		}
+		for j, blk := range page {
+			if reply.Blocks[j].ID != blk.ID().String() {
+				t.Fatalf("page %d should have had block %s but had %s", i, blk.ID(), reply.Blocks[j].ID)
+			}
+		}
+		if i == len(pages)-1 {
+			if reply.NextHeight != 0 {
+				t.Fatalf("the last page shouldn't have a next height but had %d", reply.NextHeight)
+			}
+		} else if reply.NextHeight != 4 {
+			t.Fatalf("next height should have been 4 but was %d", reply.NextHeight)
+		}
+		args.StartHeight = reply.NextHeight
+	}
+
+	// A limit below the max page size is honored
+	reply := GetBlocksByTimeRangeReply{}
+	if err := service.GetBlocksByTimeRange(nil, &GetBlocksByTimeRangeArgs{Start: 2, End: 5, Limit: 1}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Blocks) != 1 || reply.NextHeight != 3 {
+		t.Fatalf("expected 1 block and a next height of 3 but got %d blocks and %d", len(reply.Blocks), reply.NextHeight)
+	}
+}
+
+func TestGetBlockByHeight(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	blocks := acceptBlocks(t, vm, "first", "second", "third")
+
+	service := Service{vm}
+	for i, blk := range blocks {
+		reply := GetBlockByHeightReply{}
+		if err := service.GetBlockByHeight(nil, &GetBlockByHeightArgs{Height: json.Uint64(i + 1)}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if expected := newAPIBlock(blk); reply.APIBlock != expected {
+			t.Fatalf("GetBlockByHeight(%d) returned %+v, expected %+v", i+1, reply.APIBlock, expected)
+		}
+	}
+
+	reply := GetBlockByHeightReply{}
+	if err := service.GetBlockByHeight(nil, &GetBlockByHeightArgs{Height: 0}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.ID != blocks[0].ParentID().String() {
+		t.Fatalf("GetBlockByHeight(0) should have returned the genesis block but returned %s", reply.ID)
+	}
+
+	if err := service.GetBlockByHeight(nil, &GetBlockByHeightArgs{Height: 4}, &reply); err != errUnknownHeight {
+		t.Fatalf("GetBlockByHeight should have failed with %s but returned %v", errUnknownHeight, err)
+	}
+}
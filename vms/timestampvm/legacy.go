@@ -0,0 +1,100 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/state"
+)
+
+// legacyBlock is a block as it was encoded before blocks held variable-length
+// data and started with a codec version
+type legacyBlock struct {
+	*core.Block `serialize:"true"`
+	Data        [defaultMaxDataLen]byte `serialize:"true"`
+	Timestamp   int64                   `serialize:"true"`
+}
+
+// parseLegacyBlock parses [bytes], which must be a block encoded by [c] as it
+// was before blocks started with a codec version. The block keeps its ID.
+func (vm *VM) parseLegacyBlock(c codec.Codec, bytes []byte) (snowman.Block, error) {
+	legacy := legacyBlock{}
+	if err := c.Unmarshal(bytes, &legacy); err != nil {
+		return nil, err
+	}
+	block := &Block{
+		Block:     legacy.Block,
+		Data:      legacy.Data[:],
+		Timestamp: legacy.Timestamp,
+		vm:        vm,
+	}
+	block.Initialize(bytes, &vm.SnowmanVM)
+	return block, nil
+}
+
+// upgradeBlockEncoding re-encodes the accepted blocks of a database created
+// before blocks started with a codec version. As a block's ID is the hash of
+// its bytes, every accepted block gets a new ID, and the height index is
+// rewritten and the search index cleared to match. The genesis data was padded
+// the same way it is now, so the upgraded chain is the one a new node creates,
+// but every node must be upgraded together.
+// Blocks that weren't accepted are left under their old IDs, which nothing
+// refers to after the upgrade.
+func upgradeBlockEncoding(vm *VM) error {
+	legacyCodec := codec.NewDefault()
+	legacyState, err := core.NewSnowmanState(func(bytes []byte) (snowman.Block, error) {
+		return vm.parseLegacyBlock(legacyCodec, bytes)
+	})
+	if err != nil {
+		return err
+	}
+
+	blocks := []*Block(nil)
+	for blkID := vm.LastAccepted(); !blkID.Equals(ids.Empty); {
+		blkIntf, err := legacyState.GetBlock(vm.DB, blkID)
+		if err != nil {
+			return err
+		}
+		blk, ok := blkIntf.(*Block)
+		if !ok {
+			return errDatabase
+		}
+		blocks = append(blocks, blk)
+		blkID = blk.ParentID()
+	}
+
+	parentID := ids.Empty
+	for i := range blocks {
+		height := uint64(i)
+		legacy := blocks[len(blocks)-1-i]
+		blk, err := vm.NewBlock(parentID, legacy.Data, time.Unix(legacy.Timestamp, 0))
+		if err != nil {
+			return err
+		}
+		if err := vm.SaveBlock(vm.DB, blk); err != nil {
+			return err
+		}
+		blk.Block.Accept()
+		if err := vm.heights.Put(height, blk.ID(), blk.Timestamp); err != nil {
+			return err
+		}
+		if err := vm.State.Put(vm.DB, state.BlockTypeID, legacy.ID(), nil); err != nil {
+			return err
+		}
+		if err := vm.State.Put(vm.DB, state.StatusTypeID, legacy.ID(), nil); err != nil {
+			return err
+		}
+		parentID = blk.ID()
+	}
+	vm.SetPreference(parentID)
+
+	search := searchIndex{}
+	search.Initialize(vm.DB)
+	return search.Clear()
+}
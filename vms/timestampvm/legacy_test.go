@@ -0,0 +1,176 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/state"
+)
+
+// newLegacyDatabase returns a database as it was left by a version of this VM
+// from before blocks started with a codec version, with a genesis block
+// containing [genesisData] followed by accepted blocks containing [data]. The
+// i'th block has timestamp i. Returns the IDs of the blocks.
+func newLegacyDatabase(t *testing.T, genesisData []byte, data ...string) (database.Database, []ids.ID) {
+	t.Helper()
+
+	db := memdb.New()
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	svm := core.SnowmanVM{}
+	unused := func([]byte) (snowman.Block, error) { return nil, errors.New("unused") }
+	if err := svm.Initialize(ctx, db, unused, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	c := codec.NewDefault()
+	blkIDs := []ids.ID(nil)
+	parentID := ids.Empty
+	for i, d := range append([][]byte{genesisData}, toBytes(data)...) {
+		blk := &legacyBlock{Block: core.NewBlock(parentID), Timestamp: int64(i)}
+		copy(blk.Data[:], d)
+		blkBytes, err := c.Marshal(blk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blk.Initialize(blkBytes, &svm)
+		if err := svm.State.Put(svm.DB, state.BlockTypeID, blk.ID(), blk); err != nil {
+			t.Fatal(err)
+		}
+		blk.Accept()
+		blkIDs = append(blkIDs, blk.ID())
+		parentID = blk.ID()
+	}
+	svm.SetDBInitialized()
+	if err := svm.DB.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return db, blkIDs
+}
+
+func toBytes(strs []string) [][]byte {
+	b := make([][]byte, len(strs))
+	for i, s := range strs {
+		b[i] = []byte(s)
+	}
+	return b
+}
+
+func TestParseLegacyBlock(t *testing.T) {
+	vm, _ := NewTestVM(t)
+
+	// A block as the old codec marshaled it: its parent's ID, its data as a
+	// 32 byte array and its timestamp
+	parentID := ids.NewID([32]byte{1, 2, 3})
+	blkBytes := append([]byte(nil), parentID.Bytes()...)
+	blkBytes = append(blkBytes, padGenesisData([]byte("data"))...)
+	blkBytes = append(blkBytes, 0, 0, 0, 0, 0, 0, 0, 5)
+
+	blkIntf, err := vm.parseLegacyBlock(codec.NewDefault(), blkBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blk := blkIntf.(*Block)
+	if err := assertBlock(blk, parentID, padGenesisData([]byte("data")), false); err != nil {
+		t.Fatal(err)
+	}
+	if blk.Timestamp != 5 {
+		t.Fatalf("block should have had timestamp 5 but had %d", blk.Timestamp)
+	}
+	if !bytes.Equal(blk.Bytes(), blkBytes) {
+		t.Fatal("a legacy block should keep its bytes, and so its ID")
+	}
+
+	// The current codec doesn't parse legacy blocks
+	if _, err := vm.ParseBlock(blkBytes); err == nil {
+		t.Fatal("a legacy block shouldn't have parsed as a current block")
+	}
+}
+
+func TestUpgradeBlockEncoding(t *testing.T) {
+	db, legacyIDs := newLegacyDatabase(t, []byte("genesis"), "a", "b")
+
+	vm := &VM{EnableSearch: true}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The upgraded chain is the one a new node creates from the same genesis
+	fresh := &VM{}
+	if err := fresh.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	defer fresh.Shutdown()
+	for i, d := range []string{"a", "b"} {
+		blk, err := fresh.NewBlock(fresh.LastAccepted(), padGenesisData([]byte(d)), time.Unix(int64(i+1), 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := blk.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		blk.Accept()
+	}
+	if !vm.LastAccepted().Equals(fresh.LastAccepted()) {
+		t.Fatalf("upgraded chain should have ended with %s but ended with %s", fresh.LastAccepted(), vm.LastAccepted())
+	}
+	if !vm.Preferred().Equals(vm.LastAccepted()) {
+		t.Fatalf("upgraded VM should have preferred %s but preferred %s", vm.LastAccepted(), vm.Preferred())
+	}
+
+	for height, legacyID := range legacyIDs {
+		blk, err := vm.getBlockByHeight(uint64(height))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blk.Timestamp != int64(height) {
+			t.Fatalf("block at height %d should have had timestamp %d but had %d", height, height, blk.Timestamp)
+		}
+		if _, err := vm.GetBlock(legacyID); err == nil {
+			t.Fatalf("legacy block %s should have been removed", legacyID)
+		}
+	}
+
+	// The search index is rebuilt with the new IDs
+	found, err := vm.searchBlocks([]byte("b"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || !found[0].ID().Equals(vm.LastAccepted()) {
+		t.Fatalf("expected to find the last accepted block but found %d blocks", len(found))
+	}
+
+	// The upgraded chain can be extended, and isn't upgraded again
+	next, err := vm.NewBlock(vm.LastAccepted(), []byte("c"), time.Unix(3, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := next.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	next.Accept()
+	lastAccepted := vm.LastAccepted()
+	vm = &VM{}
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+	if !vm.LastAccepted().Equals(lastAccepted) {
+		t.Fatalf("last accepted block should have been %s but was %s", lastAccepted, vm.LastAccepted())
+	}
+}
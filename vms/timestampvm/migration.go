@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// schemaVersionKey maps to the version of the database's schema
+var schemaVersionKey = []byte("version")
+
+// builtinMigrations upgrade databases created by older versions of this VM.
+// They run before [VM.Migrations].
+var builtinMigrations = []Migration{
+	upgradeBlockEncoding,
+}
+
+// Migration upgrades the database of [vm] from one schema version to the next.
+// It's run after the database is opened and before any indices are loaded.
+type Migration func(vm *VM) error
+
+// schemaDB returns the database that the schema version is recorded in
+func (vm *VM) schemaDB() database.Database { return prefixdb.New([]byte("schema"), vm.DB) }
+
+// schemaVersion returns the recorded schema version. A database with no
+// recorded version has version 0.
+func (vm *VM) schemaVersion() (uint32, error) {
+	versionBytes, err := vm.schemaDB().Get(schemaVersionKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	p := wrappers.Packer{Bytes: versionBytes}
+	version := p.UnpackInt()
+	return version, p.Err
+}
+
+// putSchemaVersion records [version] as the schema version
+func (vm *VM) putSchemaVersion(version uint32) error {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.IntLen)}
+	p.PackInt(version)
+	return vm.schemaDB().Put(schemaVersionKey, p.Bytes)
+}
+
+// migrations returns every migration, in order. The current schema version is
+// the number of migrations.
+func (vm *VM) migrations() []Migration {
+	migrations := make([]Migration, 0, len(builtinMigrations)+len(vm.Migrations))
+	migrations = append(migrations, builtinMigrations...)
+	return append(migrations, vm.Migrations...)
+}
+
+// migrate runs the migrations that bring the database from its recorded schema
+// version to the current version. The version is recorded and committed after
+// each migration, so a failed migration is retried the next time the VM is
+// initialized.
+func (vm *VM) migrate() error {
+	version, err := vm.schemaVersion()
+	if err != nil {
+		return err
+	}
+	migrations := vm.migrations()
+	if version > uint32(len(migrations)) {
+		return fmt.Errorf("database schema version %d is newer than the current version %d", version, len(migrations))
+	}
+
+	for ; version < uint32(len(migrations)); version++ {
+		vm.Ctx.Log.Info("migrating database from schema version %d to %d", version, version+1)
+		if err := migrations[version](vm); err != nil {
+			vm.DB.Abort()
+			return err
+		}
+		if err := vm.putSchemaVersion(version + 1); err != nil {
+			return err
+		}
+		if err := vm.DB.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+func TestMigrate(t *testing.T) {
+	db := memdb.New()
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+
+	// Create a database at the VM's own version
+	vm := &VM{}
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if version, err := vm.schemaVersion(); err != nil {
+		t.Fatal(err)
+	} else if version != uint32(len(builtinMigrations)) {
+		t.Fatalf("new database should be at version %d but is at %d", len(builtinMigrations), version)
+	}
+
+	runs := 0
+	migration := func(vm *VM) error {
+		runs++
+		return vm.DB.Put([]byte("migrated"), []byte{1})
+	}
+
+	// The migration runs once, no matter how many times the VM is initialized
+	for i := 0; i < 2; i++ {
+		vm = &VM{Migrations: []Migration{migration}}
+		if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+			t.Fatal(err)
+		}
+		if runs != 1 {
+			t.Fatalf("migration should have run once but ran %d times", runs)
+		}
+		if version, err := vm.schemaVersion(); err != nil {
+			t.Fatal(err)
+		} else if version != uint32(len(builtinMigrations))+1 {
+			t.Fatalf("database should be at version %d but is at %d", len(builtinMigrations)+1, version)
+		}
+		if migrated, err := db.Has([]byte("migrated")); err != nil {
+			t.Fatal(err)
+		} else if !migrated {
+			t.Fatal("migration should have been committed")
+		}
+	}
+
+	// A VM can't open a database from a newer version
+	vm = &VM{}
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err == nil {
+		t.Fatal("should have failed to open a database from a newer schema version")
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+
+	runs := 0
+	vm := &VM{Migrations: []Migration{
+		func(*VM) error { runs++; return nil },
+		func(*VM) error { runs++; return nil },
+	}}
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 0 {
+		t.Fatalf("migrations shouldn't run on a new database but ran %d times", runs)
+	}
+	if version, err := vm.schemaVersion(); err != nil {
+		t.Fatal(err)
+	} else if version != uint32(len(builtinMigrations))+2 {
+		t.Fatalf("new database should be at version %d but is at %d", len(builtinMigrations)+2, version)
+	}
+}
@@ -0,0 +1,20 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"encoding/binary"
+	"math/rand"
+)
+
+// Rand returns a random number generator seeded from the ID of the last
+// accepted block. Every node that has accepted the same block gets the same
+// sequence, so unlike the global math/rand source it can be used to make
+// decisions that every node must agree on.
+// The sequence is predictable, so it mustn't be used where an adversary
+// shouldn't be able to guess the outcome.
+func (vm *VM) Rand() *rand.Rand {
+	seed := int64(binary.BigEndian.Uint64(vm.LastAccepted().Bytes()))
+	return rand.New(rand.NewSource(seed))
+}
@@ -0,0 +1,34 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+)
+
+func TestRandDeterministic(t *testing.T) {
+	vm1, _ := NewTestVM(t)
+	vm2, _ := NewTestVM(t)
+	acceptBlocks(t, vm1, "a", "b")
+	acceptBlocks(t, vm2, "a", "b")
+
+	rand1, rand2 := vm1.Rand(), vm2.Rand()
+	for i := 0; i < 100; i++ {
+		if x1, x2 := rand1.Int63(), rand2.Int63(); x1 != x2 {
+			t.Fatalf("VMs at the same block diverged at draw %d: %d != %d", i, x1, x2)
+		}
+	}
+
+	// The sequence restarts every time Rand is called
+	if x1, x2 := vm1.Rand().Int63(), vm1.Rand().Int63(); x1 != x2 {
+		t.Fatalf("Rand should have returned the same sequence: %d != %d", x1, x2)
+	}
+
+	// A VM at a later block gets a different sequence
+	vm3, _ := NewTestVM(t)
+	acceptBlocks(t, vm3, "a", "b", "c")
+	if x1, x3 := vm1.Rand().Int63(), vm3.Rand().Int63(); x1 == x3 {
+		t.Fatal("VMs at different blocks should have had different sequences")
+	}
+}
@@ -0,0 +1,98 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"math"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// tokenBucketKey maps to the state of a rate limiter
+var tokenBucketKey = []byte("bucket")
+
+// rateLimiter is a token bucket that allows [rate] operations per second on
+// average, in bursts of up to [burst] operations. Its state is persisted, so
+// restarting the VM doesn't refill the bucket.
+type rateLimiter struct {
+	rate, burst float64
+
+	tokens     float64
+	lastRefill time.Time
+
+	clock timer.Clock
+	db    database.Database
+}
+
+// Initialize the limiter. Its state is loaded from [db] if it was saved,
+// otherwise the bucket starts full.
+// [db] should be written to directly, rather than through the VM's versiondb,
+// so that saving the limiter's state doesn't commit other pending writes.
+func (r *rateLimiter) Initialize(rate, burst float64, db database.Database) error {
+	r.rate = rate
+	r.burst = burst
+	r.db = prefixdb.New([]byte("rate limiter"), db)
+
+	bucketBytes, err := r.db.Get(tokenBucketKey)
+	if err == database.ErrNotFound {
+		r.tokens = burst
+		r.lastRefill = r.clock.Time()
+		return nil
+	} else if err != nil {
+		return err
+	}
+	p := wrappers.Packer{Bytes: bucketBytes}
+	r.tokens, r.lastRefill = p.UnpackTokenBucket()
+	return p.Err
+}
+
+// Allow returns true if a token is available in the bucket. No token is taken.
+func (r *rateLimiter) Allow() bool { return r.AllowN(1) }
+
+// AllowN returns true if [n] tokens are available in the bucket. No tokens are
+// taken, so that an operation that fails after being allowed doesn't spend
+// them. Call TakeN once the operation succeeds.
+func (r *rateLimiter) AllowN(n int) bool {
+	r.refill()
+	return r.tokens >= float64(n)
+}
+
+// TakeN takes [n] tokens from the bucket and saves its new state. If fewer
+// than [n] tokens are available, the bucket is emptied.
+func (r *rateLimiter) TakeN(n int) error {
+	r.refill()
+	r.tokens = math.Max(0, r.tokens-float64(n))
+
+	p := wrappers.Packer{MaxSize: wrappers.LongLen + wrappers.MaxVarIntLen}
+	p.PackTokenBucket(r.tokens, r.lastRefill)
+	if p.Errored() {
+		return p.Err
+	}
+	return r.db.Put(tokenBucketKey, p.Bytes)
+}
+
+// refill adds the tokens earned since the bucket was last refilled
+func (r *rateLimiter) refill() {
+	now := r.clock.Time()
+	if elapsed := now.Sub(r.lastRefill); elapsed > 0 {
+		r.tokens = math.Min(r.burst, r.tokens+elapsed.Seconds()*r.rate)
+		r.lastRefill = now
+	}
+}
+
+// takeProposeTokens takes [n] tokens from the propose rate limiter, if there is
+// one. The data was already proposed, so a failure to save the limiter's state
+// is only logged.
+func (vm *VM) takeProposeTokens(n int) {
+	if vm.proposeLimiter == nil {
+		return
+	}
+	if err := vm.proposeLimiter.TakeN(n); err != nil {
+		vm.Ctx.Log.Warn("failed to save the propose rate limiter's state: %s", err)
+	}
+}
@@ -0,0 +1,215 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+func TestProposeRateLimitPersists(t *testing.T) {
+	db := memdb.New()
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	now := time.Now()
+
+	// Only allows bursts of 3 proposals, and refills once an hour
+	newVM := func(db database.Database) *VM {
+		vm := &VM{ProposeRate: 1. / 3600, ProposeBurst: 3}
+		if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+			t.Fatal(err)
+		}
+		vm.Bootstrapped()
+		vm.proposeLimiter.clock.Set(now)
+		return vm
+	}
+	propose := func(vm *VM, i byte) error {
+		data := formatting.CB58{Bytes: make([]byte, defaultMaxDataLen)}
+		data.Bytes[0] = i
+		return (&Service{vm}).ProposeBlock(nil, &ProposeBlockArgs{Data: data.String()}, &ProposeBlockReply{})
+	}
+
+	vm := newVM(db)
+	for i := byte(0); i < 2; i++ {
+		if err := propose(vm, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Restarting the VM doesn't refill the bucket
+	vm = newVM(db)
+	if tokens := vm.proposeLimiter.tokens; tokens != 1 {
+		t.Fatalf("Restarted bucket should have had 1 token but had %v", tokens)
+	}
+	if err := propose(vm, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := propose(vm, 3); err != errRateLimited {
+		t.Fatalf("Proposal should have been rate limited but returned %v", err)
+	}
+
+	// Tokens are refilled over time
+	vm.proposeLimiter.clock.Set(now.Add(2 * time.Hour))
+	if err := propose(vm, 3); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProposeRateLimitDisabled(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	if vm.proposeLimiter != nil {
+		t.Fatal("Proposals shouldn't be rate limited by default")
+	}
+}
+
+func TestProposeBlocksRateLimit(t *testing.T) {
+	vm := &VM{ProposeRate: 1. / 3600, ProposeBurst: 3}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+	vm.proposeLimiter.clock.Set(time.Now())
+	service := Service{vm}
+	data := formatting.CB58{Bytes: make([]byte, defaultMaxDataLen)}
+
+	// A batch takes a token per piece of data
+	args := &ProposeBlocksArgs{Data: []string{data.String(), data.String()}}
+	if err := service.ProposeBlocks(nil, args, &ProposeBlocksReply{}); err != nil {
+		t.Fatal(err)
+	}
+	// A batch larger than the remaining tokens takes none of them
+	if err := service.ProposeBlocks(nil, args, &ProposeBlocksReply{}); err != errRateLimited {
+		t.Fatalf("Proposal should have been rate limited but returned %v", err)
+	}
+	if tokens := vm.proposeLimiter.tokens; tokens != 1 {
+		t.Fatalf("Bucket should have had 1 token but had %v", tokens)
+	}
+}
+
+func TestProposeRateLimitFailedProposal(t *testing.T) {
+	vm := &VM{ProposeRate: 1. / 3600, ProposeBurst: 1, MaxMempoolSize: 1}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+	vm.proposeLimiter.clock.Set(time.Now())
+	service := Service{vm}
+
+	// A proposal that doesn't fit in the mempool doesn't take a token
+	vm.mempool = [][]byte{{0}}
+	args := &ProposeBlockArgs{Data: formatting.CB58{Bytes: []byte{1}}.String()}
+	if err := service.ProposeBlock(nil, args, &ProposeBlockReply{}); err != errMempoolFull {
+		t.Fatalf("Expected %s but got %v", errMempoolFull, err)
+	}
+	if tokens := vm.proposeLimiter.tokens; tokens != 1 {
+		t.Fatalf("Bucket should have had 1 token but had %v", tokens)
+	}
+
+	vm.mempool = nil
+	if err := service.ProposeBlock(nil, args, &ProposeBlockReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if tokens := vm.proposeLimiter.tokens; tokens != 0 {
+		t.Fatalf("Bucket should have had 0 tokens but had %v", tokens)
+	}
+}
+
+func TestProposeRateLimitDoesntCommit(t *testing.T) {
+	db := memdb.New()
+	vm := &VM{ProposeRate: 1, ProposeBurst: 1}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+
+	// Saving the bucket mustn't commit writes pending in the VM's database
+	key := []byte("pending")
+	if err := vm.DB.Put(key, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	args := &ProposeBlockArgs{Data: formatting.CB58{Bytes: []byte{1}}.String()}
+	if err := (&Service{vm}).ProposeBlock(nil, args, &ProposeBlockReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := db.Has(key); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatal("Rate limiting a proposal committed the VM's pending writes")
+	}
+}
+
+func TestProposeBlocksRateLimitFailedProposal(t *testing.T) {
+	vm := &VM{ProposeRate: 1. / 3600, ProposeBurst: 3, MaxMempoolSize: 1}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+	vm.proposeLimiter.clock.Set(time.Now())
+	service := Service{vm}
+
+	// A batch that doesn't fit in the mempool doesn't take any tokens
+	data := formatting.CB58{Bytes: []byte{1}}.String()
+	args := &ProposeBlocksArgs{Data: []string{data, data}}
+	if err := service.ProposeBlocks(nil, args, &ProposeBlocksReply{}); err != errMempoolFull {
+		t.Fatalf("Expected %s but got %v", errMempoolFull, err)
+	}
+	if tokens := vm.proposeLimiter.tokens; tokens != 3 {
+		t.Fatalf("Bucket should have had 3 tokens but had %v", tokens)
+	}
+}
+
+func TestProposeBlocksRateLimitSaveFails(t *testing.T) {
+	vm := &VM{ProposeRate: 1. / 3600, ProposeBurst: 3}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+	vm.proposeLimiter.clock.Set(time.Now())
+	closedDB := memdb.New()
+	closedDB.Close()
+	vm.proposeLimiter.db = closedDB
+
+	// The batch is already in the mempool when the bucket is saved, so failing
+	// to save it doesn't fail the proposal
+	data := formatting.CB58{Bytes: []byte{1}}.String()
+	reply := &ProposeBlocksReply{}
+	if err := (&Service{vm}).ProposeBlocks(nil, &ProposeBlocksArgs{Data: []string{data, data}}, reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Accepted != 2 || len(vm.mempool) != 2 {
+		t.Fatalf("Both pieces of data should have been proposed but %d were and the mempool has %d", reply.Accepted, len(vm.mempool))
+	}
+	if tokens := vm.proposeLimiter.tokens; tokens != 1 {
+		t.Fatalf("Bucket should have had 1 token but had %v", tokens)
+	}
+}
+
+func TestRateLimiterTakeNEmpties(t *testing.T) {
+	r := rateLimiter{}
+	if err := r.Initialize(1, 2, memdb.New()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.TakeN(3); err != nil {
+		t.Fatal(err)
+	}
+	if r.tokens != 0 {
+		t.Fatalf("Bucket should have been emptied but had %v tokens", r.tokens)
+	}
+}
@@ -0,0 +1,46 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+)
+
+// blockRoute is the REST route that serves the block with ID [id]
+const blockRoute = "/block/{id}"
+
+// serveBlock writes the API representation of the block whose ID is the [id]
+// path variable. Accepted blocks never change, so their responses may be
+// cached.
+func (vm *VM) serveBlock(w http.ResponseWriter, r *http.Request) {
+	blkID, err := ids.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "problem parsing ID", http.StatusBadRequest)
+		return
+	}
+	blk, err := vm.GetBlock(blkID)
+	if err != nil {
+		http.Error(w, errNoSuchBlock.Error(), http.StatusNotFound)
+		return
+	}
+	block, ok := blk.(*Block)
+	if !ok {
+		http.Error(w, errBadData.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if block.Status() == choices.Accepted {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	if err := json.NewEncoder(w).Encode(newAPIBlock(block)); err != nil {
+		vm.Ctx.Log.Debug("failed to write block %s: %s", blkID, err)
+	}
+}
@@ -0,0 +1,59 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestServeBlock(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	blocks := acceptBlocks(t, vm, "hello world")
+
+	handler, ok := vm.CreateHandlers()[blockRoute]
+	if !ok {
+		t.Fatalf("the VM should have registered %s", blockRoute)
+	}
+	if len(handler.Methods) != 1 || handler.Methods[0] != http.MethodGet {
+		t.Fatalf("%s should only serve GET requests but serves %v", blockRoute, handler.Methods)
+	}
+	// Route the handler the way the API server does
+	router := mux.NewRouter()
+	router.Handle(blockRoute, handler.Handler)
+
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, httptest.NewRequest("GET", "/block/"+blocks[0].ID().String(), nil))
+	if writer.Code != http.StatusOK {
+		t.Fatalf("GET returned %d: %s", writer.Code, writer.Body.String())
+	}
+	if writer.Header().Get("Cache-Control") == "" {
+		t.Fatal("an accepted block should have been cacheable")
+	}
+	reply := APIBlock{}
+	if err := json.NewDecoder(writer.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply != newAPIBlock(blocks[0]) {
+		t.Fatalf("GET returned %+v, expected %+v", reply, newAPIBlock(blocks[0]))
+	}
+
+	writer = httptest.NewRecorder()
+	router.ServeHTTP(writer, httptest.NewRequest("GET", "/block/"+ids.Empty.String(), nil))
+	if writer.Code != http.StatusNotFound {
+		t.Fatalf("GET of an unknown block returned %d, expected %d", writer.Code, http.StatusNotFound)
+	}
+
+	writer = httptest.NewRecorder()
+	router.ServeHTTP(writer, httptest.NewRequest("GET", "/block/notanid", nil))
+	if writer.Code != http.StatusBadRequest {
+		t.Fatalf("GET of a malformed ID returned %d, expected %d", writer.Code, http.StatusBadRequest)
+	}
+}
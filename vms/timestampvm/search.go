@@ -0,0 +1,229 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+)
+
+// gramLen is the length of the substrings of block data that are indexed.
+// Substring queries at least this long are answered by intersecting the blocks
+// that contain each of the query's grams.
+const gramLen = 3
+
+var (
+	errSearchDisabled = errors.New("search isn't enabled on this node")
+	errEmptyQuery     = errors.New("search query is empty")
+)
+
+// searchIndex is an inverted index from the data of accepted blocks to their
+// IDs. It is stored in the VM's database so that it's updated atomically with
+// the acceptance of blocks.
+//
+// [grams] maps every substring of a block's text of length at most [gramLen]
+// to the block. Keys are the substring, zero padded to [gramLen] bytes,
+// followed by the block ID.
+//
+// [exact] maps a block's data to the block. Keys are the data followed by the
+// block ID.
+type searchIndex struct {
+	grams database.Database
+	exact database.Database
+}
+
+func (s *searchIndex) Initialize(db database.Database) {
+	s.grams = prefixdb.New([]byte("search grams"), db)
+	s.exact = prefixdb.New([]byte("search exact"), db)
+}
+
+// text returns [data] with any zero padding removed
+func text(data []byte) []byte { return bytes.TrimRight(data, "\x00") }
+
+// gramKey returns the key that maps [gram] to [blkID] in the gram index
+func gramKey(gram []byte, blkID ids.ID) []byte {
+	key := make([]byte, gramLen, gramLen+len(blkID.Bytes()))
+	copy(key, gram)
+	return append(key, blkID.Bytes()...)
+}
+
+// Add [blk] to the index
+func (s *searchIndex) Add(blk *Block) error {
+	blkID := blk.ID()
+	txt := text(blk.Data)
+	for i := range txt {
+		end := i + gramLen
+		if end > len(txt) {
+			end = len(txt)
+		}
+		if err := s.grams.Put(gramKey(txt[i:end], blkID), nil); err != nil {
+			return err
+		}
+	}
+	key := make([]byte, 0, len(blk.Data)+len(blkID.Bytes()))
+	key = append(key, blk.Data...)
+	return s.exact.Put(append(key, blkID.Bytes()...), nil)
+}
+
+// Empty returns true iff no blocks have been added to the index
+func (s *searchIndex) Empty() (bool, error) {
+	it := s.exact.NewIterator()
+	defer it.Release()
+	return !it.Next(), it.Error()
+}
+
+// Clear removes every block from the index
+func (s *searchIndex) Clear() error {
+	for _, db := range []database.Database{s.grams, s.exact} {
+		keys := [][]byte(nil)
+		it := db.NewIterator()
+		for it.Next() {
+			keys = append(keys, it.Key())
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := db.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// blockIDs returns the IDs of the blocks whose keys in [db] start with
+// [prefix], where the block ID is the suffix of each key
+func blockIDs(db database.Database, prefix []byte) (ids.Set, error) {
+	blkIDs := ids.Set{}
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		blkID, err := ids.ToID(key[len(key)-len(ids.Empty.Bytes()):])
+		if err != nil {
+			return nil, err
+		}
+		blkIDs.Add(blkID)
+	}
+	return blkIDs, it.Error()
+}
+
+// Substring returns the IDs of the blocks that may contain [query] in their
+// text. Every block that does is returned, but the caller must check the
+// returned blocks as some may not.
+func (s *searchIndex) Substring(query []byte) (ids.Set, error) {
+	if len(query) < gramLen {
+		return blockIDs(s.grams, query)
+	}
+
+	candidates := ids.Set(nil)
+	for i := 0; i+gramLen <= len(query); i++ {
+		blkIDs, err := blockIDs(s.grams, query[i:i+gramLen])
+		if err != nil {
+			return nil, err
+		}
+		if candidates == nil {
+			candidates = blkIDs
+			continue
+		}
+		intersection := ids.Set{}
+		for _, blkID := range candidates.List() {
+			if blkIDs.Contains(blkID) {
+				intersection.Add(blkID)
+			}
+		}
+		candidates = intersection
+	}
+	return candidates, nil
+}
+
+// Exact returns the IDs of the blocks whose data is [data]. Blocks whose data
+// merely starts with [data] may also be returned.
+func (s *searchIndex) Exact(data []byte) (ids.Set, error) {
+	return blockIDs(s.exact, data)
+}
+
+// indexBlock adds [blk] to the search index, if search is enabled
+func (vm *VM) indexBlock(blk *Block) error {
+	if !vm.EnableSearch {
+		return nil
+	}
+	return vm.search.Add(blk)
+}
+
+// RebuildSearchIndex clears the search index and re-adds every accepted block
+// to it. The blocks are found by walking back from the last accepted block to
+// the genesis block.
+func (vm *VM) RebuildSearchIndex() error {
+	if !vm.EnableSearch {
+		return errSearchDisabled
+	}
+	if err := vm.search.Clear(); err != nil {
+		return err
+	}
+	for blkID := vm.LastAccepted(); !blkID.IsZero() && !blkID.Equals(ids.Empty); {
+		blk, err := vm.getBlock(blkID)
+		if err != nil {
+			return err
+		}
+		if err := vm.search.Add(blk); err != nil {
+			return err
+		}
+		blkID = blk.ParentID()
+	}
+	return vm.DB.Commit()
+}
+
+// searchBlocks returns the accepted blocks that contain [query] in their text
+// or, if [exact], whose data is exactly [query], sorted by timestamp.
+func (vm *VM) searchBlocks(query []byte, exact bool) ([]*Block, error) {
+	if !vm.EnableSearch {
+		return nil, errSearchDisabled
+	}
+	if len(query) == 0 {
+		return nil, errEmptyQuery
+	}
+
+	var (
+		candidates ids.Set
+		err        error
+	)
+	if exact {
+		if len(query) > vm.maxDataLen {
+			return nil, nil
+		}
+		candidates, err = vm.search.Exact(query)
+	} else {
+		candidates, err = vm.search.Substring(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := []*Block(nil)
+	for _, blkID := range candidates.List() {
+		blk, err := vm.getBlock(blkID)
+		if err != nil {
+			return nil, err
+		}
+		if exact && bytes.Equal(blk.Data, query) || !exact && bytes.Contains(text(blk.Data), query) {
+			blocks = append(blocks, blk)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Timestamp != blocks[j].Timestamp {
+			return blocks[i].Timestamp < blocks[j].Timestamp
+		}
+		return bytes.Compare(blocks[i].ID().Bytes(), blocks[j].ID().Bytes()) < 0
+	})
+	return blocks, nil
+}
@@ -0,0 +1,134 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+// acceptBlocks builds and accepts a chain of blocks containing [texts]
+func acceptBlocks(t *testing.T, vm *VM, texts ...string) []*Block {
+	blocks := []*Block(nil)
+	for i, txt := range texts {
+		blk, err := vm.NewBlock(vm.LastAccepted(), []byte(txt), time.Unix(int64(i+1), 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := blk.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		blk.Accept()
+		blocks = append(blocks, blk)
+	}
+	return blocks
+}
+
+func TestSearchBlocks(t *testing.T) {
+	db := memdb.New()
+	vm := &VM{EnableSearch: true}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	blocks := acceptBlocks(t, vm, "hello world", "goodbye world", "hello again")
+
+	service := Service{vm}
+	tests := []struct {
+		query    string
+		encoding string
+		expected []*Block
+	}{
+		{query: "world", expected: []*Block{blocks[0], blocks[1]}},
+		{query: "hello", encoding: "text", expected: []*Block{blocks[0], blocks[2]}},
+		{query: "bye", expected: []*Block{blocks[1]}},
+		{query: "o", expected: blocks},
+		{query: "d", expected: []*Block{blocks[0], blocks[1]}},
+		{query: "hello world!", expected: nil},
+		{query: "lo wo", expected: []*Block{blocks[0]}},
+		{query: formatting.CB58{Bytes: []byte("hello again")}.String(), encoding: "cb58", expected: []*Block{blocks[2]}},
+		{query: formatting.CB58{Bytes: []byte("hello")}.String(), encoding: "cb58", expected: nil},
+	}
+	for _, test := range tests {
+		reply := SearchBlocksReply{}
+		if err := service.SearchBlocks(nil, &SearchBlocksArgs{Query: test.query, Encoding: test.encoding}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Blocks) != len(test.expected) {
+			t.Fatalf("searching for %q returned %d blocks, expected %d", test.query, len(reply.Blocks), len(test.expected))
+		}
+		for i, blk := range test.expected {
+			if reply.Blocks[i].ID != blk.ID().String() {
+				t.Fatalf("searching for %q returned block %s at %d, expected %s", test.query, reply.Blocks[i].ID, i, blk.ID())
+			}
+		}
+	}
+
+	if err := service.SearchBlocks(nil, &SearchBlocksArgs{Query: "hello", Encoding: "hex"}, &SearchBlocksReply{}); err == nil {
+		t.Fatal("should have failed with an unknown encoding")
+	}
+}
+
+func TestSearchBlocksDisabled(t *testing.T) {
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	service := Service{vm}
+	if err := service.SearchBlocks(nil, &SearchBlocksArgs{Query: "genesis"}, &SearchBlocksReply{}); err != errSearchDisabled {
+		t.Fatalf("expected %s but got %v", errSearchDisabled, err)
+	}
+}
+
+func TestRebuildSearchIndex(t *testing.T) {
+	db := memdb.New()
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	vm := &VM{}
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	blocks := acceptBlocks(t, vm, "hello world")
+	if err := vm.DB.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enabling search on a chain that already has blocks should build the index
+	vm = &VM{EnableSearch: true}
+	if err := vm.Initialize(ctx, db, []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	found, err := vm.searchBlocks([]byte("world"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || !found[0].ID().Equals(blocks[0].ID()) {
+		t.Fatalf("expected to find block %s but found %d blocks", blocks[0].ID(), len(found))
+	}
+	found, err = vm.searchBlocks([]byte("genesis"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected to find the genesis block but found %d blocks", len(found))
+	}
+
+	if err := vm.RebuildSearchIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := vm.searchBlocks([]byte("world"), false); err != nil {
+		t.Fatal(err)
+	} else if len(found) != 1 {
+		t.Fatalf("expected to find 1 block after rebuilding but found %d", len(found))
+	}
+}
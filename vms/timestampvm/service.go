@@ -4,8 +4,13 @@
 package timestampvm
 
 import (
+	"context"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils/json"
@@ -14,9 +19,15 @@ import (
 )
 
 var (
-	errDBError     = errors.New("error getting data from database")
-	errBadData     = errors.New("data must be base 58 repr. of 32 bytes")
-	errNoSuchBlock = errors.New("couldn't get block from database. Does it exist?")
+	errDBError          = errors.New("error getting data from database")
+	errBadData          = errors.New("data must be base 58 repr. of bytes")
+	errNoSuchBlock      = errors.New("couldn't get block from database. Does it exist?")
+	errBadEncoding      = errors.New("encoding must be one of {text, cb58}")
+	errTimeout          = errors.New("timed out waiting for the proposed block to be accepted")
+	errRateLimited      = errors.New("too many blocks proposed, try again later")
+	errEvicted          = errors.New("the proposed data was evicted from the mempool, try again later")
+	errBadBatchEncoding = errors.New("encoding must be one of {cb58, hex}")
+	errDataTooLong      = errors.New("data is longer than the max data length")
 )
 
 // Service is the API service for this VM
@@ -24,35 +35,163 @@ type Service struct{ vm *VM }
 
 // ProposeBlockArgs are the arguments to function ProposeValue
 type ProposeBlockArgs struct {
-	// Data in the block. Must be base 58 encoding of 32 bytes.
+	// Data in the block. Must be base 58 encoding of at most the VM's max data
+	// length bytes.
 	Data string `json:"data"`
+	// If true, the call doesn't return until a block containing [Data] is
+	// accepted, or the VM's propose timeout elapses. Once the call returns
+	// successfully, reads from this node see the accepted block.
+	Sync bool `json:"sync"`
 }
 
 // ProposeBlockReply is the reply from function ProposeBlock
-type ProposeBlockReply struct{ Success bool }
+type ProposeBlockReply struct {
+	Success bool
+	// ID of the accepted block containing the data. Only set if the proposal
+	// was synchronous.
+	BlockID string `json:"blockID,omitempty"`
+}
 
 // ProposeBlock is an API method to propose a new block whose data is [args].Data.
-// [args].Data must be a string repr. of a 32 byte array
-func (s *Service) ProposeBlock(_ *http.Request, args *ProposeBlockArgs, reply *ProposeBlockReply) error {
+// [args].Data must be a string repr. of data no longer than the chain's max
+// data length
+// If [args].Sync, waits for the block to be accepted and returns its ID.
+func (s *Service) ProposeBlock(r *http.Request, args *ProposeBlockArgs, reply *ProposeBlockReply) error {
 	byteFormatter := formatting.CB58{}
 	if err := byteFormatter.FromString(args.Data); err != nil {
 		return errBadData
 	}
-	dataSlice := byteFormatter.Bytes
-	if len(dataSlice) != dataLen {
-		return errBadData
+	data := byteFormatter.Bytes
+	if len(data) > s.vm.maxDataLen {
+		return fmt.Errorf("%w: %d > %d", errDataTooLong, len(data), s.vm.maxDataLen)
+	}
+	if err := s.vm.checkWritable(); err != nil {
+		return err
+	}
+	limiter := s.vm.proposeLimiter
+	if limiter != nil && !limiter.Allow() {
+		return errRateLimited
+	}
+	if err := s.vm.proposeBlock(data); err != nil {
+		return err
+	}
+	// The token is only taken once the data is in the mempool, so rejected
+	// proposals don't count against the limit
+	s.vm.takeProposeTokens(1)
+	if !args.Sync {
+		reply.Success = true
+		return nil
+	}
+
+	// The context lock is held, so the block can't have been accepted yet
+	accepted := s.vm.awaitAcceptance(data)
+
+	// The context lock is held while the API is called, so it must be released
+	// for the block to be accepted. It is re-acquired before returning, as it
+	// is unlocked after this call returns.
+	// Stop waiting if the client goes away, so that abandoned requests don't
+	// hold a waiter until the timeout.
+	ctx := requestContext(r)
+	timer := time.NewTimer(s.vm.proposeTimeout())
+	defer timer.Stop()
+	s.vm.Ctx.Lock.Unlock()
+	waitErr := errTimeout
+	select {
+	case blkID := <-accepted:
+		s.vm.Ctx.Lock.Lock()
+		return acceptedReply(blkID, reply)
+	case <-timer.C:
+	case <-ctx.Done():
+		waitErr = ctx.Err()
+	}
+	s.vm.Ctx.Lock.Lock()
+
+	// The block may have been accepted before the lock was re-acquired
+	select {
+	case blkID := <-accepted:
+		return acceptedReply(blkID, reply)
+	default:
+		s.vm.cancelAwait(data, accepted)
+		return waitErr
+	}
+}
+
+// acceptedReply sets [reply] to report that the block with ID [blkID], which
+// contains the proposed data, was accepted. Returns an error if the data was
+// evicted from the mempool instead.
+func acceptedReply(blkID ids.ID, reply *ProposeBlockReply) error {
+	if blkID.Equals(ids.Empty) {
+		return errEvicted
 	}
-	var data [dataLen]byte             // The data as an array of bytes
-	copy(data[:], dataSlice[:dataLen]) // Copy the bytes in dataSlice to data
-	s.vm.proposeBlock(data)
 	reply.Success = true
+	reply.BlockID = blkID.String()
+	return nil
+}
+
+// ProposeBlocksArgs are the arguments to ProposeBlocks
+type ProposeBlocksArgs struct {
+	// Data of each block. Each piece of data is at most the VM's max data
+	// length bytes.
+	Data []string `json:"data"`
+	// Encoding of [Data]. One of:
+	// * "cb58" (default)
+	// * "hex"
+	Encoding string `json:"encoding"`
+}
+
+// ProposeBlocksReply is the reply from ProposeBlocks
+type ProposeBlocksReply struct {
+	// Number of pieces of data proposed, which is either all or none of them
+	Accepted int `json:"accepted"`
+}
+
+// ProposeBlocks proposes a new block for each piece of data in [args].Data.
+// Either all of the data is proposed, or, if any of it is invalid or can't be
+// proposed, none of it is and the first error is returned.
+func (s *Service) ProposeBlocks(_ *http.Request, args *ProposeBlocksArgs, reply *ProposeBlocksReply) error {
+	data := make([][]byte, len(args.Data))
+	for i, encoded := range args.Data {
+		var dataSlice []byte
+		switch args.Encoding {
+		case "", "cb58":
+			byteFormatter := formatting.CB58{}
+			if err := byteFormatter.FromString(encoded); err != nil {
+				return fmt.Errorf("data %d: %w", i, errBadData)
+			}
+			dataSlice = byteFormatter.Bytes
+		case "hex":
+			var err error
+			if dataSlice, err = hex.DecodeString(encoded); err != nil {
+				return fmt.Errorf("data %d: %w", i, errBadData)
+			}
+		default:
+			return errBadBatchEncoding
+		}
+		if len(dataSlice) > s.vm.maxDataLen {
+			return fmt.Errorf("data %d: %w", i, errDataTooLong)
+		}
+		data[i] = dataSlice
+	}
+
+	if err := s.vm.checkWritable(); err != nil {
+		return err
+	}
+	limiter := s.vm.proposeLimiter
+	if limiter != nil && !limiter.AllowN(len(data)) {
+		return errRateLimited
+	}
+	if err := s.vm.proposeBlocks(data); err != nil {
+		return err
+	}
+	s.vm.takeProposeTokens(len(data))
+	reply.Accepted = len(data)
 	return nil
 }
 
 // APIBlock is the API representation of a block
 type APIBlock struct {
 	Timestamp json.Uint64 `json:"timestamp"` // Timestamp of most recent block
-	Data      string      `json:"data"`      // Data in the most recent block. Base 58 repr. of 5 bytes.
+	Data      string      `json:"data"`      // Data in the most recent block. Base 58 repr. of at most the VM's max data length bytes.
 	ID        string      `json:"id"`        // String repr. of ID of the most recent block
 	ParentID  string      `json:"parentID"`  // String repr. of ID of the most recent block's parent
 }
@@ -93,11 +232,203 @@ func (s *Service) GetBlock(_ *http.Request, args *GetBlockArgs, reply *GetBlockR
 		return errBadData
 	}
 
-	reply.APIBlock.ID = block.ID().String()
-	reply.APIBlock.Timestamp = json.Uint64(block.Timestamp)
-	reply.APIBlock.ParentID = block.ParentID().String()
-	byteFormatter := formatting.CB58{Bytes: block.Data[:]}
-	reply.Data = byteFormatter.String()
+	reply.APIBlock = newAPIBlock(block)
+	return nil
+}
+
+// GetBlockByHeightArgs are the arguments to GetBlockByHeight
+type GetBlockByHeightArgs struct {
+	// Height of the accepted block. The genesis block has height 0.
+	Height json.Uint64 `json:"height"`
+}
+
+// GetBlockByHeightReply is the reply from GetBlockByHeight
+type GetBlockByHeightReply struct {
+	APIBlock
+}
+
+// GetBlockByHeight gets the accepted block at height [args.Height]
+func (s *Service) GetBlockByHeight(_ *http.Request, args *GetBlockByHeightArgs, reply *GetBlockByHeightReply) error {
+	block, err := s.vm.getBlockByHeight(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+	reply.APIBlock = newAPIBlock(block)
+	return nil
+}
+
+// newAPIBlock returns the API representation of [block]
+func newAPIBlock(block *Block) APIBlock {
+	byteFormatter := formatting.CB58{Bytes: block.Data}
+	return APIBlock{
+		ID:        block.ID().String(),
+		Timestamp: json.Uint64(block.Timestamp),
+		ParentID:  block.ParentID().String(),
+		Data:      byteFormatter.String(),
+	}
+}
+
+// SearchBlocksArgs are the arguments to SearchBlocks
+type SearchBlocksArgs struct {
+	// Query to search for
+	Query string `json:"query"`
+	// Encoding of [Query]. One of:
+	// * "text" (default): matches blocks whose data contains [Query]
+	// * "cb58": matches blocks whose data is exactly the decoded [Query]
+	Encoding string `json:"encoding"`
+}
+
+// SearchBlocksReply is the reply from SearchBlocks
+type SearchBlocksReply struct {
+	// Matching accepted blocks, sorted by timestamp
+	Blocks []APIBlock `json:"blocks"`
+}
 
+// SearchBlocks returns the accepted blocks whose data matches [args.Query]
+// The VM must have search enabled.
+func (s *Service) SearchBlocks(_ *http.Request, args *SearchBlocksArgs, reply *SearchBlocksReply) error {
+	var (
+		query []byte
+		exact bool
+	)
+	switch args.Encoding {
+	case "", "text":
+		query = []byte(args.Query)
+	case "cb58":
+		byteFormatter := formatting.CB58{}
+		if err := byteFormatter.FromString(args.Query); err != nil {
+			return errBadData
+		}
+		query = byteFormatter.Bytes
+		exact = true
+	default:
+		return errBadEncoding
+	}
+
+	blocks, err := s.vm.searchBlocks(query, exact)
+	if err != nil {
+		return err
+	}
+	reply.Blocks = make([]APIBlock, len(blocks))
+	for i, block := range blocks {
+		reply.Blocks[i] = newAPIBlock(block)
+	}
 	return nil
 }
+
+// GetBlocksByTimeRangeArgs are the arguments to GetBlocksByTimeRange
+type GetBlocksByTimeRangeArgs struct {
+	// Start of the time range, as a Unix timestamp, inclusive
+	Start json.Uint64 `json:"start"`
+	// End of the time range, as a Unix timestamp, inclusive
+	End json.Uint64 `json:"end"`
+	// Maximum number of blocks. If 0 or more than the VM's max page size, at
+	// most the max page size are returned.
+	Limit json.Uint64 `json:"limit"`
+	// Blocks below this height aren't returned. To get the next page, set it
+	// to the NextHeight of the previous reply.
+	StartHeight json.Uint64 `json:"startHeight"`
+}
+
+// GetBlocksByTimeRangeReply is the reply from GetBlocksByTimeRange
+type GetBlocksByTimeRangeReply struct {
+	// Accepted blocks in the time range, ordered by height. There are at most
+	// the VM's max page size.
+	Blocks []APIBlock `json:"blocks"`
+	// If more blocks are in the time range, the height of the next one.
+	// Omitted if there are no more blocks.
+	NextHeight json.Uint64 `json:"nextHeight,omitempty"`
+}
+
+// GetBlocksByTimeRange returns up to [args.Limit] accepted blocks whose
+// timestamps are in [[args.Start], [args.End]], starting at height
+// [args.StartHeight]. Stops early if the request is cancelled or its deadline
+// passes.
+func (s *Service) GetBlocksByTimeRange(r *http.Request, args *GetBlocksByTimeRangeArgs, reply *GetBlocksByTimeRangeReply) error {
+	if args.Start > math.MaxInt64 {
+		// No block can be in the range
+		reply.Blocks = []APIBlock{}
+		return nil
+	}
+	end := int64(math.MaxInt64)
+	if args.End < math.MaxInt64 {
+		end = int64(args.End)
+	}
+	limit := s.vm.MaxPageSize
+	if args.Limit != 0 && uint64(args.Limit) < uint64(limit) {
+		limit = int(args.Limit)
+	}
+
+	blocks, next, err := s.vm.getBlocksByTimeRange(requestContext(r), int64(args.Start), end, uint64(args.StartHeight), limit)
+	if err != nil {
+		return err
+	}
+	reply.Blocks = make([]APIBlock, len(blocks))
+	for i, block := range blocks {
+		reply.Blocks[i] = newAPIBlock(block)
+	}
+	reply.NextHeight = json.Uint64(next)
+	return nil
+}
+
+// GetTimestampsArgs are the arguments to GetTimestamps
+type GetTimestampsArgs struct {
+	// Height of the first block
+	StartHeight json.Uint64 `json:"startHeight"`
+	// Maximum number of blocks. If more than the VM's max page size, at most
+	// the max page size are returned.
+	Count json.Uint64 `json:"count"`
+}
+
+// APIHeightTimestamp is the timestamp of the accepted block at a height
+type APIHeightTimestamp struct {
+	Height    json.Uint64 `json:"height"`
+	Timestamp json.Uint64 `json:"timestamp"`
+}
+
+// GetTimestampsReply is the reply from GetTimestamps
+type GetTimestampsReply struct {
+	// Timestamps of the accepted blocks, ordered by height. There are at most
+	// the VM's max page size.
+	Timestamps []APIHeightTimestamp `json:"timestamps"`
+	// If the count was truncated to the VM's max page size and more blocks
+	// have been accepted, the height of the next one. Omitted otherwise.
+	NextHeight json.Uint64 `json:"nextHeight,omitempty"`
+}
+
+// GetTimestamps returns the timestamps of up to [args.Count] accepted blocks
+// starting at height [args.StartHeight]. Blocks aren't fetched, so this is
+// cheaper than getting each block.
+func (s *Service) GetTimestamps(_ *http.Request, args *GetTimestampsArgs, reply *GetTimestampsReply) error {
+	count := uint64(args.Count)
+	truncated := count > uint64(s.vm.MaxPageSize)
+	if truncated {
+		count = uint64(s.vm.MaxPageSize)
+	}
+	timestamps, err := s.vm.getTimestamps(uint64(args.StartHeight), count)
+	if err != nil {
+		return err
+	}
+	if truncated && len(timestamps) > 0 {
+		if next := timestamps[len(timestamps)-1].Height + 1; next <= s.vm.lastHeight {
+			reply.NextHeight = json.Uint64(next)
+		}
+	}
+	reply.Timestamps = make([]APIHeightTimestamp, len(timestamps))
+	for i, timestamp := range timestamps {
+		reply.Timestamps[i] = APIHeightTimestamp{
+			Height:    json.Uint64(timestamp.Height),
+			Timestamp: json.Uint64(timestamp.Timestamp),
+		}
+	}
+	return nil
+}
+
+// requestContext returns the context of [r], which is done when the client
+// disconnects or the request's deadline passes
+func requestContext(r *http.Request) context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	return r.Context()
+}
@@ -0,0 +1,307 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// defaultSnapshotsToKeep is the number of snapshots kept if SnapshotsToKeep
+	// is 0
+	defaultSnapshotsToKeep = 3
+
+	snapshotExt = ".snapshot"
+
+	// snapshotChunkSize is the number of blocks read each time the context
+	// lock is taken while exporting a snapshot
+	snapshotChunkSize = 256
+
+	// maxBlockOverhead bounds the number of bytes a block takes beyond its
+	// data, so that a corrupt snapshot can't cause a huge allocation
+	maxBlockOverhead = 1024
+)
+
+var (
+	errSnapshotStopped      = errors.New("snapshot exporter was stopped")
+	errSnapshotMismatch     = errors.New("snapshot's chain doesn't match the accepted chain")
+	errSnapshotBlockTooLong = errors.New("snapshot's block is longer than a block can be")
+	errSnapshotTrailingData = errors.New("snapshot has data after its last block")
+)
+
+// RestoreSnapshot accepts the blocks in [snapshot], as written by the snapshot
+// exporter, that are past the last accepted block. The blocks that are already
+// accepted must be the first blocks of the snapshot. Each restored block must
+// be valid.
+// Must be called with the context lock held.
+func (vm *VM) RestoreSnapshot(snapshot io.Reader) error {
+	r := bufio.NewReader(snapshot)
+	numBlocks, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	for height := uint64(0); height < numBlocks; height++ {
+		blkBytes, err := vm.readSnapshotBlock(r)
+		if err != nil {
+			return err
+		}
+		blkIntf, err := vm.ParseBlock(blkBytes)
+		if err != nil {
+			return err
+		}
+		blk := blkIntf.(*Block)
+
+		if height <= vm.lastHeight {
+			acceptedID, err := vm.heights.Get(height)
+			if err != nil {
+				return err
+			}
+			if !acceptedID.Equals(blk.ID()) {
+				return fmt.Errorf("%w at height %d", errSnapshotMismatch, height)
+			}
+			continue
+		}
+		if !blk.ParentID().Equals(vm.LastAccepted()) {
+			return fmt.Errorf("%w at height %d", errSnapshotMismatch, height)
+		}
+		if err := blk.Verify(); err != nil {
+			return fmt.Errorf("block %s at height %d is invalid: %w", blk.ID(), height, err)
+		}
+		blk.Accept()
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		return errSnapshotTrailingData
+	}
+	vm.SetPreference(vm.LastAccepted())
+	return nil
+}
+
+// readSnapshotBlock reads the bytes of the next block in a snapshot from [r]
+func (vm *VM) readSnapshotBlock(r io.Reader) ([]byte, error) {
+	sizeBytes := [wrappers.IntLen]byte{}
+	if _, err := io.ReadFull(r, sizeBytes[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(sizeBytes[:])
+	if uint64(size) > uint64(vm.maxDataLen)+maxBlockOverhead {
+		return nil, errSnapshotBlockTooLong
+	}
+	blkBytes := make([]byte, size)
+	_, err := io.ReadFull(r, blkBytes)
+	return blkBytes, err
+}
+
+// snapshotExporter periodically writes a snapshot of the accepted chain to a
+// directory, keeping the most recent snapshots and removing older ones
+type snapshotExporter struct {
+	vm   *VM
+	dir  string
+	keep int
+
+	// Signals that a snapshot should be taken
+	ticks      <-chan time.Time
+	stopTicker func()
+	// Closed when the exporter is stopped
+	closer chan struct{}
+}
+
+// start exporting a snapshot every [interval]. If [s.ticks] is already set,
+// snapshots are exported on its ticks instead.
+func (s *snapshotExporter) start(vm *VM, dir string, keep int, interval time.Duration) {
+	s.vm = vm
+	s.dir = dir
+	s.keep = keep
+	s.closer = make(chan struct{})
+	if s.ticks == nil {
+		ticker := time.NewTicker(interval)
+		s.ticks = ticker.C
+		s.stopTicker = ticker.Stop
+	}
+	go s.run()
+}
+
+func (s *snapshotExporter) run() {
+	for {
+		select {
+		case <-s.closer:
+			if s.stopTicker != nil {
+				s.stopTicker()
+			}
+			return
+		case <-s.ticks:
+		}
+		if err := s.export(); err != nil {
+			s.vm.Ctx.Log.Error("error while exporting snapshot: %v", err)
+		}
+	}
+}
+
+// stop exporting snapshots. Must be called with the context lock held, so that
+// an export in progress doesn't read the database after the VM shuts down.
+func (s *snapshotExporter) stop() { close(s.closer) }
+
+// stopped returns true if the exporter was stopped
+func (s *snapshotExporter) stopped() bool {
+	select {
+	case <-s.closer:
+		return true
+	default:
+		return false
+	}
+}
+
+// export writes a snapshot of the accepted chain and removes old snapshots
+func (s *snapshotExporter) export() error {
+	s.vm.Ctx.Lock.Lock()
+	if s.stopped() {
+		s.vm.Ctx.Lock.Unlock()
+		return nil
+	}
+	height := s.vm.lastHeight
+	now := s.vm.clock.Time()
+	s.vm.Ctx.Lock.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	name := s.name(now)
+	// Write to a temporary file first, so a partial snapshot is never left
+	// behind under a snapshot's name
+	tmpPath := filepath.Join(s.dir, name+".tmp")
+	if err := s.writeFile(tmpPath, height); err != nil {
+		os.Remove(tmpPath)
+		if err == errSnapshotStopped {
+			return nil
+		}
+		return err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, name)); err != nil {
+		return err
+	}
+	s.vm.Ctx.Log.Debug("exported snapshot %s at height %d", name, height)
+	return s.rotate()
+}
+
+// writeFile writes a snapshot of the accepted chain up to [height] to a new
+// file at [path]
+func (s *snapshotExporter) writeFile(path string, height uint64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := s.writeChain(w, height); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeChain writes a snapshot of the accepted chain, from the genesis block to
+// the block at [height], to [w]. The snapshot is the number of blocks followed
+// by the bytes of each block, ordered by height. Accepted blocks don't change,
+// so the context lock is only held while each chunk of blocks is read.
+func (s *snapshotExporter) writeChain(w io.Writer, height uint64) error {
+	p := wrappers.Packer{MaxSize: wrappers.MaxVarIntLen}
+	p.PackVarInt(height + 1)
+	if p.Errored() {
+		return p.Err
+	}
+	if _, err := w.Write(p.Bytes); err != nil {
+		return err
+	}
+	for start := uint64(0); start <= height; start += snapshotChunkSize {
+		end := start + snapshotChunkSize - 1
+		if end > height {
+			end = height
+		}
+		chunk, err := s.readChunk(start, end)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readChunk returns the bytes of the accepted blocks at heights [start] to
+// [end], each prefixed with its length
+func (s *snapshotExporter) readChunk(start, end uint64) ([]byte, error) {
+	s.vm.Ctx.Lock.Lock()
+	defer s.vm.Ctx.Lock.Unlock()
+
+	if s.stopped() {
+		return nil, errSnapshotStopped
+	}
+	p := wrappers.Packer{MaxSize: math.MaxInt32}
+	for height := start; height <= end; height++ {
+		blk, err := s.vm.getBlockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		p.PackBytes(blk.Bytes())
+	}
+	return p.Bytes, p.Err
+}
+
+// rotate removes all but the [keep] most recent snapshots of this chain
+func (s *snapshotExporter) rotate() error {
+	snapshots, err := s.snapshots()
+	if err != nil {
+		return err
+	}
+	for len(snapshots) > s.keep {
+		if err := os.Remove(filepath.Join(s.dir, snapshots[0])); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// snapshots returns the file names of this chain's snapshots, oldest first
+func (s *snapshotExporter) snapshots() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string(nil)
+	for _, file := range files {
+		name := file.Name()
+		if strings.HasPrefix(name, s.prefix()) && strings.HasSuffix(name, snapshotExt) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// name returns the file name of the snapshot taken at [t]. Names sort in the
+// order the snapshots were taken.
+func (s *snapshotExporter) name(t time.Time) string {
+	return fmt.Sprintf("%s%020d%s", s.prefix(), t.UnixNano(), snapshotExt)
+}
+
+// prefix of the file names of this chain's snapshots. Several chains may share
+// a snapshot directory.
+func (s *snapshotExporter) prefix() string { return s.vm.Ctx.ChainID.String() + "-" }
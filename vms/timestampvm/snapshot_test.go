@@ -0,0 +1,204 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// waitForSnapshots waits until the snapshots [vm] has exported are the ones
+// taken at [times], oldest first
+func waitForSnapshots(t *testing.T, vm *VM, times ...time.Time) {
+	t.Helper()
+
+	expected := []string(nil)
+	for _, tm := range times {
+		expected = append(expected, vm.snapshots.name(tm))
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		snapshots, err := vm.snapshots.snapshots()
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		if reflect.DeepEqual(snapshots, expected) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("snapshots should have been %v but were %v", expected, snapshots)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSnapshotExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timestampvm-snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ticks := make(chan time.Time)
+	vm := &VM{
+		SnapshotInterval: time.Hour,
+		SnapshotDir:      dir,
+		SnapshotsToKeep:  2,
+	}
+	vm.snapshots.ticks = ticks
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), testGenesisData, make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+	blocks := acceptBlocks(t, vm, "first", "second")
+
+	// The exporter reads the clock with the context lock held
+	tick := func(now time.Time) {
+		vm.Ctx.Lock.Lock()
+		vm.clock.Set(now)
+		vm.Ctx.Lock.Unlock()
+		ticks <- now
+	}
+
+	// A snapshot of the accepted chain is exported once the interval elapses
+	first := time.Unix(100, 0)
+	tick(first)
+	waitForSnapshots(t, vm, first)
+
+	snapshot, err := ioutil.ReadFile(filepath.Join(dir, vm.snapshots.name(first)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := wrappers.Packer{Bytes: snapshot}
+	if numBlocks := p.UnpackVarInt(); numBlocks != 3 {
+		t.Fatalf("snapshot should have had 3 blocks but had %d", numBlocks)
+	}
+	p.UnpackBytes() // genesis block
+	for _, blk := range blocks {
+		if blkBytes := p.UnpackBytes(); !bytes.Equal(blkBytes, blk.Bytes()) {
+			t.Fatalf("snapshot should have had block %s", blk.ID())
+		}
+	}
+	if p.Errored() || p.Remaining() != 0 {
+		t.Fatalf("snapshot is malformed: %v", p.Err)
+	}
+
+	// Only the most recent snapshots are kept
+	second, third := first.Add(time.Hour), first.Add(2*time.Hour)
+	tick(second)
+	waitForSnapshots(t, vm, first, second)
+	tick(third)
+	waitForSnapshots(t, vm, second, third)
+}
+
+func TestSnapshotExportDisabled(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	if vm.exportSnapshots {
+		t.Fatal("snapshots shouldn't be exported without a snapshot directory")
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	texts := []string(nil)
+	// Export more than one chunk of blocks
+	for i := 0; i < 2*snapshotChunkSize+1; i++ {
+		texts = append(texts, fmt.Sprintf("block %d", i))
+	}
+	acceptBlocks(t, vm, texts...)
+
+	snapshot := &bytes.Buffer{}
+	s := snapshotExporter{vm: vm, closer: make(chan struct{})}
+	if err := s.writeChain(snapshot, vm.lastHeight); err != nil {
+		t.Fatal(err)
+	}
+	snapshotBytes := snapshot.Bytes()
+
+	// The snapshot's blocks are accepted by a VM with the same genesis
+	restored, _ := NewTestVM(t)
+	if err := restored.RestoreSnapshot(bytes.NewReader(snapshotBytes)); err != nil {
+		t.Fatal(err)
+	}
+	if !restored.LastAccepted().Equals(vm.LastAccepted()) {
+		t.Fatalf("last accepted should have been %s but was %s", vm.LastAccepted(), restored.LastAccepted())
+	}
+	if restored.lastHeight != vm.lastHeight {
+		t.Fatalf("last height should have been %d but was %d", vm.lastHeight, restored.lastHeight)
+	}
+	for height := uint64(0); height <= vm.lastHeight; height++ {
+		expected, err := vm.heights.Get(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blkID, err := restored.heights.Get(height); err != nil || !blkID.Equals(expected) {
+			t.Fatalf("block at height %d should have been %s but was %s (%v)", height, expected, blkID, err)
+		}
+	}
+
+	// Restoring blocks that are already accepted does nothing
+	if err := restored.RestoreSnapshot(bytes.NewReader(snapshotBytes)); err != nil {
+		t.Fatal(err)
+	}
+	if restored.lastHeight != vm.lastHeight {
+		t.Fatalf("last height should have been %d but was %d", vm.lastHeight, restored.lastHeight)
+	}
+}
+
+func TestSnapshotRestoreMismatch(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	acceptBlocks(t, vm, "first", "second")
+	snapshot := &bytes.Buffer{}
+	s := snapshotExporter{vm: vm, closer: make(chan struct{})}
+	if err := s.writeChain(snapshot, vm.lastHeight); err != nil {
+		t.Fatal(err)
+	}
+
+	forked, _ := NewTestVM(t)
+	acceptBlocks(t, forked, "other")
+	if err := forked.RestoreSnapshot(snapshot); !errors.Is(err, errSnapshotMismatch) {
+		t.Fatalf("restoring a different chain should have failed with %q but got %v", errSnapshotMismatch, err)
+	}
+}
+
+func TestSnapshotRestoreMalformed(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	acceptBlocks(t, vm, "first")
+	snapshot := &bytes.Buffer{}
+	s := snapshotExporter{vm: vm, closer: make(chan struct{})}
+	if err := s.writeChain(snapshot, vm.lastHeight); err != nil {
+		t.Fatal(err)
+	}
+	snapshotBytes := snapshot.Bytes()
+
+	restored, _ := NewTestVM(t)
+	if err := restored.RestoreSnapshot(bytes.NewReader(snapshotBytes[:len(snapshotBytes)-1])); err == nil {
+		t.Fatal("restoring a truncated snapshot should have failed")
+	}
+	if err := restored.RestoreSnapshot(bytes.NewReader(append(snapshotBytes, 0))); err != errSnapshotTrailingData {
+		t.Fatalf("restoring a snapshot with trailing data should have failed with %q but got %v", errSnapshotTrailingData, err)
+	}
+}
+
+func TestSnapshotExportStopped(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	s := snapshotExporter{vm: vm, closer: make(chan struct{})}
+	close(s.closer)
+	if err := s.writeChain(ioutil.Discard, vm.lastHeight); err != errSnapshotStopped {
+		t.Fatalf("writing a stopped exporter's chain should have failed with %q but got %v", errSnapshotStopped, err)
+	}
+}
@@ -0,0 +1,64 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"fmt"
+)
+
+// State is the stage of its lifecycle that a VM is in
+type State uint32
+
+// List of possible states, in the order they're entered
+// [Initializing] means the VM is being initialized
+// [Bootstrapping] means the chain is catching up with the network
+// [NormalOp] means consensus is running, so blocks can be built
+const (
+	Initializing State = iota
+	Bootstrapping
+	NormalOp
+)
+
+func (s State) String() string {
+	switch s {
+	case Initializing:
+		return "Initializing"
+	case Bootstrapping:
+		return "Bootstrapping"
+	case NormalOp:
+		return "NormalOp"
+	default:
+		return "Invalid state"
+	}
+}
+
+// CurrentState returns the stage of its lifecycle the VM is in
+func (vm *VM) CurrentState() State { return vm.state }
+
+// setState moves the VM to [state], which must be the state after the current
+// one
+func (vm *VM) setState(state State) error {
+	if state != vm.state+1 || state > NormalOp {
+		return fmt.Errorf("can't move from state %s to %s", vm.state, state)
+	}
+	vm.Ctx.Log.Debug("timestampvm moving from state %s to %s", vm.state, state)
+	vm.state = state
+	return nil
+}
+
+// requireState returns an error if the VM isn't in [state]
+func (vm *VM) requireState(state State) error {
+	if vm.state != state {
+		return fmt.Errorf("operation requires state %s but the VM is in state %s", state, vm.state)
+	}
+	return nil
+}
+
+// Bootstrapped is called once the chain has finished bootstrapping. Blocks can
+// only be built and proposed after this is called.
+func (vm *VM) Bootstrapped() {
+	if err := vm.setState(NormalOp); err != nil {
+		vm.Ctx.Log.Error("error while finishing bootstrapping: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+func TestBuildBlockRequiresNormalOp(t *testing.T) {
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if state := vm.CurrentState(); state != Bootstrapping {
+		t.Fatalf("VM should be %s after initialization but is %s", Bootstrapping, state)
+	}
+
+	// Blocks can't be proposed or built while bootstrapping
+	if err := vm.proposeBlock([]byte{1}); err == nil {
+		t.Fatalf("Should have failed to propose a block while bootstrapping")
+	}
+	data := formatting.CB58{Bytes: make([]byte, defaultMaxDataLen)}
+	if err := (&Service{vm}).ProposeBlock(nil, &ProposeBlockArgs{Data: data.String()}, &ProposeBlockReply{}); err == nil {
+		t.Fatalf("ProposeBlock should have failed while bootstrapping")
+	}
+	if _, err := vm.BuildBlock(); err == nil {
+		t.Fatalf("Should have failed to build a block while bootstrapping")
+	}
+
+	// In an actual execution, the engine would set the preference
+	vm.SetPreference(vm.LastAccepted())
+	vm.Bootstrapped()
+	if state := vm.CurrentState(); state != NormalOp {
+		t.Fatalf("VM should be %s after bootstrapping but is %s", NormalOp, state)
+	}
+	if err := vm.proposeBlock([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.BuildBlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetStateTransitions(t *testing.T) {
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := vm.setState(Initializing); err == nil {
+		t.Fatalf("Shouldn't be able to move back to %s", Initializing)
+	}
+	if err := vm.setState(Bootstrapping); err == nil {
+		t.Fatalf("Shouldn't be able to move from %s to itself", Bootstrapping)
+	}
+	if err := vm.setState(NormalOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.setState(NormalOp + 1); err == nil {
+		t.Fatalf("Shouldn't be able to move past %s", NormalOp)
+	}
+}
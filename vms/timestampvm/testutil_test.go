@@ -0,0 +1,36 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+// testGenesisData is the genesis data of VMs created by NewTestVM
+var testGenesisData = []byte{0, 0, 0, 0, 0}
+
+// NewTestVM returns a VM initialized with an in-memory database and
+// testGenesisData, along with the channel it sends messages to the engine on.
+// The VM is bootstrapped and prefers the genesis block, as it would be after
+// the engine started. It's shut down when the test finishes.
+func NewTestVM(t *testing.T) (*VM, chan common.Message) {
+	t.Helper()
+
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	toEngine := make(chan common.Message, 1)
+
+	vm := &VM{}
+	if err := vm.Initialize(ctx, memdb.New(), testGenesisData, toEngine, nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+	vm.SetPreference(vm.LastAccepted())
+	t.Cleanup(vm.Shutdown)
+	return vm, toEngine
+}
@@ -0,0 +1,14 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// CanReveal returns true if the data committed to by [tl] may be revealed now.
+// The local clock is allowed the same skew as block timestamps are.
+func (vm *VM) CanReveal(tl wrappers.TimeLock) bool {
+	return tl.CanReveal(vm.clock.Time(), vm.MaxFutureDrift)
+}
@@ -0,0 +1,26 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+func TestCanRevealMaxFutureDrift(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	now := time.Unix(1000, 0)
+	vm.clock.Set(now)
+
+	tl := wrappers.TimeLock{UnlockTime: now.Add(vm.MaxFutureDrift).Unix()}
+	if !vm.CanReveal(tl) {
+		t.Fatal("should be able to reveal within the VM's max future drift")
+	}
+	tl.UnlockTime++
+	if vm.CanReveal(tl) {
+		t.Fatal("shouldn't be able to reveal beyond the VM's max future drift")
+	}
+}
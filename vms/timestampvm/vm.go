@@ -4,23 +4,54 @@
 package timestampvm
 
 import (
+	"bytes"
 	"errors"
+	"math"
+	"net/http"
 	"time"
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/vms/components/codec"
 	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/state"
 )
 
-const dataLen = 32
+const (
+	// defaultMaxDataLen is the maximum length of a block's data unless the
+	// genesis raises it. It's the fixed length blocks used to have.
+	defaultMaxDataLen = 32
+
+	// defaultProposeTimeout is how long a synchronous proposal waits for its
+	// block to be accepted if [VM.ProposeTimeout] isn't set
+	defaultProposeTimeout = 30 * time.Second
+
+	// defaultMaxFutureDrift is how far ahead of local time a block's timestamp
+	// may be if [VM.MaxFutureDrift] isn't set
+	defaultMaxFutureDrift = 10 * time.Second
+
+	// defaultMaxMempoolSize is the maximum number of pieces of data in the
+	// mempool if [VM.MaxMempoolSize] isn't set
+	defaultMaxMempoolSize = 1024
+
+	// defaultMaxPageSize is the maximum number of items returned by a range
+	// query if [VM.MaxPageSize] isn't set
+	defaultMaxPageSize = 1024
+)
 
 var (
 	errNoPendingBlocks = errors.New("there is no block to propose")
-	errBadGenesisBytes = errors.New("genesis data should be bytes (max length 32)")
+	errBadGenesisBytes = errors.New("genesis bytes are malformed or their data is longer than the max data length")
+	errReorgTooDeep    = errors.New("block would replace more accepted blocks than the max reorg depth")
+	errTooFewPeers     = errors.New("insufficient peers to accept writes")
+	errMempoolTooSmall = errors.New("data is larger than the mempool")
+	errMempoolFull     = errors.New("mempool is full, try again later")
+	errClockBehind     = errors.New("local clock is too far behind the preferred block's timestamp to build a block")
 )
 
 // VM implements the snowman.VM interface
@@ -29,8 +60,120 @@ var (
 type VM struct {
 	core.SnowmanVM
 	codec codec.Codec
+	// The stage of its lifecycle the VM is in
+	state State
+
+	// Maximum length of a block's data. Blocks with longer data fail
+	// verification, so it's read from the genesis bytes. See Genesis.
+	maxDataLen int
+
+	// CodecName is the name of the registered codec used to serialize blocks.
+	// If empty, DefaultCodec is used.
+	CodecName string
+
 	// Proposed pieces of data that haven't been put into a block and proposed yet
-	mempool [][dataLen]byte
+	mempool [][]byte
+	// MaxMempoolSize is the maximum number of pieces of data in the mempool.
+	// Proposals are rejected while it's full.
+	// If 0, defaultMaxMempoolSize is used.
+	MaxMempoolSize int
+	// MaxMempoolBytes is the maximum total size of the data in the mempool.
+	// When a proposal exceeds it, the oldest data is evicted, and synchronous
+	// proposals waiting for it fail so that they can be retried.
+	// If 0, the mempool isn't bounded.
+	MaxMempoolBytes int
+	// RequeueRejected re-adds the data of rejected blocks to the front of the
+	// mempool, unless a block with the same data was accepted in its place.
+	// Otherwise, the data of rejected blocks is dropped.
+	RequeueRejected bool
+
+	// GenesisTransform, if non-nil, is applied to the genesis data before the
+	// genesis block is created. It can be used to canonicalize the genesis
+	// data, or to reject it by returning an error.
+	// If nil, the genesis data is used as is.
+	GenesisTransform func([]byte) ([]byte, error)
+
+	// CompactionThreshold is the number of blocks that must be pruned from the
+	// database before it is compacted.
+	// If 0, the database is never compacted.
+	CompactionThreshold int
+	// Number of blocks pruned since the database was last compacted
+	numPruned int
+
+	// EnableSearch, if true, maintains an index of the data of accepted blocks
+	// that allows them to be searched with SearchBlocks.
+	EnableSearch bool
+	search       searchIndex
+
+	// Maps the height of each accepted block to its ID
+	heights heightIndex
+	// Height of the last accepted block
+	lastHeight uint64
+
+	// ProposeTimeout is how long a synchronous proposal waits for its block to
+	// be accepted. If 0, defaultProposeTimeout is used.
+	ProposeTimeout time.Duration
+	// Maps data to the channels of the synchronous proposals waiting for a
+	// block containing the data to be accepted, in the order they were made
+	acceptWaiters map[string][]chan ids.ID
+
+	// MaxReorgDepth is the maximum number of accepted blocks that accepting a
+	// block may replace. A block that would replace more fails verification.
+	// If 0, the depth isn't limited.
+	MaxReorgDepth uint64
+
+	// Migrations upgrade the database from older schema versions, in order,
+	// after the VM's own migrations have run. The current schema version is
+	// the total number of migrations, and the i'th migration upgrades a
+	// database from version i to version i+1.
+	// A new database is created at the current version.
+	Migrations []Migration
+
+	// ProposeRate is the average number of blocks per second that may be
+	// proposed through the API, in bursts of up to ProposeBurst blocks.
+	// If 0, proposals aren't rate limited. ProposeBurst is at least 1.
+	ProposeRate  float64
+	ProposeBurst float64
+	// Limits the rate of proposals if [ProposeRate] > 0
+	proposeLimiter *rateLimiter
+	// Notifies the engine once the local clock has caught up with the
+	// preferred block, if building a block was refused because it was behind
+	buildRetry *time.Timer
+
+	// MaxFutureDrift is how far ahead of local time a block's timestamp may
+	// be. Blocks stamped further in the future fail verification.
+	// If 0, defaultMaxFutureDrift is used.
+	MaxFutureDrift time.Duration
+	// Local time, which built blocks are stamped with
+	clock timer.Clock
+
+	// MaxPageSize is the maximum number of items returned by a range query
+	// through the API. Larger requests are truncated, and the reply says where
+	// the next page starts. If 0, defaultMaxPageSize is used.
+	MaxPageSize int
+
+	// MinPeersForWrites is the number of peers the node must be connected to
+	// for blocks to be proposed through the API. Blocks proposed while the
+	// node is on a minority partition are likely to be orphaned. Reads are
+	// always allowed. If 0, proposals aren't gated.
+	MinPeersForWrites int
+
+	// DBEncryptionKey, if non-empty, is the secret that database values are
+	// encrypted with at rest. Keys aren't encrypted. Encryption can't be
+	// turned on or off, or the secret changed, for an existing database.
+	DBEncryptionKey []byte
+
+	// SnapshotInterval is how often a snapshot of the accepted chain is
+	// exported to SnapshotDir. The SnapshotsToKeep most recent snapshots are
+	// kept and older ones are removed. If SnapshotInterval is 0 or SnapshotDir
+	// is empty, snapshots aren't exported. If SnapshotsToKeep is 0,
+	// defaultSnapshotsToKeep is used.
+	SnapshotInterval time.Duration
+	SnapshotDir      string
+	SnapshotsToKeep  int
+	snapshots        snapshotExporter
+	// True if [snapshots] was started and hasn't been stopped
+	exportSnapshots bool
 }
 
 // Initialize this vm
@@ -46,26 +189,67 @@ func (vm *VM) Initialize(
 	toEngine chan<- common.Message,
 	_ []*common.Fx,
 ) error {
+	vm.state = Initializing
+	if vm.MaxFutureDrift == 0 {
+		vm.MaxFutureDrift = defaultMaxFutureDrift
+	}
+	if vm.MaxPageSize == 0 {
+		vm.MaxPageSize = defaultMaxPageSize
+	}
+	if vm.MaxMempoolSize == 0 {
+		vm.MaxMempoolSize = defaultMaxMempoolSize
+	}
+	genesisData, maxDataLen, err := parseGenesis(genesisData)
+	if err != nil {
+		ctx.Log.Error("error while parsing genesis bytes: %v", err)
+		return err
+	}
+	vm.maxDataLen = maxDataLen
+	codecName := vm.CodecName
+	if codecName == "" {
+		codecName = DefaultCodec
+	}
+	c, err := newCodec(codecName)
+	if err != nil {
+		ctx.Log.Error("error creating codec: %v", err)
+		return err
+	}
+	vm.codec = c
+	if len(vm.DBEncryptionKey) > 0 {
+		encDB, err := openEncryptedDB(vm.DBEncryptionKey, db)
+		if err != nil {
+			ctx.Log.Error("error opening encrypted database: %v", err)
+			return err
+		}
+		db = encDB
+	}
 	if err := vm.SnowmanVM.Initialize(ctx, db, vm.ParseBlock, toEngine); err != nil {
 		ctx.Log.Error("error initializing SnowmanVM: %v", err)
 		return err
 	}
-	vm.codec = codec.NewDefault()
+	vm.heights.Initialize(vm.DB)
+	if vm.EnableSearch {
+		vm.search.Initialize(vm.DB)
+	}
 
 	// If database is empty, create it using the provided genesis data
 	if !vm.DBInitialized() {
-		if len(genesisData) > dataLen {
-			return errBadGenesisBytes
+		if vm.GenesisTransform != nil {
+			transformedData, err := vm.GenesisTransform(genesisData)
+			if err != nil {
+				ctx.Log.Error("error while transforming genesis data: %v", err)
+				return err
+			}
+			genesisData = transformedData
 		}
 
-		// genesisData is a byte slice but each block contains an byte array
-		// Take the first [dataLen] bytes from genesisData and put them in an array
-		var genesisDataArr [dataLen]byte
-		copy(genesisDataArr[:], genesisData)
+		if len(genesisData) > vm.maxDataLen {
+			return errBadGenesisBytes
+		}
 
 		// Create the genesis block
 		// Timestamp of genesis block is 0. It has no parent.
-		genesisBlock, err := vm.NewBlock(ids.Empty, genesisDataArr, time.Unix(0, 0))
+		genesisBlock, err := vm.NewBlock(ids.Empty, padGenesisData(genesisData), time.Unix(0, 0))
 		if err != nil {
 			vm.Ctx.Log.Error("error while creating genesis block: %v", err)
 			return err
@@ -81,23 +265,82 @@ func (vm *VM) Initialize(
 		genesisBlock.Accept()
 
 		vm.SetDBInitialized()
+		if err := vm.putSchemaVersion(uint32(len(vm.migrations()))); err != nil {
+			vm.Ctx.Log.Error("error while recording schema version: %v", err)
+			return err
+		}
 
 		// Flush VM's database to underlying db
 		if err := vm.DB.Commit(); err != nil {
 			vm.Ctx.Log.Error("error while commiting db: %v", err)
 			return err
 		}
+	} else {
+		if err := vm.migrate(); err != nil {
+			vm.Ctx.Log.Error("error while migrating database: %v", err)
+			return err
+		}
+		if err := vm.loadHeightIndex(); err != nil {
+			vm.Ctx.Log.Error("error while loading height index: %v", err)
+			return err
+		}
+
+		// Search may have been enabled after blocks were accepted
+		if vm.EnableSearch {
+			if empty, err := vm.search.Empty(); err != nil {
+				return err
+			} else if empty {
+				if err := vm.RebuildSearchIndex(); err != nil {
+					vm.Ctx.Log.Error("error while building search index: %v", err)
+					return err
+				}
+			}
+		}
 	}
-	return nil
+	if vm.ProposeRate > 0 {
+		vm.proposeLimiter = &rateLimiter{}
+		burst := math.Max(1, vm.ProposeBurst)
+		if err := vm.proposeLimiter.Initialize(vm.ProposeRate, burst, vm.DB.GetDatabase()); err != nil {
+			vm.Ctx.Log.Error("error while loading propose rate limiter: %v", err)
+			return err
+		}
+	}
+	if vm.SnapshotInterval > 0 && vm.SnapshotDir != "" {
+		keep := vm.SnapshotsToKeep
+		if keep == 0 {
+			keep = defaultSnapshotsToKeep
+		}
+		vm.snapshots.start(vm, vm.SnapshotDir, keep, vm.SnapshotInterval)
+		vm.exportSnapshots = true
+	}
+	return vm.setState(Bootstrapping)
+}
+
+// Shutdown this vm
+func (vm *VM) Shutdown() {
+	if vm.buildRetry != nil {
+		vm.buildRetry.Stop()
+	}
+	if vm.exportSnapshots {
+		vm.snapshots.stop()
+		vm.exportSnapshots = false
+	}
+	vm.SnowmanVM.Shutdown()
 }
 
 // CreateHandlers returns a map where:
-// Keys: The path extension for this VM's API (empty in this case)
+// Keys: The path extension for this VM's API
 // Values: The handler for the API
+// The JSON-RPC service is at the empty extension, and blocks can also be
+// fetched with GET requests to /block/{id}
 func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
 	handler := vm.NewHandler("timestamp", &Service{vm})
 	return map[string]*common.HTTPHandler{
 		"": handler,
+		blockRoute: {
+			Methods: []string{http.MethodGet},
+			Handler: http.HandlerFunc(vm.serveBlock),
+		},
 	}
 }
 
@@ -107,12 +350,40 @@ func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
 // We return nil because this VM has no static API
 func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler { return nil }
 
-// BuildBlock returns a block that this vm wants to add to consensus
+// BuildBlock returns a block that this vm wants to add to consensus. Blocks
+// can't be built until the chain has finished bootstrapping.
+// If the local clock is so far behind the preferred block that the new block's
+// timestamp would fail Verify, no block is built until the clock catches up.
 func (vm *VM) BuildBlock() (snowman.Block, error) {
+	if err := vm.requireState(NormalOp); err != nil {
+		return nil, err
+	}
 	if len(vm.mempool) == 0 { // There is no block to be built
 		return nil, errNoPendingBlocks
 	}
 
+	// The new block's timestamp can't be before its parent's, even if the
+	// local clock went backwards
+	parent, err := vm.getBlock(vm.Preferred())
+	if err != nil {
+		return nil, err
+	}
+	timestamp := vm.clock.Time().Unix()
+	minTimestamp := parent.Timestamp
+	if vm.Ctx.Feature(strictTimestamps) {
+		minTimestamp++
+	}
+	if timestamp < minTimestamp {
+		timestamp = minTimestamp
+	}
+	if behind := time.Unix(timestamp, 0).Sub(vm.clock.Time().Add(vm.MaxFutureDrift)); behind > 0 {
+		if vm.buildRetry != nil {
+			vm.buildRetry.Stop()
+		}
+		vm.buildRetry = time.AfterFunc(behind, vm.NotifyBlockReady)
+		return nil, errClockBehind
+	}
+
 	// Get the value to put in the new block
 	value := vm.mempool[0]
 	vm.mempool = vm.mempool[1:]
@@ -124,7 +395,7 @@ func (vm *VM) BuildBlock() (snowman.Block, error) {
 	}
 
 	// Build the block
-	block, err := vm.NewBlock(vm.Preferred(), value, time.Now())
+	block, err := vm.NewBlock(vm.Preferred(), value, time.Unix(timestamp, 0))
 	if err != nil {
 		return nil, err
 	}
@@ -135,18 +406,196 @@ func (vm *VM) BuildBlock() (snowman.Block, error) {
 // Then it notifies the consensus engine
 // that a new block is ready to be added to consensus
 // (namely, a block with data [data])
-func (vm *VM) proposeBlock(data [dataLen]byte) {
-	vm.mempool = append(vm.mempool, data)
+// Blocks can't be proposed until the chain has finished bootstrapping, or
+// while the mempool is full.
+func (vm *VM) proposeBlock(data []byte) error {
+	return vm.proposeBlocks([][]byte{data})
+}
+
+// proposeBlocks appends all of [data] to [p.mempool], or none of it if any of
+// it can't be proposed
+func (vm *VM) proposeBlocks(data [][]byte) error {
+	if err := vm.requireState(NormalOp); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	// Data evicted by the rest of the batch wouldn't be proposed
+	if vm.MaxMempoolBytes > 0 && totalLen(data) > vm.MaxMempoolBytes {
+		return errMempoolTooSmall
+	}
+	if len(vm.mempool)+len(data) > vm.MaxMempoolSize {
+		return errMempoolFull
+	}
+	vm.mempool = append(vm.mempool, data...)
+	vm.evictMempool()
 	vm.NotifyBlockReady()
+	return nil
+}
+
+// mempoolBytes returns the total size of the data in the mempool
+func (vm *VM) mempoolBytes() int { return totalLen(vm.mempool) }
+
+// totalLen returns the sum of the lengths of [data]
+func totalLen(data [][]byte) int {
+	n := 0
+	for _, d := range data {
+		n += len(d)
+	}
+	return n
+}
+
+// evictMempool removes the oldest data from the mempool until it's no larger
+// than [vm.MaxMempoolBytes]
+func (vm *VM) evictMempool() {
+	if vm.MaxMempoolBytes <= 0 {
+		return
+	}
+	for size := vm.mempoolBytes(); size > vm.MaxMempoolBytes; {
+		data := vm.mempool[0]
+		vm.mempool = vm.mempool[1:]
+		size -= len(data)
+		vm.Ctx.Log.Debug("evicted data %x from the mempool", data)
+		vm.notifyEvicted(data)
+	}
+}
+
+// releaseRejected handles the data of [blk], which was just rejected. If a
+// block with the same data was accepted in its place, the data has already been
+// timestamped and is dropped. Otherwise, it's re-added to the mempool if
+// [vm.RequeueRejected] and the mempool isn't full, or dropped and the
+// synchronous proposal waiting for it is notified.
+func (vm *VM) releaseRejected(blk *Block) {
+	duplicate, err := vm.acceptedInPlace(blk)
+	if err != nil {
+		vm.Ctx.Log.Error("error while checking the block accepted in place of block %s: %v", blk.ID(), err)
+	}
+	switch {
+	case duplicate:
+		vm.Ctx.Log.Debug("rejected block %s has the same data as the block accepted in its place", blk.ID())
+	case vm.RequeueRejected && len(vm.mempool) < vm.MaxMempoolSize:
+		vm.mempool = append([][]byte{blk.Data}, vm.mempool...)
+		vm.evictMempool()
+		vm.NotifyBlockReady()
+	default:
+		vm.Ctx.Log.Debug("dropped data %x of rejected block %s", blk.Data, blk.ID())
+		vm.notifyEvicted(blk.Data)
+	}
+}
+
+// acceptedInPlace returns true if the accepted block with the same parent as
+// [blk] has the same data as [blk]. Returns false if [blk]'s parent isn't
+// accepted.
+func (vm *VM) acceptedInPlace(blk *Block) (bool, error) {
+	if vm.State.GetStatus(vm.DB, blk.ParentID()) != choices.Accepted {
+		return false, nil
+	}
+	parent, err := vm.getBlock(blk.ParentID())
+	if err != nil {
+		return false, err
+	}
+	height, err := vm.acceptedHeight(parent)
+	if err != nil {
+		return false, err
+	}
+	sibling, err := vm.getBlockByHeight(height + 1)
+	if err == errUnknownHeight {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return bytes.Equal(sibling.Data, blk.Data), nil
+}
+
+// checkWritable returns an error if the node isn't connected to enough peers
+// for blocks to be proposed through the API
+func (vm *VM) checkWritable() error {
+	if vm.MinPeersForWrites <= 0 {
+		return nil
+	}
+	numPeers := 0
+	if vm.Ctx.Peers != nil {
+		numPeers = vm.Ctx.Peers.Len()
+	}
+	if numPeers < vm.MinPeersForWrites {
+		return errTooFewPeers
+	}
+	return nil
+}
+
+// awaitAcceptance returns a channel that receives the ID of the next accepted
+// block containing [data] that isn't already awaited, or ids.Empty if [data] is
+// evicted from the mempool first
+func (vm *VM) awaitAcceptance(data []byte) chan ids.ID {
+	if vm.acceptWaiters == nil {
+		vm.acceptWaiters = make(map[string][]chan ids.ID)
+	}
+	accepted := make(chan ids.ID, 1)
+	vm.acceptWaiters[string(data)] = append(vm.acceptWaiters[string(data)], accepted)
+	return accepted
+}
+
+// cancelAwait stops [accepted] from receiving the ID of an accepted block
+// containing [data]
+func (vm *VM) cancelAwait(data []byte, accepted chan ids.ID) {
+	waiters := vm.acceptWaiters[string(data)]
+	for i, waiter := range waiters {
+		if waiter == accepted {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(vm.acceptWaiters, string(data))
+	} else {
+		vm.acceptWaiters[string(data)] = waiters
+	}
+}
+
+// notifyAccepted sends the ID of [blk], which was just accepted, to the oldest
+// synchronous proposal waiting for its data
+func (vm *VM) notifyAccepted(blk *Block) {
+	waiters := vm.acceptWaiters[string(blk.Data)]
+	if len(waiters) == 0 {
+		return
+	}
+	waiters[0] <- blk.ID()
+	vm.cancelAwait(blk.Data, waiters[0])
+}
+
+// notifyEvicted sends ids.Empty to the oldest synchronous proposal waiting for
+// [data], which was just evicted from the mempool or dropped with a rejected
+// block
+func (vm *VM) notifyEvicted(data []byte) {
+	waiters := vm.acceptWaiters[string(data)]
+	if len(waiters) == 0 {
+		return
+	}
+	waiters[0] <- ids.Empty
+	vm.cancelAwait(data, waiters[0])
+}
+
+// proposeTimeout returns how long a synchronous proposal waits for its block
+// to be accepted
+func (vm *VM) proposeTimeout() time.Duration {
+	if vm.ProposeTimeout == 0 {
+		return defaultProposeTimeout
+	}
+	return vm.ProposeTimeout
 }
 
 // ParseBlock parses [bytes] to a snowman.Block
 // This function is used by the vm's state to unmarshal blocks saved in state
+// Blocks saved before blocks started with a codec version are re-encoded by a
+// migration when the database is opened, so they never reach ParseBlock.
 func (vm *VM) ParseBlock(bytes []byte) (snowman.Block, error) {
-	block := &Block{}
-	err := vm.codec.Unmarshal(bytes, block)
+	block := &Block{vm: vm}
+	if err := vm.codec.Unmarshal(bytes, block); err != nil {
+		return nil, err
+	}
 	block.Initialize(bytes, &vm.SnowmanVM)
-	return block, err
+	return block, nil
 }
 
 // NewBlock returns a new Block where:
@@ -154,11 +603,12 @@ func (vm *VM) ParseBlock(bytes []byte) (snowman.Block, error) {
 // - the block's data is [data]
 // - the block's timestamp is [timestamp]
 // The block is persisted in storage
-func (vm *VM) NewBlock(parentID ids.ID, data [dataLen]byte, timestamp time.Time) (*Block, error) {
+func (vm *VM) NewBlock(parentID ids.ID, data []byte, timestamp time.Time) (*Block, error) {
 	block := &Block{
 		Block:     core.NewBlock(parentID),
 		Data:      data,
 		Timestamp: timestamp.Unix(),
+		vm:        vm,
 	}
 
 	blockBytes, err := vm.codec.Marshal(block)
@@ -170,3 +620,65 @@ func (vm *VM) NewBlock(parentID ids.ID, data [dataLen]byte, timestamp time.Time)
 
 	return block, nil
 }
+
+// checkReorgDepth returns an error if [blk] builds on an accepted block that
+// is more than [vm.MaxReorgDepth] blocks behind the last accepted block.
+// Snowman never reorgs accepted blocks, so this only guards against building on
+// a stale accepted ancestor. The depth is measured from [blk]'s closest accepted
+// ancestor, so [blk] may build on blocks that are still processing.
+func (vm *VM) checkReorgDepth(blk *Block) error {
+	if vm.MaxReorgDepth == 0 || blk.ParentID().Equals(ids.Empty) {
+		return nil
+	}
+	ancestor, err := vm.getBlock(blk.ParentID())
+	if err != nil {
+		return err
+	}
+	for ancestor.Status() != choices.Accepted {
+		if ancestor, err = vm.getBlock(ancestor.ParentID()); err != nil {
+			return err
+		}
+	}
+	height, err := vm.acceptedHeight(ancestor)
+	if err != nil {
+		return err
+	}
+	if vm.lastHeight-height > vm.MaxReorgDepth {
+		return errReorgTooDeep
+	}
+	return nil
+}
+
+// pruneBlock removes the block with ID [blkID] from the database. The block's
+// status is kept. Each time [vm.CompactionThreshold] blocks have been pruned,
+// the database is compacted to discard the deleted blocks.
+func (vm *VM) pruneBlock(blkID ids.ID) error {
+	if err := vm.State.Put(vm.DB, state.BlockTypeID, blkID, nil); err != nil {
+		return err
+	}
+
+	vm.numPruned++
+	if vm.CompactionThreshold == 0 || vm.numPruned < vm.CompactionThreshold {
+		return nil
+	}
+	vm.numPruned = 0
+
+	// Flush the deletions to the underlying database before compacting it
+	if err := vm.DB.Commit(); err != nil {
+		return err
+	}
+	return vm.DB.Compact(nil, nil)
+}
+
+// getBlock returns the block with ID [blkID]
+func (vm *VM) getBlock(blkID ids.ID) (*Block, error) {
+	blkIntf, err := vm.GetBlock(blkID)
+	if err != nil {
+		return nil, err
+	}
+	blk, ok := blkIntf.(*Block)
+	if !ok {
+		return nil, errDatabase
+	}
+	return blk, nil
+}
@@ -4,12 +4,21 @@
 package timestampvm
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/formatting"
 )
@@ -20,11 +29,11 @@ var blockchainID = ids.NewID([32]byte{1, 2, 3})
 // * Parent with ID [parentID]
 // * Data [expectedData]
 // * Verify() returns nil iff passesVerify == true
-func assertBlock(block *Block, parentID ids.ID, expectedData [dataLen]byte, passesVerify bool) error {
+func assertBlock(block *Block, parentID ids.ID, expectedData []byte, passesVerify bool) error {
 	if !block.ParentID().Equals(parentID) {
 		return fmt.Errorf("expect parent ID to be %s but was %s", parentID, block.ParentID())
 	}
-	if block.Data != expectedData {
+	if !bytes.Equal(block.Data, expectedData) {
 		return fmt.Errorf("expected data to be %v but was %v", expectedData, block.Data)
 	}
 	if block.Verify() != nil && passesVerify {
@@ -69,31 +78,22 @@ func TestGenesis(t *testing.T) {
 	}
 
 	// Verify that the genesis block has the data we expect
-	if err := assertBlock(genesisBlock, ids.Empty, [32]byte{0, 0, 0, 0, 0}, true); err != nil {
+	if err := assertBlock(genesisBlock, ids.Empty, padGenesisData([]byte{0, 0, 0, 0, 0}), true); err != nil {
 		t.Fatal(err)
 	}
 }
 
 func TestHappyPath(t *testing.T) {
-	// Initialize the vm
-	db := memdb.New()
-	msgChan := make(chan common.Message, 1)
-	vm := &VM{}
-	ctx := snow.DefaultContextTest()
-	ctx.ChainID = blockchainID
-	if err := vm.Initialize(ctx, db, []byte{0, 0, 0, 0, 0}, msgChan, nil); err != nil {
-		t.Fatal(err)
-	}
+	vm, msgChan := NewTestVM(t)
+	ctx := vm.Ctx
 
 	genesisBlock, err := vm.GetBlock(vm.LastAccepted())
 	if err != nil {
 		t.Fatal("could not get genesis block")
 	}
-	// in an actual execution, the engine would set the preference
-	vm.SetPreference(genesisBlock.ID())
 
 	ctx.Lock.Lock()
-	vm.proposeBlock([dataLen]byte{0, 0, 0, 0, 1}) // propose a value
+	vm.proposeBlock([]byte{0, 0, 0, 0, 1}) // propose a value
 	ctx.Lock.Unlock()
 
 	select { // assert there is a pending tx message to the engine
@@ -127,11 +127,11 @@ func TestHappyPath(t *testing.T) {
 		t.Fatal("genesis block should be type *Block")
 	}
 	// Assert the block we accepted has the data we expect
-	if err := assertBlock(block2, genesisBlock.ID(), [dataLen]byte{0, 0, 0, 0, 1}, true); err != nil {
+	if err := assertBlock(block2, genesisBlock.ID(), []byte{0, 0, 0, 0, 1}, true); err != nil {
 		t.Fatal(err)
 	}
 
-	vm.proposeBlock([dataLen]byte{0, 0, 0, 0, 2}) // propose a block
+	vm.proposeBlock([]byte{0, 0, 0, 0, 2}) // propose a block
 	ctx.Lock.Unlock()
 
 	select { // verify there is a pending tx message to the engine
@@ -166,7 +166,7 @@ func TestHappyPath(t *testing.T) {
 		t.Fatal("genesis block should be type *Block")
 	}
 	// Assert the block we accepted has the data we expect
-	if err := assertBlock(block3, snowmanBlock2.ID(), [dataLen]byte{0, 0, 0, 0, 2}, true); err != nil {
+	if err := assertBlock(block3, snowmanBlock2.ID(), []byte{0, 0, 0, 0, 2}, true); err != nil {
 		t.Fatal(err)
 	}
 
@@ -192,18 +192,909 @@ func TestMakeStringFrom32Bytes(t *testing.T) {
 }
 
 func TestService(t *testing.T) {
-	// Initialize the vm
-	db := memdb.New()
-	msgChan := make(chan common.Message, 1)
+	vm, _ := NewTestVM(t)
+
+	service := Service{vm}
+	if err := service.GetBlock(nil, &GetBlockArgs{}, &GetBlockReply{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenesisTransform(t *testing.T) {
+	trim := func(genesisData []byte) ([]byte, error) {
+		return bytes.TrimSpace(genesisData), nil
+	}
+
+	genesisIDs := []ids.ID(nil)
+	for _, genesisData := range [][]byte{[]byte("genesis"), []byte("  genesis\n")} {
+		vm := &VM{GenesisTransform: trim}
+		ctx := snow.DefaultContextTest()
+		ctx.ChainID = blockchainID
+		if err := vm.Initialize(ctx, memdb.New(), genesisData, make(chan common.Message, 1), nil); err != nil {
+			t.Fatal(err)
+		}
+
+		genesisBlock, err := vm.GetBlock(vm.LastAccepted())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := assertBlock(genesisBlock.(*Block), ids.Empty, padGenesisData([]byte("genesis")), true); err != nil {
+			t.Fatal(err)
+		}
+		genesisIDs = append(genesisIDs, genesisBlock.ID())
+	}
+
+	if !genesisIDs[0].Equals(genesisIDs[1]) {
+		t.Fatalf("expected both genesis blocks to have the same ID but got %s and %s", genesisIDs[0], genesisIDs[1])
+	}
+}
+
+func TestGenesisTransformRejects(t *testing.T) {
+	errRejected := errors.New("rejected genesis")
+	vm := &VM{GenesisTransform: func([]byte) ([]byte, error) { return nil, errRejected }}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != errRejected {
+		t.Fatalf("expected Initialize to fail with %s but got %v", errRejected, err)
+	}
+}
+
+func TestGenesisMaxDataLen(t *testing.T) {
+	raised, err := Genesis([]byte("genesis"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		genesisBytes []byte
+		genesisData  []byte
+		maxDataLen   int
+		err          error
+	}{
+		{"32 bytes", bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{1}, 32), defaultMaxDataLen, nil},
+		// Short genesis data is padded, as it was when blocks held exactly
+		// 32 bytes of data, so that the genesis block doesn't change
+		{"short", []byte("genesis"), padGenesisData([]byte("genesis")), defaultMaxDataLen, nil},
+		{"empty", nil, make([]byte, 32), defaultMaxDataLen, nil},
+		{"too long", bytes.Repeat([]byte{1}, 33), nil, 0, errBadGenesisBytes},
+		{"raised max", raised, padGenesisData([]byte("genesis")), 64, nil},
+		{"max too low", append(make([]byte, 32), 0, 0, 0, 31), nil, 0, errBadGenesisBytes},
+		{"trailing bytes", append(raised, 0), nil, 0, errBadGenesisBytes},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vm := &VM{}
+			ctx := snow.DefaultContextTest()
+			ctx.ChainID = blockchainID
+			err := vm.Initialize(ctx, memdb.New(), test.genesisBytes, make(chan common.Message, 1), nil)
+			if err != test.err {
+				t.Fatalf("expected Initialize to return %v but got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+			defer vm.Shutdown()
+
+			if vm.maxDataLen != test.maxDataLen {
+				t.Fatalf("max data length should have been %d but was %d", test.maxDataLen, vm.maxDataLen)
+			}
+			genesisBlock, err := vm.GetBlock(vm.LastAccepted())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := assertBlock(genesisBlock.(*Block), ids.Empty, test.genesisData, true); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestGenesisBytes(t *testing.T) {
+	// The genesis bytes of a chain with the default max data length are just
+	// the genesis data, as they used to be
+	if genesisBytes, err := Genesis([]byte("genesis"), defaultMaxDataLen); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(genesisBytes, []byte("genesis")) {
+		t.Fatalf("genesis bytes should have been the genesis data but were %x", genesisBytes)
+	}
+	if _, err := Genesis(make([]byte, defaultMaxDataLen+1), 64); err != errBadGenesisBytes {
+		t.Fatalf("expected %s but got %v", errBadGenesisBytes, err)
+	}
+	if _, err := Genesis(nil, defaultMaxDataLen-1); err != errBadGenesisBytes {
+		t.Fatalf("expected %s but got %v", errBadGenesisBytes, err)
+	}
+}
+
+func TestMaxDataLen(t *testing.T) {
+	genesisBytes, err := Genesis(nil, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), genesisBytes, make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+	vm.Bootstrapped()
+	service := Service{vm}
+
+	long := formatting.CB58{Bytes: bytes.Repeat([]byte{1}, 256)}
+	if err := service.ProposeBlock(nil, &ProposeBlockArgs{Data: long.String()}, &ProposeBlockReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(vm.mempool) != 1 || !bytes.Equal(vm.mempool[0], long.Bytes) {
+		t.Fatalf("mempool should have the proposed data but has %x", vm.mempool)
+	}
+
+	tooLong := formatting.CB58{Bytes: make([]byte, 257)}
+	if err := service.ProposeBlock(nil, &ProposeBlockArgs{Data: tooLong.String()}, &ProposeBlockReply{}); !errors.Is(err, errDataTooLong) {
+		t.Fatalf("ProposeBlock should have failed with %s but returned %v", errDataTooLong, err)
+	}
+
+	// Blocks with data longer than the max fail verification
+	block, err := vm.NewBlock(vm.LastAccepted(), long.Bytes, time.Unix(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := block.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	block, err = vm.NewBlock(vm.LastAccepted(), tooLong.Bytes, time.Unix(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := block.Verify(); err != errBlockDataTooLong {
+		t.Fatalf("Verify should have failed with %s but returned %v", errBlockDataTooLong, err)
+	}
+}
+
+// compactionCounter counts the number of times the database is compacted
+type compactionCounter struct {
+	database.Database
+	compactions int
+}
+
+func (db *compactionCounter) Compact(start, limit []byte) error {
+	db.compactions++
+	return db.Database.Compact(start, limit)
+}
+
+func TestCompactAfterPruning(t *testing.T) {
+	db := &compactionCounter{Database: memdb.New()}
+	vm := &VM{CompactionThreshold: 2}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, db, []byte{0, 0, 0, 0, 0}, make(chan common.Message, 3), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+	genesisID := vm.LastAccepted()
+	vm.SetPreference(genesisID)
+
+	// Build three conflicting blocks on top of the genesis block
+	blocks := []snowman.Block(nil)
+	for i := byte(1); i <= 3; i++ {
+		vm.proposeBlock([]byte{i})
+		block, err := vm.BuildBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := block.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	blocks[0].Accept()
+	blocks[1].Reject()
+	if db.compactions != 0 {
+		t.Fatalf("database shouldn't have been compacted after pruning one block")
+	}
+	blocks[2].Reject()
+	if db.compactions != 1 {
+		t.Fatalf("database should have been compacted once but was compacted %d times", db.compactions)
+	}
+
+	// The accepted blocks should still be readable
+	for _, blkID := range []ids.ID{genesisID, blocks[0].ID()} {
+		if _, err := vm.GetBlock(blkID); err != nil {
+			t.Fatalf("couldn't get block %s after compaction: %s", blkID, err)
+		}
+	}
+	// The rejected blocks should have been pruned
+	for _, block := range blocks[1:] {
+		if _, err := vm.GetBlock(block.ID()); err == nil {
+			t.Fatalf("rejected block %s should have been pruned", block.ID())
+		}
+		if status := vm.State.GetStatus(vm.DB, block.ID()); status != choices.Rejected {
+			t.Fatalf("rejected block %s should have status %s but has %s", block.ID(), choices.Rejected, status)
+		}
+	}
+}
+
+func TestStrictTimestampsFeature(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		vm := &VM{}
+		ctx := snow.DefaultContextTest()
+		ctx.ChainID = blockchainID
+		ctx.Features = map[string]bool{strictTimestamps: strict}
+		if err := vm.Initialize(ctx, memdb.New(), []byte{0, 0, 0, 0, 0}, make(chan common.Message, 1), nil); err != nil {
+			t.Fatal(err)
+		}
+
+		// The genesis block has timestamp 0, so this block has the same
+		// timestamp as its parent
+		block, err := vm.NewBlock(vm.LastAccepted(), []byte{1}, time.Unix(0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := assertBlock(block, vm.LastAccepted(), []byte{1}, !strict); err != nil {
+			t.Fatalf("with %s=%v: %s", strictTimestamps, strict, err)
+		}
+	}
+}
+
+func TestProposeBlockSync(t *testing.T) {
 	vm := &VM{}
 	ctx := snow.DefaultContextTest()
 	ctx.ChainID = blockchainID
-	if err := vm.Initialize(ctx, db, []byte{0, 0, 0, 0, 0}, msgChan, nil); err != nil {
+	msgChan := make(chan common.Message, 1)
+	if err := vm.Initialize(ctx, memdb.New(), []byte{0, 0, 0, 0, 0}, msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.Bootstrapped()
+	vm.SetPreference(vm.LastAccepted())
+
+	data := []byte{1, 2, 3}
+	service := Service{vm}
+	replies := make(chan *ProposeBlockReply, 1)
+	errs := make(chan error, 1)
+	go func() {
+		// The API server holds the context lock while the API is called
+		ctx.Lock.Lock()
+		defer ctx.Lock.Unlock()
+
+		reply := &ProposeBlockReply{}
+		args := &ProposeBlockArgs{Data: formatting.CB58{Bytes: data[:]}.String(), Sync: true}
+		if err := service.ProposeBlock(nil, args, reply); err != nil {
+			errs <- err
+			return
+		}
+		replies <- reply
+	}()
+
+	// Wait for the proposal, then build and accept its block
+	<-msgChan
+	ctx.Lock.Lock()
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := blk.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	blk.Accept()
+	ctx.Lock.Unlock()
+
+	select {
+	case reply := <-replies:
+		if !reply.Success {
+			t.Fatal("synchronous proposal should have succeeded")
+		}
+		if reply.BlockID != blk.ID().String() {
+			t.Fatalf("synchronous proposal returned block %s but %s was accepted", reply.BlockID, blk.ID())
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(defaultProposeTimeout):
+		t.Fatal("synchronous proposal didn't return")
+	}
+	if len(vm.acceptWaiters) != 0 {
+		t.Fatalf("expected no waiters but there are %d", len(vm.acceptWaiters))
+	}
+}
+
+func TestProposeBlockSyncTimeout(t *testing.T) {
+	vm := &VM{ProposeTimeout: time.Millisecond}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte{0, 0, 0, 0, 0}, make(chan common.Message, 1), nil); err != nil {
 		t.Fatal(err)
 	}
+	vm.Bootstrapped()
 
+	data := []byte{1, 2, 3}
 	service := Service{vm}
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+	args := &ProposeBlockArgs{Data: formatting.CB58{Bytes: data[:]}.String(), Sync: true}
+	if err := service.ProposeBlock(nil, args, &ProposeBlockReply{}); err != errTimeout {
+		t.Fatalf("expected %s but got %v", errTimeout, err)
+	}
+	if len(vm.acceptWaiters) != 0 {
+		t.Fatalf("expected no waiters but there are %d", len(vm.acceptWaiters))
+	}
+}
+
+func TestProposeBlockSyncCanceled(t *testing.T) {
+	vm, _ := NewTestVM(t)
+
+	data := []byte{1, 2, 3}
+	service := Service{vm}
+	reqCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(reqCtx)
+	vm.Ctx.Lock.Lock()
+	defer vm.Ctx.Lock.Unlock()
+	args := &ProposeBlockArgs{Data: formatting.CB58{Bytes: data}.String(), Sync: true}
+	if err := service.ProposeBlock(r, args, &ProposeBlockReply{}); err != context.Canceled {
+		t.Fatalf("expected %s but got %v", context.Canceled, err)
+	}
+	if len(vm.acceptWaiters) != 0 {
+		t.Fatalf("expected no waiters but there are %d", len(vm.acceptWaiters))
+	}
+}
+
+func TestMaxReorgDepth(t *testing.T) {
+	vm := &VM{MaxReorgDepth: 1}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, memdb.New(), []byte("genesis"), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	blocks := acceptBlocks(t, vm, "a", "b", "c")
+	lastAccepted := vm.LastAccepted()
+
+	// A block whose parent is two blocks behind the last accepted block would
+	// replace two accepted blocks, so it's invalid
+	deep, err := vm.NewBlock(blocks[0].ID(), []byte{'d'}, time.Unix(4, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := deep.Verify(); err != errReorgTooDeep {
+		t.Fatalf("Verify should have failed with %s but returned %v", errReorgTooDeep, err)
+	}
+	if !vm.LastAccepted().Equals(lastAccepted) {
+		t.Fatalf("last accepted block should still be %s but is %s", lastAccepted, vm.LastAccepted())
+	}
+
+	// Replacing a single accepted block is allowed
+	shallow, err := vm.NewBlock(blocks[1].ID(), []byte{'e'}, time.Unix(4, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shallow.Verify(); err != nil {
+		t.Fatalf("block that reorgs 1 block deep should have been valid but got %s", err)
+	}
+
+	// Blocks building on processing blocks are measured from their closest
+	// accepted ancestor
+	child, err := vm.NewBlock(lastAccepted, []byte{'f'}, time.Unix(4, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	grandchild, err := vm.NewBlock(child.ID(), []byte{'g'}, time.Unix(5, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := grandchild.Verify(); err != nil {
+		t.Fatalf("block building on a processing block should have been valid but got %s", err)
+	}
+	// A processing block whose closest accepted ancestor is too deep is
+	// invalid, even if it was verified before the limit was set
+	vm.MaxReorgDepth = 0
+	if err := deep.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	vm.MaxReorgDepth = 1
+	deepChild, err := vm.NewBlock(deep.ID(), []byte{'h'}, time.Unix(5, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := deepChild.Verify(); err != errReorgTooDeep {
+		t.Fatalf("Verify should have failed with %s but returned %v", errReorgTooDeep, err)
+	}
+
+	// If the depth is unlimited, any reorg is allowed
+	vm.MaxReorgDepth = 0
+	if err := deep.Verify(); err != nil {
+		t.Fatalf("block should have been valid with an unlimited reorg depth but got %s", err)
+	}
+}
+
+type testPeers struct{ numPeers int }
+
+func (p *testPeers) Len() int { return p.numPeers }
+
+func TestMinPeersForWrites(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	vm.MinPeersForWrites = 2
+	peers := &testPeers{}
+	vm.Ctx.Peers = peers
+
+	service := Service{vm}
+	data := formatting.CB58{Bytes: make([]byte, defaultMaxDataLen)}
+	propose := func() error {
+		return service.ProposeBlock(nil, &ProposeBlockArgs{Data: data.String()}, &ProposeBlockReply{})
+	}
+
+	peers.numPeers = 1
+	if err := propose(); err != errTooFewPeers {
+		t.Fatalf("Proposal should have failed with %s but returned %v", errTooFewPeers, err)
+	}
+	// Reads are still served
 	if err := service.GetBlock(nil, &GetBlockArgs{}, &GetBlockReply{}); err != nil {
 		t.Fatal(err)
 	}
+
+	peers.numPeers = 2
+	if err := propose(); err != nil {
+		t.Fatal(err)
+	}
+
+	peers.numPeers = 0
+	if err := propose(); err != errTooFewPeers {
+		t.Fatalf("Proposal should have failed with %s but returned %v", errTooFewPeers, err)
+	}
+}
+
+func TestDBEncryption(t *testing.T) {
+	db := memdb.New()
+	key := []byte("correct horse battery staple")
+	initialize := func(key []byte) (*VM, error) {
+		ctx := snow.DefaultContextTest()
+		ctx.ChainID = blockchainID
+		vm := &VM{DBEncryptionKey: key}
+		return vm, vm.Initialize(ctx, db, testGenesisData, make(chan common.Message, 1), nil)
+	}
+
+	vm, err := initialize(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blk := acceptBlocks(t, vm, "top secret")[0]
+	vm.Shutdown()
+
+	// The block's data is only stored as ciphertext
+	it := db.NewIterator()
+	for it.Next() {
+		if bytes.Contains(it.Value(), []byte("top secret")) {
+			t.Fatalf("value of key %x is stored in plaintext", it.Key())
+		}
+	}
+	it.Release()
+
+	// Reopening the database with the same key reads the blocks back
+	vm, err = initialize(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+	if !vm.LastAccepted().Equals(blk.ID()) {
+		t.Fatalf("last accepted block should have been %s but was %s", blk.ID(), vm.LastAccepted())
+	}
+	got, err := vm.getBlock(blk.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, []byte("top secret")) {
+		t.Fatalf("block has data %q", got.Data)
+	}
+
+	// The database can't be read with a different key
+	if _, err := initialize([]byte("wrong key")); err != errWrongDBEncryptionKey {
+		t.Fatalf("initializing with the wrong key should have failed with %s but returned %v", errWrongDBEncryptionKey, err)
+	}
+}
+
+func TestBuildBlockClockBackwards(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		vm := &VM{}
+		ctx := snow.DefaultContextTest()
+		ctx.ChainID = blockchainID
+		ctx.Features = map[string]bool{strictTimestamps: strict}
+		if err := vm.Initialize(ctx, memdb.New(), testGenesisData, make(chan common.Message, 2), nil); err != nil {
+			t.Fatal(err)
+		}
+		vm.Bootstrapped()
+		vm.SetPreference(vm.LastAccepted())
+
+		build := func(data byte) *Block {
+			if err := vm.proposeBlock([]byte{data}); err != nil {
+				t.Fatal(err)
+			}
+			blk, err := vm.BuildBlock()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := blk.Verify(); err != nil {
+				t.Fatalf("with %s=%v: %s", strictTimestamps, strict, err)
+			}
+			blk.Accept()
+			vm.SetPreference(blk.ID())
+			return blk.(*Block)
+		}
+
+		now := time.Now()
+		vm.clock.Set(now)
+		parent := build(1)
+		if parent.Timestamp != now.Unix() {
+			t.Fatalf("block should have had timestamp %d but had %d", now.Unix(), parent.Timestamp)
+		}
+
+		// The clock is adjusted so far backwards that a block with the minimum
+		// timestamp would fail Verify, so no block is built until it catches up
+		vm.clock.Set(now.Add(-time.Hour))
+		if err := vm.proposeBlock([]byte{2}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := vm.BuildBlock(); err != errClockBehind {
+			t.Fatalf("with %s=%v: BuildBlock should have failed with %s but returned %v", strictTimestamps, strict, errClockBehind, err)
+		}
+		if len(vm.mempool) != 1 {
+			t.Fatalf("the data should have stayed in the mempool but it has %d pieces of data", len(vm.mempool))
+		}
+		vm.mempool = nil
+
+		// Within the max future drift, the block's timestamp is clamped
+		vm.clock.Set(now.Add(-5 * time.Second))
+		child := build(2)
+		expected := parent.Timestamp
+		if strict {
+			expected++
+		}
+		if child.Timestamp != expected {
+			t.Fatalf("with %s=%v: block should have had timestamp %d but had %d", strictTimestamps, strict, expected, child.Timestamp)
+		}
+
+		// A block from a proposer that doesn't clamp its timestamp is rejected
+		early, err := vm.NewBlock(child.ID(), []byte{3}, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := early.Verify(); err != errTimestampTooEarly {
+			t.Fatalf("Verify should have failed with %s but returned %v", errTimestampTooEarly, err)
+		}
+		vm.Shutdown()
+	}
+}
+
+func TestMaxFutureDrift(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	if vm.MaxFutureDrift != defaultMaxFutureDrift {
+		t.Fatalf("MaxFutureDrift should have defaulted to %s but was %s", defaultMaxFutureDrift, vm.MaxFutureDrift)
+	}
+	now := time.Now()
+	vm.clock.Set(now)
+
+	late, err := vm.NewBlock(vm.LastAccepted(), []byte{1}, now.Add(60*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := late.Verify(); err != errTimestampTooLate {
+		t.Fatalf("Verify should have failed with %s but returned %v", errTimestampTooLate, err)
+	}
+
+	early, err := vm.NewBlock(vm.LastAccepted(), []byte{2}, now.Add(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := early.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A larger drift allows the later block
+	vm.MaxFutureDrift = time.Minute
+	if err := late.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxMempoolBytes(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	vm.MaxMempoolBytes = 2
+
+	oldest := []byte{1}
+	if err := vm.proposeBlock(oldest); err != nil {
+		t.Fatal(err)
+	}
+	evicted := vm.awaitAcceptance(oldest)
+	for _, data := range [][]byte{{2}, {3}} {
+		if err := vm.proposeBlock(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only 2 pieces of data fit, so the oldest is evicted
+	if len(vm.mempool) != 2 || !bytes.Equal(vm.mempool[0], []byte{2}) || !bytes.Equal(vm.mempool[1], []byte{3}) {
+		t.Fatalf("wrong mempool after eviction: %v", vm.mempool)
+	}
+	if size := vm.mempoolBytes(); size > vm.MaxMempoolBytes {
+		t.Fatalf("mempool has %d bytes, more than the max of %d", size, vm.MaxMempoolBytes)
+	}
+
+	// The synchronous proposal waiting for the evicted data is told to retry
+	select {
+	case blkID := <-evicted:
+		if err := acceptedReply(blkID, &ProposeBlockReply{}); err != errEvicted {
+			t.Fatalf("proposal should have failed with %s but returned %v", errEvicted, err)
+		}
+	default:
+		t.Fatal("the proposal waiting for the evicted data should have been notified")
+	}
+	if _, ok := vm.acceptWaiters[string(oldest)]; ok {
+		t.Fatal("the proposal waiting for the evicted data should have stopped waiting")
+	}
+
+	vm.MaxMempoolBytes = 1
+	if err := vm.proposeBlock([]byte{4, 4}); err != errMempoolTooSmall {
+		t.Fatalf("proposal should have failed with %s but returned %v", errMempoolTooSmall, err)
+	}
+}
+
+func TestMaxMempoolSize(t *testing.T) {
+	vm, toEngine := NewTestVM(t)
+	if vm.MaxMempoolSize != defaultMaxMempoolSize {
+		t.Fatalf("MaxMempoolSize should have defaulted to %d but was %d", defaultMaxMempoolSize, vm.MaxMempoolSize)
+	}
+	vm.MaxMempoolSize = 3
+
+	for i := 0; i < vm.MaxMempoolSize; i++ {
+		if err := vm.proposeBlock([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-toEngine
+
+	if err := vm.proposeBlock([]byte{byte(vm.MaxMempoolSize)}); err != errMempoolFull {
+		t.Fatalf("proposal should have failed with %s but returned %v", errMempoolFull, err)
+	}
+	if len(vm.mempool) != vm.MaxMempoolSize {
+		t.Fatalf("mempool has %d pieces of data, expected %d", len(vm.mempool), vm.MaxMempoolSize)
+	}
+	select {
+	case <-toEngine:
+		t.Fatal("the engine shouldn't have been notified of a rejected proposal")
+	default:
+	}
+
+	// The error is returned to API callers
+	service := Service{vm}
+	data := formatting.CB58{Bytes: make([]byte, defaultMaxDataLen)}
+	if err := service.ProposeBlock(nil, &ProposeBlockArgs{Data: data.String()}, &ProposeBlockReply{}); err != errMempoolFull {
+		t.Fatalf("ProposeBlock should have failed with %s but returned %v", errMempoolFull, err)
+	}
+
+	// Building a block makes room
+	if _, err := vm.BuildBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.proposeBlock([]byte{byte(vm.MaxMempoolSize)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProposeBlocks(t *testing.T) {
+	vm, toEngine := NewTestVM(t)
+	service := Service{vm}
+
+	cb58 := formatting.CB58{Bytes: []byte("short")}
+	full := formatting.CB58{Bytes: bytes.Repeat([]byte{1}, defaultMaxDataLen)}
+	oversized := formatting.CB58{Bytes: make([]byte, defaultMaxDataLen+1)}
+
+	// One oversized blob fails the whole batch
+	reply := ProposeBlocksReply{}
+	err := service.ProposeBlocks(nil, &ProposeBlocksArgs{
+		Data: []string{cb58.String(), oversized.String(), full.String()},
+	}, &reply)
+	if !errors.Is(err, errDataTooLong) {
+		t.Fatalf("ProposeBlocks should have failed with %s but returned %v", errDataTooLong, err)
+	}
+	if reply.Accepted != 0 || len(vm.mempool) != 0 {
+		t.Fatalf("ProposeBlocks shouldn't have proposed a partial batch, but proposed %d pieces of data", len(vm.mempool))
+	}
+	select {
+	case <-toEngine:
+		t.Fatal("the engine shouldn't have been notified of a rejected batch")
+	default:
+	}
+
+	if err := service.ProposeBlocks(nil, &ProposeBlocksArgs{
+		Data: []string{cb58.String(), full.String()},
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ProposeBlocks(nil, &ProposeBlocksArgs{
+		Data:     []string{"0a0b"},
+		Encoding: "hex",
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Accepted != 1 {
+		t.Fatalf("ProposeBlocks accepted %d pieces of data, expected 1", reply.Accepted)
+	}
+
+	expected := [][]byte{[]byte("short"), full.Bytes, {0x0a, 0x0b}}
+	if len(vm.mempool) != len(expected) {
+		t.Fatalf("mempool has %d pieces of data, expected %d", len(vm.mempool), len(expected))
+	}
+	for i, data := range vm.mempool {
+		if !bytes.Equal(data, expected[i]) {
+			t.Fatalf("mempool has %x at %d, expected %x", data, i, expected[i])
+		}
+	}
+
+	// A batch that doesn't fit in the mempool isn't partially proposed
+	vm.MaxMempoolSize = 4
+	err = service.ProposeBlocks(nil, &ProposeBlocksArgs{Data: []string{cb58.String(), cb58.String()}}, &ProposeBlocksReply{})
+	if err != errMempoolFull || len(vm.mempool) != len(expected) {
+		t.Fatalf("ProposeBlocks should have failed with %s but returned %v", errMempoolFull, err)
+	}
+
+	if err := service.ProposeBlocks(nil, &ProposeBlocksArgs{Data: []string{"0a"}, Encoding: "base64"}, &reply); err != errBadBatchEncoding {
+		t.Fatalf("ProposeBlocks should have failed with %s but returned %v", errBadBatchEncoding, err)
+	}
+}
+
+func TestRejectCleanup(t *testing.T) {
+	vm, _ := NewTestVM(t)
+	vm.RequeueRejected = true
+
+	// Build conflicting blocks on top of the genesis block. The clock moves so
+	// that blocks with the same data have different IDs.
+	build := func(data []byte) *Block {
+		vm.clock.Set(vm.clock.Time().Add(time.Second))
+		if err := vm.proposeBlock(data); err != nil {
+			t.Fatal(err)
+		}
+		blk, err := vm.BuildBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := blk.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		return blk.(*Block)
+	}
+	vm.clock.Set(time.Unix(1000, 0))
+	accepted := build([]byte{1})
+	duplicate := build([]byte{1})
+	conflicting := build([]byte{2})
+	if duplicate.ID().Equals(accepted.ID()) {
+		t.Fatal("blocks with the same data should have had different IDs")
+	}
+
+	accepted.Accept()
+	duplicate.Reject()
+	conflicting.Reject()
+
+	// The rejected blocks are pruned
+	for _, blk := range []*Block{duplicate, conflicting} {
+		if _, err := vm.GetBlock(blk.ID()); err == nil {
+			t.Fatalf("rejected block %s should have been pruned", blk.ID())
+		}
+		if status := vm.State.GetStatus(vm.DB, blk.ID()); status != choices.Rejected {
+			t.Fatalf("rejected block %s should have status %s but has %s", blk.ID(), choices.Rejected, status)
+		}
+	}
+	// Only the data that wasn't accepted in another block is retried
+	if len(vm.mempool) != 1 || !bytes.Equal(vm.mempool[0], conflicting.Data) {
+		t.Fatalf("the data of the conflicting block should have been retried but the mempool is %v", vm.mempool)
+	}
+
+	// Without retries, the data of a rejected block is dropped and the
+	// proposal waiting for it fails
+	vm.RequeueRejected = false
+	vm.SetPreference(accepted.ID())
+	retried, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := retried.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	dropped := vm.awaitAcceptance(conflicting.Data)
+	retried.Reject()
+	if len(vm.mempool) != 0 {
+		t.Fatalf("the data of the rejected block should have been dropped but the mempool is %v", vm.mempool)
+	}
+	select {
+	case blkID := <-dropped:
+		if err := acceptedReply(blkID, &ProposeBlockReply{}); err != errEvicted {
+			t.Fatalf("proposal should have failed with %s but returned %v", errEvicted, err)
+		}
+	default:
+		t.Fatal("the proposal waiting for the dropped data should have been notified")
+	}
+}
+
+func TestProposeBlockSyncReadYourWrites(t *testing.T) {
+	db := memdb.New()
+	vm := &VM{EnableSearch: true}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	msgChan := make(chan common.Message, 1)
+	if err := vm.Initialize(ctx, db, testGenesisData, msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+	vm.Bootstrapped()
+	vm.SetPreference(vm.LastAccepted())
+
+	// Build and accept the proposed block as the engine would
+	go func() {
+		<-msgChan
+		ctx.Lock.Lock()
+		defer ctx.Lock.Unlock()
+
+		blk, err := vm.BuildBlock()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err := blk.Verify(); err != nil {
+			t.Error(err)
+			return
+		}
+		blk.Accept()
+	}()
+
+	// The API server holds the context lock while the API is called
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	data := []byte{'r', 'y', 'w'}
+	service := Service{vm}
+	proposeReply := &ProposeBlockReply{}
+	proposeArgs := &ProposeBlockArgs{Data: formatting.CB58{Bytes: data[:]}.String(), Sync: true}
+	if err := service.ProposeBlock(nil, proposeArgs, proposeReply); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every read right after the proposal returns sees the block
+	lastReply := &GetBlockReply{}
+	if err := service.GetBlock(nil, &GetBlockArgs{}, lastReply); err != nil {
+		t.Fatal(err)
+	}
+	if lastReply.ID != proposeReply.BlockID {
+		t.Fatalf("last accepted block should have been %s but was %s", proposeReply.BlockID, lastReply.ID)
+	}
+	blkReply := &GetBlockReply{}
+	if err := service.GetBlock(nil, &GetBlockArgs{ID: proposeReply.BlockID}, blkReply); err != nil {
+		t.Fatal(err)
+	}
+	if blkID, _ := ids.FromString(proposeReply.BlockID); vm.State.GetStatus(vm.DB, blkID) != choices.Accepted {
+		t.Fatalf("block %s should have been accepted", blkID)
+	}
+	heightReply := &GetBlockByHeightReply{}
+	if err := service.GetBlockByHeight(nil, &GetBlockByHeightArgs{Height: 1}, heightReply); err != nil {
+		t.Fatal(err)
+	}
+	if heightReply.ID != proposeReply.BlockID {
+		t.Fatalf("block at height 1 should have been %s but was %s", proposeReply.BlockID, heightReply.ID)
+	}
+	searchReply := &SearchBlocksReply{}
+	if err := service.SearchBlocks(nil, &SearchBlocksArgs{Query: "ryw"}, searchReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(searchReply.Blocks) != 1 || searchReply.Blocks[0].ID != proposeReply.BlockID {
+		t.Fatalf("search should have found block %s but found %v", proposeReply.BlockID, searchReply.Blocks)
+	}
+
+	// The acceptance has been committed to the database. The second VM isn't
+	// shut down, since that would close the database the first VM uses.
+	ctx2 := snow.DefaultContextTest()
+	ctx2.ChainID = blockchainID
+	vm2 := &VM{}
+	if err := vm2.Initialize(ctx2, db, testGenesisData, make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if lastAccepted := vm2.LastAccepted().String(); lastAccepted != proposeReply.BlockID {
+		t.Fatalf("committed last accepted block should have been %s but was %s", proposeReply.BlockID, lastAccepted)
+	}
 }